@@ -0,0 +1,31 @@
+package main
+
+import (
+	"errors"
+	"log"
+
+	"github.com/famusovsky/WikiSurfBack/internal/achievements"
+	"github.com/famusovsky/WikiSurfBack/internal/postgres"
+)
+
+// backfillAchievements - выдача всем пользователям достижений, заработанных до их появления.
+func backfillAchievements(db postgres.DbHandler, args []string, infoLog *log.Logger) error {
+	ids, err := db.GetUsersIds()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	awarded := 0
+	for _, id := range ids {
+		added, err := achievements.Award(db, id)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		awarded += len(added)
+	}
+
+	infoLog.Printf("checked %d users, awarded %d achievements\n", len(ids), awarded)
+	return errors.Join(errs...)
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/famusovsky/WikiSurfBack/internal/analytics"
+	"github.com/famusovsky/WikiSurfBack/internal/postgres"
+)
+
+// insightsTop - количество статей каждого вида, сохраняемое в статистике маршрута.
+const insightsTop = 10
+
+// computeInsights - пересчёт статистики путей по всем спринтам без ожидания планировщика.
+func computeInsights(db postgres.DbHandler, args []string, infoLog *log.Logger) error {
+	sprints, err := db.GetSprintPaths()
+	if err != nil {
+		return err
+	}
+
+	insights := analytics.Compute(sprints, insightsTop, time.Now())
+	if err := db.ReplacePathInsights(insights); err != nil {
+		return err
+	}
+
+	infoLog.Printf("computed %d path insights from %d sprints\n", len(insights), len(sprints))
+	return nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/famusovsky/WikiSurfBack/internal/postgres"
+	"github.com/famusovsky/WikiSurfBack/pkg/database"
+	_ "github.com/lib/pq"
+)
+
+// command - структура, описывающая команду утилиты.
+type command struct {
+	usage string                                                                // usage - описание аргументов и действия команды.
+	run   func(db postgres.DbHandler, args []string, infoLog *log.Logger) error // run - выполнение команды.
+}
+
+// commands - команды утилиты по названиям.
+var commands = map[string]command{
+	"add-season": {
+		usage: "<name> <start YYYY-MM-DD> <end YYYY-MM-DD> - create a leaderboard season, both days inclusive",
+		run:   addSeason,
+	},
+	"backfill-achievements": {
+		usage: "award the achievements earned over the whole sprint and tournament history",
+		run:   backfillAchievements,
+	},
+	"compute-insights": {
+		usage: "recalculate the path insights of all routes",
+		run:   computeInsights,
+	},
+	"export-tour": {
+		usage: "<tour id> [json|yaml|csv] - print the tournament setup to stdout",
+		run:   exportTour,
+	},
+	"list-reports": {
+		usage: "print the open reports on routes and comments",
+		run:   listReports,
+	},
+	"import-tour": {
+		usage: "<creator email> <file.json|file.yaml|file.csv> - create a tournament with its routes and co-creators",
+		run:   importTour,
+	},
+	"resolve-report": {
+		usage: "<report id> dismiss|resolve|hide [note] - close a report, hide also removes the reported comment",
+		run:   resolveReport,
+	},
+	"recompute-skill": {
+		usage: "rebuild the skill ratings from the full sprint history",
+		run:   recomputeSkill,
+	},
+}
+
+func main() {
+	dsn := flag.String("dsn", "", "dsn for the db")
+	flag.Usage = usage
+	flag.Parse()
+
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+
+	infoLog := log.New(os.Stdout, "INFO\t", log.Ldate|log.Ltime)
+	errorLog := log.New(os.Stderr, "ERR\t", log.Ldate|log.Ltime)
+
+	var db *sql.DB
+	var err error
+	if *dsn == "" {
+		db, err = database.OpenViaEnvVars("postgres")
+	} else {
+		db, err = database.OpenViaDsn(*dsn, "postgres")
+	}
+	if err != nil {
+		errorLog.Fatal("error while connecting to the db ", err)
+	}
+	defer db.Close()
+
+	dbHandler, err := postgres.Get(db, false)
+	if err != nil {
+		errorLog.Fatal(err)
+	}
+
+	if err := cmd.run(dbHandler, flag.Args()[1:], infoLog); err != nil {
+		errorLog.Fatal(err)
+	}
+}
+
+// usage - функция, выводящая справку по утилите.
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintln(out, "Usage: cli [-dsn=...] <command> [args]")
+	fmt.Fprintln(out, "\nCommands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(out, "  %s - %s\n", name, commands[name].usage)
+	}
+
+	fmt.Fprintln(out, "\nFlags:")
+	flag.PrintDefaults()
+}
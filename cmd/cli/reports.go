@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/famusovsky/WikiSurfBack/internal/postgres"
+)
+
+// listReports - вывод нерассмотренных жалоб на маршруты и комментарии.
+func listReports(db postgres.DbHandler, args []string, infoLog *log.Logger) error {
+	reports, err := db.GetOpenReports()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tROUTE\tCOMMENT\tUSER\tCREATED\tREASON")
+	for _, r := range reports {
+		target := "-"
+		if r.CommentId != nil {
+			comment, err := db.GetRouteComment(*r.CommentId)
+			if err != nil {
+				return err
+			}
+			target = fmt.Sprintf("#%d %q", comment.Id, comment.Body)
+		}
+		fmt.Fprintf(w, "%d\t%d\t%s\t%d\t%s\t%s\n", r.Id, r.RouteId, target, r.UserId, r.CreatedAt.Format("2006-01-02 15:04"), r.Reason)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	infoLog.Printf("%d open reports\n", len(reports))
+	return nil
+}
+
+// resolveReport - рассмотрение жалобы: отклонение, отметка о принятых мерах или скрытие комментария.
+func resolveReport(db postgres.DbHandler, args []string, infoLog *log.Logger) error {
+	if len(args) < 2 {
+		return errors.New("resolve-report expects <report id> dismiss|resolve|hide [note]")
+	}
+
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return errors.Join(errors.New("invalid report id"), err)
+	}
+
+	action := args[1]
+	if action != "dismiss" && action != "resolve" && action != "hide" {
+		return fmt.Errorf("unknown action %q, use dismiss, resolve or hide", action)
+	}
+	resolution := action
+	if note := strings.Join(args[2:], " "); note != "" {
+		resolution += ": " + note
+	}
+
+	if err := db.ResolveRouteReport(id, resolution, action == "hide"); err != nil {
+		return err
+	}
+
+	infoLog.Printf("report #%d closed: %s\n", id, resolution)
+	return nil
+}
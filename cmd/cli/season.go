@@ -0,0 +1,38 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/famusovsky/WikiSurfBack/internal/models"
+	"github.com/famusovsky/WikiSurfBack/internal/postgres"
+)
+
+// addSeason - создание сезона рейтинга, конец сезона включается в него целиком.
+func addSeason(db postgres.DbHandler, args []string, infoLog *log.Logger) error {
+	if len(args) != 3 {
+		return errors.New("add-season expects <name> <start YYYY-MM-DD> <end YYYY-MM-DD>")
+	}
+
+	start, err := time.ParseInLocation(time.DateOnly, args[1], time.Local)
+	if err != nil {
+		return errors.Join(errors.New("invalid season start"), err)
+	}
+	end, err := time.ParseInLocation(time.DateOnly, args[2], time.Local)
+	if err != nil {
+		return errors.Join(errors.New("invalid season end"), err)
+	}
+	end = end.AddDate(0, 0, 1)
+	if !end.After(start) {
+		return errors.New("the season must end after it starts")
+	}
+
+	id, err := db.AddSeason(models.Season{Name: args[0], StartTime: start, EndTime: end})
+	if err != nil {
+		return err
+	}
+
+	infoLog.Printf("season #%d %q created: %s - %s\n", id, args[0], args[1], args[2])
+	return nil
+}
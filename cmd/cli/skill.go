@@ -0,0 +1,30 @@
+package main
+
+import (
+	"log"
+
+	"github.com/famusovsky/WikiSurfBack/internal/models"
+	"github.com/famusovsky/WikiSurfBack/internal/postgres"
+	"github.com/famusovsky/WikiSurfBack/internal/skill"
+)
+
+// recomputeSkill - пересчёт рейтинга мастерства всех игроков по полной истории спринтов.
+func recomputeSkill(db postgres.DbHandler, args []string, infoLog *log.Logger) error {
+	sprints, err := db.GetSuccessfulSprints()
+	if err != nil {
+		return err
+	}
+
+	ratings := skill.Replay(sprints)
+	skills := make([]models.PlayerSkill, 0, len(ratings))
+	for _, r := range ratings {
+		skills = append(skills, r)
+	}
+
+	if err := db.ReplacePlayerSkills(skills); err != nil {
+		return err
+	}
+
+	infoLog.Printf("replayed %d sprints, rated %d players\n", len(sprints), len(skills))
+	return nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/famusovsky/WikiSurfBack/internal/postgres"
+	"github.com/famusovsky/WikiSurfBack/internal/tourspec"
+)
+
+// importTour - создание соревнования из файла настройки от имени пользователя с указанной почтой.
+func importTour(db postgres.DbHandler, args []string, infoLog *log.Logger) error {
+	if len(args) != 2 {
+		return errors.New("import-tour expects <creator email> <file.json|file.yaml|file.csv>")
+	}
+
+	user, err := db.GetUser(args[0])
+	if err != nil {
+		return err
+	}
+	format, err := tourspec.FormatOf(args[1], "")
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(args[1])
+	if err != nil {
+		return err
+	}
+	spec, err := tourspec.Parse(data, format)
+	if err != nil {
+		return err
+	}
+
+	id, err := tourspec.Import(db, spec, user.Id)
+	if err != nil {
+		return err
+	}
+
+	infoLog.Printf("tournament #%d created with %d routes\n", id, len(spec.Routes))
+	return nil
+}
+
+// exportTour - вывод настройки соревнования в stdout, по которой его можно воссоздать командой import-tour.
+func exportTour(db postgres.DbHandler, args []string, infoLog *log.Logger) error {
+	if len(args) < 1 || len(args) > 2 {
+		return errors.New("export-tour expects <tour id> [json|yaml|csv]")
+	}
+
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return err
+	}
+	format := "json"
+	if len(args) == 2 {
+		format = args[1]
+	}
+
+	spec, err := tourspec.Export(db, id)
+	if err != nil {
+		return err
+	}
+
+	return tourspec.Encode(os.Stdout, spec, format)
+}
@@ -7,9 +7,12 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
 	"github.com/famusovsky/WikiSurfBack/internal/app"
+	"github.com/famusovsky/WikiSurfBack/internal/mailer"
+	"github.com/famusovsky/WikiSurfBack/internal/oidc"
 	"github.com/famusovsky/WikiSurfBack/internal/postgres"
 	"github.com/famusovsky/WikiSurfBack/pkg/database"
 	_ "github.com/lib/pq"
@@ -22,6 +25,8 @@ func main() {
 	addr := flag.String("addr", ":8080", "HTTP address")
 	overrideTables := flag.Bool("override_tables", false, "Override tables in database")
 	dsn := flag.String("dsn", "", "dsn for the db")
+	extOrigins := flag.String("extension_origins", os.Getenv("EXTENSION_ORIGINS"), "comma separated origins of the trusted browser extensions, e.g. chrome-extension://<id>")
+	baseUrl := flag.String("base_url", os.Getenv("BASE_URL"), "public URL of the server used in emails and links, e.g. https://wikisurf.example.com")
 	flag.Parse()
 
 	infoLog := log.New(os.Stdout, "INFO\t", log.Ldate|log.Ltime)
@@ -44,7 +49,23 @@ func main() {
 		errorLog.Fatal(err)
 	}
 
-	app := app.CreateApp(DbHandler, infoLog, errorLog)
+	if *baseUrl == "" {
+		errorLog.Fatal("the public server URL is not set: use -base_url or BASE_URL")
+	}
+
+	mail, err := mailer.OpenViaEnvVars(infoLog)
+	if err != nil {
+		errorLog.Fatal(err)
+	}
+
+	cfg := app.Config{BaseUrl: *baseUrl}
+	for _, origin := range strings.Split(*extOrigins, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			cfg.ExtensionOrigins = append(cfg.ExtensionOrigins, origin)
+		}
+	}
+
+	app := app.CreateApp(DbHandler, mail, oidc.ProvidersFromEnv(), cfg, infoLog, errorLog)
 
 	sigQuit := make(chan os.Signal, 2)
 	signal.Notify(sigQuit, syscall.SIGINT, syscall.SIGTERM)
@@ -0,0 +1,118 @@
+// Package achievements - пакет, описывающий достижения игроков и правила их выдачи.
+package achievements
+
+import (
+	"time"
+
+	"github.com/famusovsky/WikiSurfBack/internal/models"
+)
+
+// Achievement - структура, описывающая достижение.
+type Achievement struct {
+	Id          string                               // Id - постоянный id достижения, хранящийся в БД.
+	Title       string                               // Title - название достижения.
+	Description string                               // Description - условие получения достижения.
+	Icon        string                               // Icon - значок достижения.
+	check       func(f models.AchievementFacts) bool // check - проверка условия получения.
+}
+
+// All - все достижения в порядке показа.
+var All = []Achievement{
+	{
+		Id: "first-sprint", Title: "First steps", Icon: "👣",
+		Description: "Finish a sprint",
+		check:       func(f models.AchievementFacts) bool { return f.Successful >= 1 },
+	},
+	{
+		Id: "first-win", Title: "Record holder", Icon: "🥇",
+		Description: "Hold the record on a route",
+		check:       func(f models.AchievementFacts) bool { return f.Records >= 1 },
+	},
+	{
+		Id: "records-10", Title: "Dominator", Icon: "👑",
+		Description: "Hold the records on 10 routes",
+		check:       func(f models.AchievementFacts) bool { return f.Records >= 10 },
+	},
+	{
+		Id: "sub-30", Title: "Speedrunner", Icon: "⚡",
+		Description: "Finish a sprint in less than 30 seconds",
+		check:       func(f models.AchievementFacts) bool { return f.Fastest > 0 && f.Fastest < 30_000 },
+	},
+	{
+		Id: "routes-10", Title: "Explorer", Icon: "🧭",
+		Description: "Finish 10 different routes",
+		check:       func(f models.AchievementFacts) bool { return f.Routes >= 10 },
+	},
+	{
+		Id: "routes-100", Title: "Cartographer", Icon: "🗺️",
+		Description: "Finish 100 different routes",
+		check:       func(f models.AchievementFacts) bool { return f.Routes >= 100 },
+	},
+	{
+		Id: "optimal", Title: "Shortest path", Icon: "🎯",
+		Description: "Finish a route, played by at least 3 players, in the fewest clicks anyone needed",
+		check:       func(f models.AchievementFacts) bool { return f.Optimal >= 1 },
+	},
+	{
+		Id: "creator-10", Title: "Route maker", Icon: "🛠️",
+		Description: "Create 10 routes",
+		check:       func(f models.AchievementFacts) bool { return f.RoutesCreated >= 10 },
+	},
+	{
+		Id: "tour-first", Title: "Competitor", Icon: "🎟️",
+		Description: "Take part in a finished tournament",
+		check:       func(f models.AchievementFacts) bool { return f.Tournaments >= 1 },
+	},
+	{
+		Id: "tour-win", Title: "Champion", Icon: "🏆",
+		Description: "Win a tournament",
+		check:       func(f models.AchievementFacts) bool { return f.TourWins >= 1 },
+	},
+}
+
+// Get - функция, возвращающая достижение по id.
+func Get(id string) (Achievement, bool) {
+	for _, a := range All {
+		if a.Id == id {
+			return a, true
+		}
+	}
+
+	return Achievement{}, false
+}
+
+// Earned - функция, возвращающая id достижений, условия которых выполнены.
+func Earned(f models.AchievementFacts) []string {
+	var res []string
+	for _, a := range All {
+		if a.check(f) {
+			res = append(res, a.Id)
+		}
+	}
+
+	return res
+}
+
+// Store - интерфейс хранилища, необходимого для выдачи достижений.
+type Store interface {
+	GetAchievementFacts(userId int) (models.AchievementFacts, error)              // GetAchievementFacts - получение данных пользователя для достижений.
+	AddUserAchievements(userId int, ids []string, at time.Time) ([]string, error) // AddUserAchievements - выдача достижений, возвращает новые.
+}
+
+// Award - функция, выдающая пользователю все заработанные достижения.
+// Выдача идемпотентна, поэтому функция подходит и для обработки событий, и для пересчёта по истории.
+//
+// Возвращает: id впервые выданных достижений.
+func Award(store Store, userId int) ([]string, error) {
+	facts, err := store.GetAchievementFacts(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	earned := Earned(facts)
+	if len(earned) == 0 {
+		return nil, nil
+	}
+
+	return store.AddUserAchievements(userId, earned, time.Now())
+}
@@ -0,0 +1,148 @@
+// Package analytics - пакет, считающий статистику по путям спринтов:
+// статьи-хабы, тупики и самые успешные первые переходы на маршрутах и по всем маршрутам.
+package analytics
+
+import (
+	"sort"
+	"time"
+
+	"github.com/famusovsky/WikiSurfBack/internal/models"
+)
+
+// Global - id маршрута, под которым хранится статистика по всем маршрутам.
+const Global = 0
+
+// key - ключ счётчика статистики.
+type key struct {
+	routeId int
+	kind    string
+	article string
+}
+
+// counter - счётчик спринтов, в которых встретилась статья.
+type counter struct {
+	hits, successes int
+}
+
+// Compute - функция, считающая статистику путей.
+//
+// Принимает: пути спринтов, количество статей каждого вида, сохраняемое для маршрута, время расчёта.
+//
+// Возвращает: статистику по маршрутам и по всем маршрутам (RouteId = Global).
+func Compute(sprints []models.PathSprint, top int, at time.Time) []models.PathInsight {
+	counters := map[key]*counter{}
+	count := func(k key, success bool) {
+		c, ok := counters[k]
+		if !ok {
+			c = &counter{}
+			counters[k] = c
+		}
+		c.hits++
+		if success {
+			c.successes++
+		}
+	}
+
+	for _, s := range sprints {
+		steps := Intermediate(s)
+		seen := map[key]bool{}
+		once := func(k key) {
+			if !seen[k] {
+				seen[k] = true
+				count(k, s.Success)
+			}
+		}
+
+		if len(steps) != 0 {
+			once(key{s.RouteId, models.InsightFirstClick, steps[0]})
+		}
+		for _, article := range steps {
+			once(key{s.RouteId, models.InsightHub, article})
+			once(key{Global, models.InsightHub, article})
+		}
+		for _, article := range DeadEnds(s) {
+			once(key{s.RouteId, models.InsightDeadEnd, article})
+			once(key{Global, models.InsightDeadEnd, article})
+		}
+	}
+
+	groups := map[key][]models.PathInsight{}
+	for k, c := range counters {
+		g := key{routeId: k.routeId, kind: k.kind}
+		groups[g] = append(groups[g], models.PathInsight{
+			RouteId:    k.routeId,
+			Kind:       k.kind,
+			Article:    k.article,
+			Hits:       c.hits,
+			Successes:  c.successes,
+			ComputedAt: at,
+		})
+	}
+
+	res := []models.PathInsight{}
+	for g, insights := range groups {
+		sort.Slice(insights, func(i, j int) bool {
+			return better(g.kind, insights[i], insights[j])
+		})
+		if len(insights) > top {
+			insights = insights[:top]
+		}
+		for i := range insights {
+			insights[i].Place = i + 1
+		}
+		res = append(res, insights...)
+	}
+
+	return res
+}
+
+// better - функция, сравнивающая статьи в статистике: первые переходы - по количеству и доле успехов,
+// остальное - по количеству спринтов.
+func better(kind string, a, b models.PathInsight) bool {
+	if kind == models.InsightFirstClick {
+		if a.Successes != b.Successes {
+			return a.Successes > b.Successes
+		}
+		// a.Successes/a.Hits > b.Successes/b.Hits без деления.
+		if l, r := a.Successes*b.Hits, b.Successes*a.Hits; l != r {
+			return l > r
+		}
+	}
+	if a.Hits != b.Hits {
+		return a.Hits > b.Hits
+	}
+	if a.Successes != b.Successes {
+		return a.Successes > b.Successes
+	}
+
+	return a.Article < b.Article
+}
+
+// Intermediate - функция, возвращающая статьи пути без стартовой и конечной статей маршрута.
+func Intermediate(s models.PathSprint) []string {
+	steps := []string(s.Path)
+	if len(steps) != 0 && steps[0] == s.Start {
+		steps = steps[1:]
+	}
+	if len(steps) != 0 && steps[len(steps)-1] == s.Finish {
+		steps = steps[:len(steps)-1]
+	}
+
+	return steps
+}
+
+// DeadEnds - функция, возвращающая тупики спринта: статьи, из которых вернулись кнопкой "назад",
+// и статью, на которой закончился неуспешный спринт.
+func DeadEnds(s models.PathSprint) []string {
+	res := []string{}
+	for i := 1; i < len(s.Path) && i < len(s.BackSteps); i++ {
+		if s.BackSteps[i] && s.Path[i-1] != s.Finish {
+			res = append(res, s.Path[i-1])
+		}
+	}
+	if n := len(s.Path); !s.Success && n != 0 && s.Path[n-1] != s.Start && s.Path[n-1] != s.Finish {
+		res = append(res, s.Path[n-1])
+	}
+
+	return res
+}
@@ -0,0 +1,263 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/famusovsky/WikiSurfBack/internal/mailer"
+	"github.com/famusovsky/WikiSurfBack/internal/models"
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// userTokenKind - структура, описывающая вид одноразового токена пользователя и письмо с ним.
+type userTokenKind struct {
+	ttl     time.Duration // ttl - время жизни токена.
+	path    string        // path - путь ссылки с токеном.
+	subject string        // subject - тема письма.
+	text    string        // text - текст письма, содержащий место для ссылки.
+}
+
+// userTokenKinds - виды одноразовых токенов пользователя.
+var userTokenKinds = map[string]userTokenKind{
+	models.TokenVerify: {
+		ttl:     48 * time.Hour,
+		path:    "/auth/verify/",
+		subject: "Confirm your WikiSurf email",
+		text:    "Hi, %s!\n\nOpen the link below to confirm your email:\n%s\n\nThe link is valid for 48 hours.",
+	},
+	models.TokenReset: {
+		ttl:     time.Hour,
+		path:    "/auth/reset/",
+		subject: "Reset your WikiSurf password",
+		text:    "Hi, %s!\n\nOpen the link below to set a new password:\n%s\n\nThe link is valid for one hour. If you did not ask for a reset, ignore this email.",
+	},
+	models.TokenEmail: {
+		ttl:     24 * time.Hour,
+		path:    "/auth/email/",
+		subject: "Confirm your new WikiSurf email",
+		text:    "Hi, %s!\n\nOpen the link below to use this address for your WikiSurf account:\n%s\n\nThe link is valid for 24 hours.",
+	},
+}
+
+// sendUserToken - функция, создающая одноразовый токен пользователя и отправляющая ссылку с ним на почту email.
+func (app *App) sendUserToken(c *fiber.Ctx, user models.User, kind, email string) error {
+	k := userTokenKinds[kind]
+
+	token, err := newToken()
+	if err != nil {
+		return err
+	}
+
+	if err := app.db.AddUserToken(models.UserToken{
+		TokenHash: hashToken(token),
+		UserId:    user.Id,
+		Kind:      kind,
+		Email:     email,
+		ExpiresAt: time.Now().Add(k.ttl),
+	}); err != nil {
+		return err
+	}
+
+	return app.mail.Send(mailer.Message{
+		To:      email,
+		Subject: k.subject,
+		Body:    fmt.Sprintf(k.text, user.Name, app.baseUrl+k.path+token),
+	})
+}
+
+// renderNotice - функция, рендерящая страницу с сообщением пользователю.
+func (app *App) renderNotice(c *fiber.Ctx, title, text string) error {
+	return c.Render("notice", fiber.Map{
+		"title": title,
+		"text":  text,
+	}, "layouts/mini")
+}
+
+// verifyEmail - подтверждение почты пользователя по ссылке из письма.
+func (app *App) verifyEmail(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while verifying email")
+
+	if err := app.db.VerifyUserEmail(hashToken(c.Params("token"))); err != nil {
+		return app.renderErr(c, fiber.StatusBadRequest, errors.Join(wrapErr, err), "layouts/mini")
+	}
+
+	return app.renderNotice(c, "Email confirmed", "Your email address is confirmed.")
+}
+
+// resendVerification - повторная отправка письма для подтверждения почты.
+func (app *App) resendVerification(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while sending the verification email")
+	user, _ := app.getUser(c, wrapErr)
+
+	if user.Verified {
+		return c.SendString("Your email is already confirmed")
+	}
+
+	if err := app.sendUserToken(c, user, models.TokenVerify, user.Email); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err))
+	}
+
+	return c.SendString(fmt.Sprintf("A confirmation link has been sent to %s", user.Email))
+}
+
+// renderForgotPassword - рендер формы запроса сброса пароля.
+func (app *App) renderForgotPassword(c *fiber.Ctx) error {
+	return c.Render("auth/forgot", fiber.Map{})
+}
+
+// requestPasswordReset - отправка ссылки для сброса пароля.
+// Ответ не зависит от существования аккаунта, чтобы по нему нельзя было перебирать почты.
+func (app *App) requestPasswordReset(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while requesting password reset")
+
+	form := struct {
+		Email string
+	}{}
+	if err := c.BodyParser(&form); err != nil || form.Email == "" {
+		return app.errToResult(c, errors.Join(wrapErr, errors.New(`request's body is wrong`)))
+	}
+
+	if user, err := app.db.GetUser(strings.TrimSpace(form.Email)); err == nil {
+		if err := app.sendUserToken(c, user, models.TokenReset, user.Email); err != nil {
+			app.errLog.Println(errors.Join(wrapErr, err))
+		}
+	}
+
+	return c.SendString("If an account with this email exists, a reset link has been sent to it")
+}
+
+// renderResetPassword - рендер страницы ввода нового пароля.
+func (app *App) renderResetPassword(c *fiber.Ctx) error {
+	return c.Render("auth/reset", fiber.Map{
+		"token": c.Params("token"),
+	}, "layouts/mini")
+}
+
+// resetPassword - установка нового пароля по ссылке из письма.
+func (app *App) resetPassword(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while resetting password")
+
+	form := struct {
+		Password string
+	}{}
+	if err := c.BodyParser(&form); err != nil || form.Password == "" {
+		return app.errToResult(c, errors.Join(wrapErr, errors.New(`request's body is wrong`)))
+	}
+	if err := validatePassword(form.Password, models.User{}); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err))
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(form.Password), 8)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err))
+	}
+
+	if err := app.db.ResetUserPassword(hashToken(c.Params("token")), string(hashedPassword)); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err))
+	}
+
+	app.ch.Remove(c)
+
+	c.Set("HX-Location", "/auth")
+	return c.SendString("OK")
+}
+
+// confirmEmailChange - смена почты пользователя по ссылке из письма, отправленного на новый адрес.
+func (app *App) confirmEmailChange(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while changing email")
+
+	token, err := app.db.ConfirmUserEmail(hashToken(c.Params("token")))
+	if err != nil {
+		return app.renderErr(c, fiber.StatusBadRequest, errors.Join(wrapErr, err), "layouts/mini")
+	}
+
+	return app.renderNotice(c, "Email changed", fmt.Sprintf("Your account now uses %s.", token.Email))
+}
+
+// validatePassword - функция, проверяющая пароль на соответствие требованиям к сложности.
+func validatePassword(password string, user models.User) error {
+	if len(password) < 8 {
+		return errors.New("the password must be at least 8 characters long")
+	}
+	if len(password) > 72 {
+		return errors.New("the password must be at most 72 bytes long")
+	}
+
+	var letter, digit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			letter = true
+		case unicode.IsDigit(r):
+			digit = true
+		}
+	}
+	if !letter || !digit {
+		return errors.New("the password must contain both letters and digits")
+	}
+
+	lower := strings.ToLower(password)
+	if (user.Email != "" && strings.Contains(lower, strings.ToLower(user.Email))) ||
+		(user.Name != "" && strings.Contains(lower, strings.ToLower(user.Name))) {
+		return errors.New("the password must not contain your name or email")
+	}
+
+	return nil
+}
+
+// checkCurrentPassword - функция, проверяющая текущий пароль пользователя перед важными изменениями аккаунта.
+// Аккаунты без пароля (вход только через провайдера) проверку проходят.
+// Неудачные проверки учитываются в блокировке входа в аккаунт.
+func (app *App) checkCurrentPassword(c *fiber.Ctx, user models.User, password string) error {
+	// У аккаунтов, созданных через внешнего провайдера, пароля нет: подтверждать нечем.
+	if user.Password == "" {
+		return nil
+	}
+
+	key := strings.ToLower(user.Email)
+	if locked, wait := app.limits.lockout.Locked(key); locked {
+		return fmt.Errorf("too many failed attempts, try again in %d s", int(wait.Seconds())+1)
+	}
+
+	if password == "" {
+		return errors.New("enter your current password to confirm the change")
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		app.limits.lockout.Fail(key)
+		return errors.New("the current password is wrong")
+	}
+	app.limits.lockout.Reset(key)
+
+	return nil
+}
+
+// deleteAccount - удаление аккаунта пользователя с обезличиванием его данных.
+func (app *App) deleteAccount(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while deleting the account")
+	user, ok := app.getUser(c, wrapErr)
+	if !ok {
+		return app.errToResult(c, errors.Join(wrapErr, errors.New("you are not signed in")))
+	}
+
+	form := struct {
+		Current string
+	}{}
+	c.BodyParser(&form)
+	if err := app.checkCurrentPassword(c, user, form.Current); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err))
+	}
+
+	if err := app.db.DeleteUser(user.Id); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err))
+	}
+
+	app.ch.Remove(c)
+	app.infoLog.Printf("user #%d deleted the account\n", user.Id)
+
+	c.Set("HX-Location", "/auth")
+	return c.SendString("OK")
+}
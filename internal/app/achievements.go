@@ -0,0 +1,45 @@
+package app
+
+import (
+	"errors"
+
+	"github.com/famusovsky/WikiSurfBack/internal/achievements"
+)
+
+// badgesTemplate - шаблон значков достижений в строках рейтингов.
+const badgesTemplate = `{{range .Badges}}<span title="{{.Title}}: {{.Description}}">{{.Icon}}</span>{{end}}`
+
+// awardAchievements - функция, выдающая пользователю заработанные достижения.
+func (app *App) awardAchievements(userId int) {
+	added, err := achievements.Award(app.db, userId)
+	if err != nil {
+		app.errLog.Println(errors.Join(errors.New("error while awarding achievements"), err))
+		return
+	}
+
+	for _, id := range added {
+		app.infoLog.Printf("user #%d earned the %q achievement\n", userId, id)
+	}
+}
+
+// getBadges - функция, возвращающая достижения пользователей для показа значков.
+func (app *App) getBadges(userIds []int) map[int][]achievements.Achievement {
+	res := make(map[int][]achievements.Achievement, len(userIds))
+	if len(userIds) == 0 {
+		return res
+	}
+
+	awards, err := app.db.GetUsersAchievements(userIds)
+	if err != nil {
+		app.errLog.Println(errors.Join(errors.New("error while getting badges"), err))
+		return res
+	}
+
+	for _, award := range awards {
+		if a, ok := achievements.Get(award.Achievement); ok {
+			res[award.UserId] = append(res[award.UserId], a)
+		}
+	}
+
+	return res
+}
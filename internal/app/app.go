@@ -4,27 +4,49 @@ import (
 	"html/template"
 	"log"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/famusovsky/WikiSurfBack/internal/mailer"
+	"github.com/famusovsky/WikiSurfBack/internal/oidc"
 	"github.com/famusovsky/WikiSurfBack/internal/postgres"
+	"github.com/famusovsky/WikiSurfBack/internal/race"
+	"github.com/famusovsky/WikiSurfBack/internal/ratelimit"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/template/html/v2"
 )
 
 // App - структура, представляющая собой приложение.
 type App struct {
-	web     *fiber.App         // web - веб-приложение на основе фреймворка Fiber.
-	db      postgres.DbHandler // db - обработчик БД.
-	ch      cookieHandler      // ch - обработчик cookie.
-	infoLog *log.Logger        // infoLog - логгер информации.
-	errLog  *log.Logger        // errorLog - логгер ошибок.
+	web       *fiber.App         // web - веб-приложение на основе фреймворка Fiber.
+	db        postgres.DbHandler // db - обработчик БД.
+	ch        cookieHandler      // ch - обработчик cookie.
+	races     *race.Hub          // races - хаб комнат живых заездов.
+	limits    *rateLimits        // limits - ограничители частоты запросов.
+	mail      mailer.Mailer      // mail - отправитель писем.
+	oidcState cookieHandler      // oidcState - обработчик cookie состояния входа через внешних провайдеров.
+	providers []oidc.Provider    // providers - внешние провайдеры входа.
+	baseUrl   string             // baseUrl - публичный адрес сервера для ссылок в письмах и ответах.
+	extOrigin []string           // extOrigin - Origin доверенных браузерных расширений, например chrome-extension://<id>.
+	skillMu   sync.Mutex         // skillMu - мьютекс, упорядочивающий обновления рейтинга мастерства.
+	infoLog   *log.Logger        // infoLog - логгер информации.
+	errLog    *log.Logger        // errorLog - логгер ошибок.
+	stop      chan struct{}      // stop - канал, закрываемый при остановке фоновых задач.
+}
+
+// Config - структура, описывающая настройки развёртывания приложения.
+type Config struct {
+	BaseUrl          string   // BaseUrl - публичный адрес сервера, из которого строятся ссылки в письмах и ответах.
+	ExtensionOrigins []string // ExtensionOrigins - Origin доверенных браузерных расширений, освобождённых от проверки CSRF.
 }
 
 // CreateApp - создание приложения.
 //
-// Принимает: логгеры, обработчик БД.
+// Принимает: обработчик БД, отправитель писем, внешние провайдеры входа, настройки развёртывания, логгеры.
 //
 // Возвращает: приложение.
-func CreateApp(db postgres.DbHandler, infoLog, errLog *log.Logger) *App {
+func CreateApp(db postgres.DbHandler, mail mailer.Mailer, providers []oidc.Provider, cfg Config, infoLog, errLog *log.Logger) *App {
 	engine := html.New("./ui/views", ".html")
 	engine.AddFunc(
 		"unescape", func(s string) template.HTML {
@@ -40,15 +62,24 @@ func CreateApp(db postgres.DbHandler, infoLog, errLog *log.Logger) *App {
 				"errText": err.Error(),
 			}, "layouts/mini")
 		},
-		Views: engine,
+		Views:             engine,
+		PassLocalsToViews: true,
 	})
 
 	result := &App{
-		web:     application,
-		db:      db,
-		ch:      getCookieHandler("user-info", "email"),
-		infoLog: infoLog,
-		errLog:  errLog,
+		web:       application,
+		db:        db,
+		ch:        getCookieHandler("user-info", "session"),
+		races:     race.NewHub(10 * time.Second),
+		limits:    newRateLimits(ratelimit.NewMemoryStore()),
+		mail:      mail,
+		oidcState: getCookieHandler("oidc-state", "state"),
+		providers: providers,
+		baseUrl:   strings.TrimSuffix(cfg.BaseUrl, "/"),
+		extOrigin: cfg.ExtensionOrigins,
+		infoLog:   infoLog,
+		errLog:    errLog,
+		stop:      make(chan struct{}),
 	}
 
 	setRoutes(result)
@@ -60,10 +91,13 @@ func CreateApp(db postgres.DbHandler, infoLog, errLog *log.Logger) *App {
 //
 // Принимает: адрес.
 func (app *App) Run(addr string) {
+	go app.runScheduler(time.Minute, app.stop)
+	go app.runInsights(time.Hour, app.stop)
 	app.errLog.Fatalln(app.web.Listen(addr))
 }
 
 // Shutdown - изящное отключение сервера.
 func (app *App) Shutdown() error {
+	close(app.stop)
 	return app.web.Shutdown()
 }
@@ -2,6 +2,7 @@ package app
 
 import (
 	"errors"
+	"strings"
 
 	"github.com/badoux/checkmail"
 	"github.com/famusovsky/WikiSurfBack/internal/models"
@@ -23,6 +24,10 @@ func (app *App) signUp(c *fiber.Ctx) error {
 		return app.errToResult(c, errors.Join(wrapErr, err))
 	}
 
+	if err := validatePassword(user.Password, user); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err))
+	}
+
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), 8)
 	if err != nil {
 		return app.errToResult(c, errors.Join(wrapErr, err))
@@ -30,12 +35,16 @@ func (app *App) signUp(c *fiber.Ctx) error {
 
 	user.Password = string(hashedPassword)
 
-	_, err = app.db.AddUser(user)
+	user.Id, err = app.db.AddUser(user)
 	if err != nil {
 		return app.errToResult(c, errors.Join(wrapErr, err))
 	}
 
-	app.ch.Set(c, user.Email)
+	if err := app.sendUserToken(c, user, models.TokenVerify, user.Email); err != nil {
+		app.errLog.Println(errors.Join(wrapErr, err))
+	}
+
+	app.setSession(c, user)
 
 	app.infoLog.Printf("user %s signed up\n", user.Name)
 
@@ -53,16 +62,25 @@ func (app *App) signIn(c *fiber.Ctx) error {
 		return app.errToResult(c, errors.Join(wrapErr, errors.Join(wrapErr, errors.New(`request's body is wrong`))))
 	}
 
+	key := strings.ToLower(strings.TrimSpace(creds.Email))
+	if locked, wait := app.limits.lockout.Locked(key); locked {
+		return app.tooManyRequests(c, wait)
+	}
+
 	user, err := app.db.GetUser(creds.Email)
 	if err != nil {
 		return app.errToResult(c, errors.Join(wrapErr, err))
 	}
 
 	if err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(creds.Password)); err != nil {
+		if d := app.limits.lockout.Fail(key); d > 0 {
+			app.infoLog.Printf("user %s is locked out for %v after failed sign in attempts\n", user.Name, d)
+		}
 		return app.errToResult(c, errors.Join(wrapErr, err))
 	}
+	app.limits.lockout.Reset(key)
 
-	app.ch.Set(c, user.Email)
+	app.setSession(c, user)
 
 	app.infoLog.Printf("user %s signed in\n", user.Name)
 
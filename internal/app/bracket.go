@@ -0,0 +1,416 @@
+package app
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/famusovsky/WikiSurfBack/internal/bracket"
+	"github.com/famusovsky/WikiSurfBack/internal/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// bracketMatchView - структура, хранящая данные матча сетки для рендера.
+type bracketMatchView struct {
+	Num       int
+	RouteId   int
+	Player1   bracketPlayerView
+	Player2   bracketPlayerView
+	Finished  bool
+	CanSettle bool
+}
+
+// bracketPlayerView - структура, хранящая данные участника матча сетки для рендера.
+type bracketPlayerView struct {
+	Id     int
+	Name   string
+	Winner bool
+}
+
+// bracketRoundView - структура, хранящая данные раунда сетки для рендера.
+type bracketRoundView struct {
+	Name    string
+	Matches []bracketMatchView
+}
+
+// createBracket - функция, строящая сетку на выбывание по посеву участников по общему рейтингу.
+func (app *App) createBracket(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while creating the bracket")
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#bracketResult")
+	}
+
+	user, _ := app.getUser(c, wrapErr)
+	ok, err := app.db.CheckTournamentCreator(id, user.Id)
+	if !ok || err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#bracketResult")
+	}
+
+	if err := app.checkTourNotArchived(id); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#bracketResult")
+	}
+
+	kind := struct {
+		Kind string
+	}{}
+	if err := c.BodyParser(&kind); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#bracketResult")
+	}
+
+	routes, err := app.db.GetTournamentRoutes(id)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#bracketResult")
+	}
+	if len(routes) == 0 {
+		return app.errToResult(c, errors.Join(wrapErr, errors.New("the tournament has no routes")), "#bracketResult")
+	}
+
+	seeds, err := app.getSeeds(id)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#bracketResult")
+	}
+
+	var matches []models.BracketMatch
+	switch kind.Kind {
+	case models.SingleElimination:
+		matches, err = bracket.Single(seeds)
+	case models.DoubleElimination:
+		matches, err = bracket.Double(seeds)
+	default:
+		err = fmt.Errorf("unknown bracket kind %q", kind.Kind)
+	}
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#bracketResult")
+	}
+
+	now := time.Now()
+	bracket.MarkReady(matches, now)
+	for i := range matches {
+		if matches[i].Side == models.BracketFinal {
+			matches[i].RouteId = routes[len(routes)-1].Id
+		} else {
+			matches[i].RouteId = routes[(matches[i].Round-1)%len(routes)].Id
+		}
+	}
+
+	if err := app.db.AddBracket(models.Bracket{
+		TournamentId: id,
+		Kind:         kind.Kind,
+		CreatedAt:    now,
+	}, matches, user.Id); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#bracketResult")
+	}
+
+	return c.Redirect(fmt.Sprintf("/tournament/edit/%d", id))
+}
+
+// deleteBracket - функция, удаляющая сетку соревнования.
+func (app *App) deleteBracket(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while deleting the bracket")
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#bracketResult")
+	}
+
+	user, _ := app.getUser(c, wrapErr)
+	if err := app.db.DeleteBracket(id, user.Id); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#bracketResult")
+	}
+
+	return c.Redirect(fmt.Sprintf("/tournament/edit/%d", id))
+}
+
+// getSeeds - функция, возвращающая id участников соревнования в порядке посева по общему рейтингу.
+func (app *App) getSeeds(tourId int) ([]int, error) {
+	participants, err := app.db.GetTournamentParticipants(tourId)
+	if err != nil {
+		return nil, err
+	}
+
+	ratings, err := app.db.GetRatings()
+	if err != nil {
+		return nil, err
+	}
+	points := make(map[int]int, len(ratings))
+	for _, r := range ratings {
+		points[r.UserId] = r.Points
+	}
+
+	seeds := make([]int, len(participants))
+	for i, p := range participants {
+		seeds[i] = p.Id
+	}
+	sort.SliceStable(seeds, func(i, j int) bool {
+		return points[seeds[i]] > points[seeds[j]]
+	})
+
+	return seeds, nil
+}
+
+// renderBracket - функция производящая рендер сетки соревнования.
+func (app *App) renderBracket(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while getting the bracket")
+	user, _ := app.getUser(c, wrapErr)
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return app.renderErr(c, fiber.StatusBadRequest, errors.Join(wrapErr, err), "")
+	}
+
+	tour, err := app.db.GetTournament(id)
+	if err != nil {
+		return app.renderErr(c, fiber.StatusNotFound, errors.Join(wrapErr, err), "")
+	}
+	if !app.canSeeTour(tour, user.Id) {
+		return app.renderErr(c, fiber.StatusForbidden, errors.Join(wrapErr, errors.New("the tournament is private")), "")
+	}
+
+	b, err := app.db.GetBracket(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return c.SendString("")
+	}
+	if err != nil {
+		return app.renderErr(c, fiber.StatusInternalServerError, errors.Join(wrapErr, err), "")
+	}
+
+	matches, err := app.db.GetBracketMatches(id)
+	if err != nil {
+		return app.renderErr(c, fiber.StatusInternalServerError, errors.Join(wrapErr, err), "")
+	}
+
+	participants, err := app.db.GetTournamentParticipants(id)
+	if err != nil {
+		return app.renderErr(c, fiber.StatusInternalServerError, errors.Join(wrapErr, err), "")
+	}
+	names := make(map[int]string, len(participants))
+	for _, p := range participants {
+		names[p.Id] = p.Name
+	}
+
+	isCreator, err := app.db.CheckTournamentCreator(id, user.Id)
+	if err != nil {
+		return app.renderErr(c, fiber.StatusInternalServerError, errors.Join(wrapErr, err), "")
+	}
+
+	player := func(id, winner int) bracketPlayerView {
+		res := bracketPlayerView{Id: id, Winner: id > 0 && id == winner}
+		switch id {
+		case models.SlotPending:
+			res.Name = "TBD"
+		case models.SlotEmpty:
+			res.Name = "-"
+		default:
+			if name, ok := names[id]; ok {
+				res.Name = name
+			} else {
+				res.Name = fmt.Sprintf("User with id:%d", id)
+			}
+		}
+		return res
+	}
+
+	var rounds []bracketRoundView
+	index := map[string]int{}
+	for _, m := range matches {
+		key := fmt.Sprintf("%s %d", m.Side, m.Round)
+		if _, ok := index[key]; !ok {
+			name := fmt.Sprintf("Round %d", m.Round)
+			switch m.Side {
+			case models.BracketLosers:
+				name = fmt.Sprintf("Lower bracket, round %d", m.Round)
+			case models.BracketFinal:
+				name = "Grand final"
+			}
+			index[key] = len(rounds)
+			rounds = append(rounds, bracketRoundView{Name: name})
+		}
+
+		ready := m.Player1 > 0 && m.Player2 > 0 && m.Winner == models.SlotPending
+		i := index[key]
+		rounds[i].Matches = append(rounds[i].Matches, bracketMatchView{
+			Num:       m.Num,
+			RouteId:   m.RouteId,
+			Player1:   player(m.Player1, m.Winner),
+			Player2:   player(m.Player2, m.Winner),
+			Finished:  m.Winner != models.SlotPending,
+			CanSettle: ready && (isCreator || m.Player1 == user.Id || m.Player2 == user.Id),
+		})
+	}
+
+	champion := ""
+	if winner := bracket.Champion(matches); winner > 0 {
+		champion = player(winner, winner).Name
+	}
+
+	return c.Render("partials/bracket", fiber.Map{
+		"ind":       c.Params("id"),
+		"kind":      b.Kind,
+		"rounds":    rounds,
+		"champion":  champion,
+		"isCreator": isCreator,
+	})
+}
+
+// getBracketJson - функция, возвращающая сетку соревнования в формате JSON.
+func (app *App) getBracketJson(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while getting the bracket")
+	user, _ := app.getUser(c, wrapErr)
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": errors.Join(wrapErr, err).Error()})
+	}
+
+	tour, err := app.db.GetTournament(id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": errors.Join(wrapErr, err).Error()})
+	}
+	if !app.canSeeTour(tour, user.Id) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": errors.Join(wrapErr, errors.New("the tournament is private")).Error()})
+	}
+
+	b, err := app.db.GetBracket(id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": errors.Join(wrapErr, err).Error()})
+	}
+
+	matches, err := app.db.GetBracketMatches(id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": errors.Join(wrapErr, err).Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"bracket":  b,
+		"matches":  matches,
+		"champion": bracket.Champion(matches),
+	})
+}
+
+// settleBracketMatch - функция, определяющая победителя матча сетки по лучшим спринтам участников на маршруте матча.
+//
+// Учитываются спринты, начатые после того, как определились оба участника матча.
+// Если спринт есть только у одного из участников, он побеждает лишь после окончания соревнования.
+func (app *App) settleBracketMatch(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while settling the bracket match")
+	user, _ := app.getUser(c, wrapErr)
+
+	id, num, err := getBracketMatchParams(c)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#bracketResult")
+	}
+
+	if err := app.checkTourNotArchived(id); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#bracketResult")
+	}
+
+	tour, err := app.db.GetTournament(id)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#bracketResult")
+	}
+	b, err := app.db.GetBracket(id)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#bracketResult")
+	}
+	matches, err := app.db.GetBracketMatches(id)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#bracketResult")
+	}
+	if num < 0 || num >= len(matches) {
+		return app.errToResult(c, errors.Join(wrapErr, bracket.ErrNoMatch), "#bracketResult")
+	}
+	m := matches[num]
+
+	isCreator, err := app.db.CheckTournamentCreator(id, user.Id)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#bracketResult")
+	}
+	if !isCreator && m.Player1 != user.Id && m.Player2 != user.Id {
+		return app.errToResult(c, errors.Join(wrapErr, bracket.ErrNotAPlayer), "#bracketResult")
+	}
+	if m.Player1 <= 0 || m.Player2 <= 0 {
+		return app.errToResult(c, errors.Join(wrapErr, bracket.ErrNotReady), "#bracketResult")
+	}
+
+	from := b.CreatedAt
+	if m.ReadyAt != nil {
+		from = *m.ReadyAt
+	}
+	s1, err1 := app.db.GetUserBestSprintInWindow(m.Player1, m.RouteId, from, tour.EndTime)
+	s2, err2 := app.db.GetUserBestSprintInWindow(m.Player2, m.RouteId, from, tour.EndTime)
+	ended := time.Now().After(tour.EndTime)
+
+	var winner int
+	switch {
+	case err1 == nil && err2 == nil:
+		winner = m.Player1
+		if s2.LengthTime < s1.LengthTime || (s2.LengthTime == s1.LengthTime && s2.Id < s1.Id) {
+			winner = m.Player2
+		}
+	case err1 == nil && ended:
+		winner = m.Player1
+	case err2 == nil && ended:
+		winner = m.Player2
+	case ended:
+		return app.errToResult(c, errors.Join(wrapErr, errors.New("no one has finished the route, the winner must be set by a creator")), "#bracketResult")
+	default:
+		return app.errToResult(c, errors.Join(wrapErr, errors.New("both participants must finish the route first")), "#bracketResult")
+	}
+
+	if err := app.db.ResolveBracketMatch(id, num, winner); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#bracketResult")
+	}
+
+	return app.renderBracket(c)
+}
+
+// setBracketWinner - функция, позволяющая создателю соревнования вручную задать победителя матча сетки.
+func (app *App) setBracketWinner(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while setting the bracket match winner")
+	user, _ := app.getUser(c, wrapErr)
+
+	id, num, err := getBracketMatchParams(c)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#bracketResult")
+	}
+
+	if err := app.checkTourNotArchived(id); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#bracketResult")
+	}
+
+	ok, err := app.db.CheckTournamentCreator(id, user.Id)
+	if !ok || err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, errors.New("user is not the tournament's creator"), err), "#bracketResult")
+	}
+
+	winner := struct {
+		Winner int
+	}{}
+	if err := c.BodyParser(&winner); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#bracketResult")
+	}
+
+	if err := app.db.ResolveBracketMatch(id, num, winner.Winner); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#bracketResult")
+	}
+
+	return app.renderBracket(c)
+}
+
+// getBracketMatchParams - функция, возвращающая id соревнования и номер матча из параметров запроса.
+func getBracketMatchParams(c *fiber.Ctx) (int, int, error) {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return 0, 0, err
+	}
+	num, err := strconv.Atoi(c.Params("num"))
+	if err != nil {
+		return 0, 0, err
+	}
+	return id, num, nil
+}
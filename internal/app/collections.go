@@ -0,0 +1,293 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/famusovsky/WikiSurfBack/internal/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxCollectionName - максимальная длина названия подборки.
+const maxCollectionName = 100
+
+// renderCollections - функция производящая рендер страницы подборок маршрутов.
+func (app *App) renderCollections(c *fiber.Ctx) error {
+	return c.Render("collections", fiber.Map{}, "layouts/base")
+}
+
+// renderCollectionList - функция производящая рендер списка подборок: своих или открытых.
+func (app *App) renderCollectionList(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while getting collections")
+	user, _ := app.getUser(c, wrapErr)
+
+	var (
+		collections []models.Collection
+		name        string
+		err         error
+	)
+	if c.Query("kind") == "public" {
+		collections, err = app.db.GetPublicCollections()
+		name = "Public collections"
+	} else {
+		collections, err = app.db.GetUserCollections(user.Id)
+		name = "My collections"
+	}
+	if err != nil {
+		return app.renderErr(c, fiber.StatusNotFound, errors.Join(wrapErr, err))
+	}
+
+	var b bytes.Buffer
+	q := `{{range .}}<tr hx-get={{printf "/collection/%d" .Id }} hx-target="body"><td>#{{.Id}} {{.Name}}{{if .Private}} (private){{end}}</td></tr>{{end}}`
+	t := template.Must(template.New("").Parse(q))
+	if err := t.Execute(&b, collections); err != nil {
+		return app.renderErr(c, fiber.StatusInternalServerError, errors.Join(wrapErr, err))
+	}
+
+	return c.Render("partials/tourList", fiber.Map{
+		"name":  name,
+		"tbody": b.String(),
+	})
+}
+
+// getVisibleCollection - функция, получающая подборку из параметров запроса, если пользователь может её видеть.
+func (app *App) getVisibleCollection(c *fiber.Ctx, userId int) (models.Collection, error) {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return models.Collection{}, err
+	}
+
+	collection, err := app.db.GetCollection(id)
+	if err != nil {
+		return models.Collection{}, err
+	}
+	if collection.Private && collection.OwnerId != userId {
+		return models.Collection{}, errors.New("the collection is private")
+	}
+
+	return collection, nil
+}
+
+// getOwnCollection - функция, получающая подборку из параметров запроса и проверяющая её автора.
+func (app *App) getOwnCollection(c *fiber.Ctx, userId int) (models.Collection, error) {
+	collection, err := app.getVisibleCollection(c, userId)
+	if err != nil {
+		return models.Collection{}, err
+	}
+	if collection.OwnerId != userId {
+		return models.Collection{}, errors.New("only the collection owner can change it")
+	}
+
+	return collection, nil
+}
+
+// renderCollection - функция производящая рендер страницы подборки с прогрессом её прохождения.
+func (app *App) renderCollection(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while getting a collection")
+	user, _ := app.getUser(c, wrapErr)
+
+	collection, err := app.getVisibleCollection(c, user.Id)
+	if err != nil {
+		return app.renderErr(c, fiber.StatusNotFound, errors.Join(wrapErr, err))
+	}
+
+	routes, err := app.db.GetCollectionRoutes(collection.Id, user.Id)
+	if err != nil {
+		return app.renderErr(c, fiber.StatusNotFound, errors.Join(wrapErr, err))
+	}
+
+	completed := 0
+	for _, r := range routes {
+		if r.Completed {
+			completed++
+		}
+	}
+
+	owner := collection.OwnerId == user.Id
+	var b bytes.Buffer
+	q := `{{range $i, $r := .routes}}<tr><td>{{inc $i}}</td>
+	<td hx-get={{printf "/route/%d" $r.Id }} hx-target="body">{{$r.Start}}</td>
+	<td hx-get={{printf "/route/%d" $r.Id }} hx-target="body">{{$r.Finish}}</td>
+	<td>{{if $r.Completed}}✓{{end}}</td>
+	{{if $.owner}}<td>
+	<button hx-post={{printf "/service/collection/%d/route/%d/move?dir=up" $.id $r.Id }} hx-target="body">↑</button>
+	<button hx-post={{printf "/service/collection/%d/route/%d/move?dir=down" $.id $r.Id }} hx-target="body">↓</button>
+	<button hx-delete={{printf "/service/collection/%d/route/%d" $.id $r.Id }} hx-target="body">Remove</button>
+	</td>{{end}}</tr>{{end}}`
+	t := template.Must(template.New("").Funcs(template.FuncMap{
+		"inc": func(i int) int { return i + 1 },
+	}).Parse(q))
+	if err := t.Execute(&b, fiber.Map{"id": collection.Id, "owner": owner, "routes": routes}); err != nil {
+		return app.renderErr(c, fiber.StatusInternalServerError, errors.Join(wrapErr, err))
+	}
+
+	return c.Render("collection", fiber.Map{
+		"ind":         strconv.Itoa(collection.Id),
+		"name":        collection.Name,
+		"description": collection.Description,
+		"privacy":     collection.Private,
+		"owner":       owner,
+		"completed":   completed,
+		"total":       len(routes),
+		"routesTbody": b.String(),
+	}, "layouts/base")
+}
+
+// createCollection - создание подборки маршрутов.
+func (app *App) createCollection(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while creating the collection")
+	user, _ := app.getUser(c, wrapErr)
+
+	collection := models.Collection{
+		Name:        strings.TrimSpace(c.FormValue("name")),
+		Description: strings.TrimSpace(c.FormValue("description")),
+		OwnerId:     user.Id,
+		Private:     c.FormValue("private") != "",
+		CreatedAt:   time.Now(),
+	}
+	if collection.Name == "" || len(collection.Name) > maxCollectionName {
+		return app.errToResult(c, errors.Join(wrapErr, fmt.Errorf("the name must be 1 to %d characters long", maxCollectionName)))
+	}
+
+	id, err := app.db.AddCollection(collection)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err))
+	}
+
+	return c.Redirect(fmt.Sprintf("/collection/%d", id))
+}
+
+// addRouteToCollection - добавление маршрута в конец подборки.
+func (app *App) addRouteToCollection(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while adding route to the collection")
+	user, _ := app.getUser(c, wrapErr)
+
+	collection, err := app.getOwnCollection(c, user.Id)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#routesResult")
+	}
+
+	route, err := app.getOrCreateRoute(c)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#routesResult")
+	}
+
+	if err := app.db.AddRouteToCollection(collection.Id, route.Id); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#routesResult")
+	}
+
+	return c.Redirect(fmt.Sprintf("/collection/%d", collection.Id))
+}
+
+// removeRouteFromCollection - удаление маршрута из подборки.
+func (app *App) removeRouteFromCollection(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while removing route from the collection")
+	user, _ := app.getUser(c, wrapErr)
+
+	collection, err := app.getOwnCollection(c, user.Id)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#routesResult")
+	}
+	routeId, err := strconv.Atoi(c.Params("route"))
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#routesResult")
+	}
+
+	if err := app.db.RemoveRouteFromCollection(collection.Id, routeId); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#routesResult")
+	}
+
+	return c.Redirect(fmt.Sprintf("/collection/%d", collection.Id))
+}
+
+// moveCollectionRoute - перемещение маршрута подборки на одну позицию вверх или вниз.
+func (app *App) moveCollectionRoute(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while moving route in the collection")
+	user, _ := app.getUser(c, wrapErr)
+
+	collection, err := app.getOwnCollection(c, user.Id)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#routesResult")
+	}
+	routeId, err := strconv.Atoi(c.Params("route"))
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#routesResult")
+	}
+
+	if err := app.db.MoveCollectionRoute(collection.Id, routeId, c.Query("dir") == "up"); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#routesResult")
+	}
+
+	return c.Redirect(fmt.Sprintf("/collection/%d", collection.Id))
+}
+
+// toggleCollectionPrivacy - переключение видимости подборки.
+func (app *App) toggleCollectionPrivacy(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while toggling collection privacy")
+	user, _ := app.getUser(c, wrapErr)
+
+	collection, err := app.getOwnCollection(c, user.Id)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err))
+	}
+
+	if err := app.db.ToggleCollectionPrivacy(collection.Id, user.Id); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err))
+	}
+
+	return c.Redirect(fmt.Sprintf("/collection/%d", collection.Id))
+}
+
+// deleteCollection - удаление подборки.
+func (app *App) deleteCollection(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while deleting the collection")
+	user, _ := app.getUser(c, wrapErr)
+
+	collection, err := app.getOwnCollection(c, user.Id)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err))
+	}
+
+	if err := app.db.DeleteCollection(collection.Id, user.Id); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err))
+	}
+
+	return c.Redirect("/collections")
+}
+
+// addCollectionToTour - добавление в соревнование всех маршрутов подборки.
+func (app *App) addCollectionToTour(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while adding collection routes to the tour")
+	user, _ := app.getUser(c, wrapErr)
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#routesResult")
+	}
+	if err := app.checkTourNotArchived(id); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#routesResult")
+	}
+
+	collectionId, err := strconv.Atoi(c.FormValue("collection"))
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, errors.New("collection id must be a number")), "#routesResult")
+	}
+	collection, err := app.db.GetCollection(collectionId)
+	if err != nil || (collection.Private && collection.OwnerId != user.Id) {
+		return app.errToResult(c, errors.Join(wrapErr, errors.New("collection not found"), err), "#routesResult")
+	}
+
+	added, err := app.db.AddCollectionToTour(id, collection.Id, user.Id)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#routesResult")
+	}
+	app.infoLog.Printf("%d routes of collection #%d added to tournament #%d by user #%d\n", added, collection.Id, id, user.Id)
+
+	c.Set("HX-Location", fmt.Sprintf("/tournament/edit/%d", id))
+	return c.SendString("OK")
+}
@@ -26,18 +26,20 @@ func getCookieHandler(cookie, val string) cookieHandler {
 }
 
 // Set - функция, устанавливающая в http.Response куки с данным именем и значением.
-func (c *cookieHandler) Set(ctx *fiber.Ctx, email string) {
+func (c *cookieHandler) Set(ctx *fiber.Ctx, val string) {
 	value := map[string]string{
-		c.val: email,
+		c.val: val,
 	}
 	if encoded, err := c.instance.Encode(c.name, value); err == nil {
 		now := time.Now()
 		cookie := &fiber.Cookie{
-			Name:    c.name,
-			Value:   encoded,
-			Path:    "/",
-			Secure:  true,
-			Expires: now.Add(7 * 24 * time.Hour),
+			Name:     c.name,
+			Value:    encoded,
+			Path:     "/",
+			Secure:   true,
+			HTTPOnly: true,
+			SameSite: fiber.CookieSameSiteLaxMode,
+			Expires:  now.Add(7 * 24 * time.Hour),
 		}
 		ctx.Cookie(cookie)
 	}
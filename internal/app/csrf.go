@@ -0,0 +1,48 @@
+package app
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/csrf"
+)
+
+// csrfHeader - заголовок, в котором htmx передаёт CSRF токен, встроенный в шаблоны.
+const csrfHeader = "X-CSRF-Token"
+
+// csrfProtection - middleware, проверяющий CSRF токен в изменяющих запросах.
+//
+// Токен выдаётся в cookie и в c.Locals("csrf"), откуда попадает в шаблоны и заголовок hx-headers макетов.
+// Проверка пропускается для запросов с действительным персональным токеном и для запросов из доверенных браузерных расширений.
+func (app *App) csrfProtection() fiber.Handler {
+	return csrf.New(csrf.Config{
+		KeyLookup:      "header:" + csrfHeader,
+		CookieName:     "csrf",
+		CookiePath:     "/",
+		CookieSecure:   true,
+		CookieHTTPOnly: true,
+		CookieSameSite: fiber.CookieSameSiteLaxMode,
+		Expiration:     24 * time.Hour,
+		ContextKey:     "csrf",
+		Next:           app.skipCsrf,
+		ErrorHandler: func(c *fiber.Ctx, err error) error {
+			c.Status(fiber.StatusForbidden)
+			return app.errToResult(c, errors.Join(errors.New("the request did not pass the CSRF check, reload the page and try again"), err))
+		},
+	})
+}
+
+// skipCsrf - функция, определяющая запросы, которым не нужна проверка CSRF токена.
+//
+// Запрос с персональным токеном пропускается только после проверки токена: такой запрос
+// авторизуется токеном, а не cookie. Origin расширения сравнивается с настроенными целиком,
+// иначе любое установленное расширение могло бы отправлять запросы с cookie пользователя.
+func (app *App) skipCsrf(c *fiber.Ctx) bool {
+	if used, err := app.authByToken(c); used && err == nil {
+		return true
+	}
+
+	return slices.Contains(app.extOrigin, c.Get(fiber.HeaderOrigin))
+}
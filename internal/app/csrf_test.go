@@ -0,0 +1,171 @@
+package app
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/famusovsky/WikiSurfBack/internal/mailer"
+	"github.com/famusovsky/WikiSurfBack/internal/models"
+	"github.com/famusovsky/WikiSurfBack/internal/postgres"
+)
+
+// csrfDb - обработчик БД с одним пользователем для проверки CSRF.
+type csrfDb struct {
+	postgres.DbHandler
+	user models.User
+}
+
+// GetUserById implements postgres.DbHandler.
+func (d *csrfDb) GetUserById(id int) (models.User, error) {
+	if id != d.user.Id {
+		return models.User{}, errors.New("user not found")
+	}
+	return d.user, nil
+}
+
+// UpdateUser implements postgres.DbHandler.
+func (d *csrfDb) UpdateUser(user models.User) error {
+	d.user = user
+	return nil
+}
+
+// UseApiToken implements postgres.DbHandler.
+func (d *csrfDb) UseApiToken(hash string) (models.ApiToken, error) {
+	if hash != hashToken("wst_valid") {
+		return models.ApiToken{}, errors.New("token not found")
+	}
+	return models.ApiToken{UserId: d.user.Id, Scopes: models.ScopeRead}, nil
+}
+
+// newCsrfApp - функция, создающая приложение с авторизованным пользователем и возвращающая его cookie сессии и CSRF токен.
+func newCsrfApp(t *testing.T) (*App, *csrfDb, string, string) {
+	t.Helper()
+
+	// шаблоны загружаются относительно корня репозитория
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir("../.."); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	db := &csrfDb{user: models.User{Id: 7, Name: "alice", Email: "alice@example.com", Session: 1}}
+	logger := log.New(io.Discard, "", 0)
+	app := CreateApp(db, mailer.NewLog(logger), nil, Config{
+		BaseUrl:          "https://wikisurf.example.com",
+		ExtensionOrigins: []string{"chrome-extension://trusted"},
+	}, logger, logger)
+
+	session, err := app.ch.instance.Encode(app.ch.name, map[string]string{app.ch.val: "7:1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessionCookie := app.ch.name + "=" + session
+
+	resp, err := app.web.Test(httptest.NewRequest(http.MethodGet, "/auth/signin", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var token string
+	for _, c := range resp.Cookies() {
+		if c.Name == "csrf" {
+			token = c.Value
+		}
+	}
+	if token == "" {
+		t.Fatal("csrf cookie is not issued on a GET request")
+	}
+
+	return app, db, sessionCookie, token
+}
+
+func TestCsrfProtection(t *testing.T) {
+	app, db, session, token := newCsrfApp(t)
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		status  int
+	}{
+		{
+			name:    "no token",
+			headers: map[string]string{"Cookie": session + "; csrf=" + token},
+			status:  http.StatusForbidden,
+		},
+		{
+			name:    "token without cookie",
+			headers: map[string]string{"Cookie": session, csrfHeader: token},
+			status:  http.StatusForbidden,
+		},
+		{
+			name:    "forged token",
+			headers: map[string]string{"Cookie": session + "; csrf=forged", csrfHeader: "forged"},
+			status:  http.StatusForbidden,
+		},
+		{
+			name:    "non bearer authorization",
+			headers: map[string]string{"Cookie": session, "Authorization": "Basic YWxpY2U6cXdlcnR5"},
+			status:  http.StatusForbidden,
+		},
+		{
+			name:    "invalid bearer token",
+			headers: map[string]string{"Cookie": session, "Authorization": "Bearer wst_invalid"},
+			status:  http.StatusForbidden,
+		},
+		{
+			name:    "valid bearer token on a web route",
+			headers: map[string]string{"Authorization": "Bearer wst_valid"},
+			status:  http.StatusUnauthorized,
+		},
+		{
+			name:    "unknown extension origin",
+			headers: map[string]string{"Cookie": session, "Origin": "chrome-extension://other"},
+			status:  http.StatusForbidden,
+		},
+		{
+			name:    "valid token",
+			headers: map[string]string{"Cookie": session + "; csrf=" + token, csrfHeader: token},
+			status:  http.StatusOK,
+		},
+		{
+			name:    "trusted extension origin",
+			headers: map[string]string{"Cookie": session, "Origin": "chrome-extension://trusted"},
+			status:  http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db.user.Name = "alice"
+			form := url.Values{"name": {"mallory"}}
+			req := httptest.NewRequest(http.MethodPut, "/service/user", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			resp, err := app.web.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.status {
+				body, _ := io.ReadAll(resp.Body)
+				t.Fatalf("status = %d, want %d: %s", resp.StatusCode, tt.status, body)
+			}
+
+			changed := db.user.Name == "mallory"
+			if changed != (tt.status == http.StatusOK) {
+				t.Fatalf("user name changed = %v with status %d", changed, resp.StatusCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/famusovsky/WikiSurfBack/internal/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	maxCommentLength = 2000 // maxCommentLength - максимальная длина комментария в символах.
+	maxReasonLength  = 500  // maxReasonLength - максимальная длина причины жалобы в символах.
+	reportsToHide    = 3    // reportsToHide - количество жалоб, после которого комментарий скрывается до рассмотрения.
+)
+
+// commentNode - структура, описывающая комментарий вместе с ответами на него.
+type commentNode struct {
+	models.RouteComment
+	Own     bool          // Own - флаг комментария текущего пользователя.
+	Replies []commentNode // Replies - ответы на комментарий.
+}
+
+// buildCommentTree - функция, собирающая дерево обсуждения из комментариев в порядке написания.
+func buildCommentTree(comments []models.RouteComment, userId int) []commentNode {
+	children := make(map[int][]models.RouteComment)
+	var roots []models.RouteComment
+	for _, c := range comments {
+		if c.ParentId == nil {
+			roots = append(roots, c)
+		} else {
+			children[*c.ParentId] = append(children[*c.ParentId], c)
+		}
+	}
+
+	var build func(list []models.RouteComment) []commentNode
+	build = func(list []models.RouteComment) []commentNode {
+		nodes := make([]commentNode, 0, len(list))
+		for _, c := range list {
+			nodes = append(nodes, commentNode{RouteComment: c, Own: c.UserId == userId, Replies: build(children[c.Id])})
+		}
+		return nodes
+	}
+
+	return build(roots)
+}
+
+// getDiscussionRouteId - функция, получающая id маршрута обсуждения из параметров запроса.
+func (app *App) getDiscussionRouteId(c *fiber.Ctx) (int, error) {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return 0, err
+	}
+	if _, err := app.db.GetRoute(id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// renderComments - функция производящая рендер обсуждения маршрута.
+func (app *App) renderComments(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while getting route comments")
+	user, _ := app.getUser(c, wrapErr)
+
+	id, err := app.getDiscussionRouteId(c)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#comments")
+	}
+
+	comments, err := app.db.GetRouteComments(id)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#comments")
+	}
+
+	return c.Render("partials/comments", fiber.Map{
+		"ind":      id,
+		"count":    len(comments),
+		"comments": buildCommentTree(comments, user.Id),
+	})
+}
+
+// addComment - добавление комментария к маршруту или ответа на комментарий.
+func (app *App) addComment(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while adding the comment")
+	user, _ := app.getUser(c, wrapErr)
+
+	id, err := app.getDiscussionRouteId(c)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#commentResult")
+	}
+
+	comment := models.RouteComment{
+		RouteId:   id,
+		UserId:    user.Id,
+		Body:      strings.TrimSpace(c.FormValue("body")),
+		Spoiler:   c.FormValue("spoiler") != "",
+		CreatedAt: time.Now(),
+	}
+	if comment.Body == "" || utf8.RuneCountInString(comment.Body) > maxCommentLength {
+		return app.errToResult(c, errors.Join(wrapErr, fmt.Errorf("the comment must be 1 to %d characters long", maxCommentLength)), "#commentResult")
+	}
+	if v := c.FormValue("parent"); v != "" {
+		parentId, err := strconv.Atoi(v)
+		if err != nil {
+			return app.errToResult(c, errors.Join(wrapErr, err), "#commentResult")
+		}
+		parent, err := app.db.GetRouteComment(parentId)
+		if err != nil || parent.RouteId != id {
+			return app.errToResult(c, errors.Join(wrapErr, errors.New("the comment to reply to is not found"), err), "#commentResult")
+		}
+		comment.ParentId = &parent.Id
+	}
+
+	if _, err := app.db.AddRouteComment(comment); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#commentResult")
+	}
+
+	return app.renderComments(c)
+}
+
+// deleteComment - скрытие комментария его автором, ответы на него остаются в обсуждении.
+func (app *App) deleteComment(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while deleting the comment")
+	user, _ := app.getUser(c, wrapErr)
+
+	commentId, err := strconv.Atoi(c.Params("comment"))
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#commentResult")
+	}
+	comment, err := app.db.GetRouteComment(commentId)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#commentResult")
+	}
+	if comment.UserId != user.Id || strconv.Itoa(comment.RouteId) != c.Params("id") {
+		return app.errToResult(c, errors.Join(wrapErr, errors.New("only the author can delete the comment")), "#commentResult")
+	}
+
+	if err := app.db.HideRouteComment(comment.Id); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#commentResult")
+	}
+
+	return app.renderComments(c)
+}
+
+// renderVotes - функция производящая рендер оценки маршрута.
+func (app *App) renderVotes(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while getting route votes")
+	user, _ := app.getUser(c, wrapErr)
+
+	id, err := app.getDiscussionRouteId(c)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#votes")
+	}
+
+	votes, err := app.db.GetRouteVotes(id, user.Id)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#votes")
+	}
+
+	return c.Render("partials/votes", fiber.Map{
+		"ind":     id,
+		"average": fmt.Sprintf("%.1f", votes.Average),
+		"count":   votes.Count,
+		"own":     votes.Own,
+		"stars":   []int{1, 2, 3, 4, 5},
+	})
+}
+
+// voteRoute - оценка маршрута пользователем от 1 до 5 звёзд.
+func (app *App) voteRoute(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while voting for the route")
+	user, _ := app.getUser(c, wrapErr)
+
+	id, err := app.getDiscussionRouteId(c)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#votes")
+	}
+
+	stars, err := strconv.Atoi(c.FormValue("stars"))
+	if err != nil || stars < 1 || stars > 5 {
+		return app.errToResult(c, errors.Join(wrapErr, errors.New("the rating must be from 1 to 5 stars")), "#votes")
+	}
+
+	if err := app.db.SetRouteVote(id, user.Id, stars); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#votes")
+	}
+
+	return app.renderVotes(c)
+}
+
+// reportRoute - жалоба на маршрут или комментарий к нему для модерации.
+func (app *App) reportRoute(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while sending the report")
+	user, _ := app.getUser(c, wrapErr)
+
+	id, err := app.getDiscussionRouteId(c)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#reportResult")
+	}
+
+	reason := strings.TrimSpace(c.FormValue("reason", c.Get("HX-Prompt")))
+	if reason == "" || utf8.RuneCountInString(reason) > maxReasonLength {
+		return app.errToResult(c, errors.Join(wrapErr, fmt.Errorf("the reason must be 1 to %d characters long", maxReasonLength)), "#reportResult")
+	}
+
+	report := models.RouteReport{
+		RouteId:   id,
+		UserId:    user.Id,
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	}
+	if v := c.FormValue("comment"); v != "" {
+		commentId, err := strconv.Atoi(v)
+		if err != nil {
+			return app.errToResult(c, errors.Join(wrapErr, err), "#reportResult")
+		}
+		comment, err := app.db.GetRouteComment(commentId)
+		if err != nil || comment.RouteId != id {
+			return app.errToResult(c, errors.Join(wrapErr, errors.New("the reported comment is not found"), err), "#reportResult")
+		}
+		report.CommentId = &comment.Id
+	}
+
+	hidden, err := app.db.AddRouteReport(report, reportsToHide)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#reportResult")
+	}
+	if hidden {
+		app.infoLog.Printf("comment #%d hidden after %d reports\n", *report.CommentId, reportsToHide)
+	}
+
+	return c.SendString("Thank you, the report was sent to the moderators")
+}
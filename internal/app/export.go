@@ -0,0 +1,224 @@
+package app
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/famusovsky/WikiSurfBack/internal/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	exportFlushRows   = 100                                     // exportFlushRows - количество строк выгрузки, после которого данные отправляются клиенту.
+	exportInterrupted = "the export was interrupted, try again" // exportInterrupted - ошибка в конце прерванной выгрузки.
+)
+
+// exportContentTypes - типы содержимого выгрузки по форматам.
+var exportContentTypes = map[string]string{
+	"csv":    "text/csv; charset=utf-8",
+	"json":   fiber.MIMEApplicationJSONCharsetUTF8,
+	"ndjson": "application/x-ndjson",
+}
+
+// sprintExportHeader - заголовок CSV выгрузки спринтов.
+var sprintExportHeader = []string{"sprint_id", "user_id", "user_name", "route_id", "start", "finish", "start_time", "length_time", "steps", "success"}
+
+// sprintExportRecord - функция, переводящая спринт выгрузки в строку CSV.
+func sprintExportRecord(s models.ExportSprint) []string {
+	return []string{
+		strconv.Itoa(s.SprintId),
+		strconv.Itoa(s.UserId),
+		s.UserName,
+		strconv.Itoa(s.RouteId),
+		s.Start,
+		s.Finish,
+		s.StartTime.Format(time.RFC3339),
+		strconv.FormatInt(s.LengthTime, 10),
+		strconv.Itoa(s.Steps),
+		strconv.FormatBool(s.Success),
+	}
+}
+
+// routeExportRow - структура, описывающая строку выгрузки рейтинга маршрута.
+type routeExportRow struct {
+	Place int `json:"place"` // Place - место в рейтинге.
+	models.ExportRouteRating
+}
+
+// routeExportHeader - заголовок CSV выгрузки рейтинга маршрута.
+var routeExportHeader = []string{"place", "user_id", "user_name", "sprint_id", "length_time", "steps"}
+
+// routeExportRecord - функция, переводящая строку рейтинга маршрута в строку CSV.
+func routeExportRecord(r routeExportRow) []string {
+	return []string{
+		strconv.Itoa(r.Place),
+		strconv.Itoa(r.UserId),
+		r.UserName,
+		strconv.Itoa(r.SprintId),
+		strconv.FormatInt(r.LengthTime, 10),
+		strconv.Itoa(r.Steps),
+	}
+}
+
+// getExportFormat - функция, получающая формат выгрузки из запроса, по умолчанию CSV.
+func getExportFormat(c *fiber.Ctx) (string, error) {
+	format := c.Query("format", "csv")
+	if _, ok := exportContentTypes[format]; !ok {
+		return "", fmt.Errorf("unknown export format %q, use csv, json or ndjson", format)
+	}
+
+	return format, nil
+}
+
+// streamExport - функция, построчно передающая выгрузку клиенту без накопления в памяти.
+//
+// Принимает: контекст, имя файла без расширения, формат, заголовок CSV, перевод строки в CSV,
+// функцию, вызывающую переданный обработчик для каждой строки выгрузки.
+func streamExport[T any](app *App, c *fiber.Ctx, name, format string, header []string, record func(T) []string, each func(fn func(T) error) error) error {
+	c.Set(fiber.HeaderContentType, exportContentTypes[format])
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s.%s"`, name, format))
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		var (
+			cw    = csv.NewWriter(w)
+			enc   = json.NewEncoder(w)
+			count = 0
+		)
+
+		switch format {
+		case "csv":
+			cw.Write(header)
+		case "json":
+			w.WriteString("[")
+		}
+
+		err := each(func(row T) error {
+			switch format {
+			case "csv":
+				cw.Write(record(row))
+			case "json":
+				if count != 0 {
+					w.WriteString(",")
+				}
+				fallthrough
+			case "ndjson":
+				if err := enc.Encode(row); err != nil {
+					return err
+				}
+			}
+
+			count++
+			if count%exportFlushRows == 0 {
+				cw.Flush()
+				return w.Flush()
+			}
+			return nil
+		})
+		if err != nil {
+			app.errLog.Println(errors.Join(fmt.Errorf("error while streaming the %s export", name), err))
+			// Прерванная выгрузка не должна выглядеть полной: JSON остаётся незакрытым,
+			// а в CSV и NDJSON последней строкой пишется ошибка.
+			switch format {
+			case "csv":
+				cw.Write([]string{"error", exportInterrupted})
+			case "ndjson":
+				enc.Encode(fiber.Map{"error": exportInterrupted})
+			}
+			cw.Flush()
+			w.Flush()
+			return
+		}
+
+		cw.Flush()
+		if format == "json" {
+			w.WriteString("]")
+		}
+		w.Flush()
+	})
+
+	return nil
+}
+
+// exportHistory - выгрузка истории спринтов текущего пользователя.
+func (app *App) exportHistory(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while exporting the history")
+	user, _ := app.getUser(c, wrapErr)
+
+	format, err := getExportFormat(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(errors.Join(wrapErr, err).Error())
+	}
+
+	return streamExport(app, c, "history", format, sprintExportHeader, sprintExportRecord,
+		func(fn func(models.ExportSprint) error) error {
+			return app.db.EachUserSprint(user.Id, fn)
+		})
+}
+
+// exportRouteRating - выгрузка рейтинга маршрута, поддерживает тот же выбор периода, что и страница маршрута.
+func (app *App) exportRouteRating(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while exporting the route rating")
+
+	id, err := strconv.Atoi(c.Params("route"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(errors.Join(wrapErr, err).Error())
+	}
+	format, err := getExportFormat(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(errors.Join(wrapErr, err).Error())
+	}
+	period, err := app.getRatingPeriod(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(errors.Join(wrapErr, err).Error())
+	}
+
+	if _, err := app.db.GetRoute(id); err != nil {
+		return c.Status(fiber.StatusNotFound).SendString(errors.Join(wrapErr, err).Error())
+	}
+	var start, end *time.Time
+	if period.set {
+		start, end = &period.start, &period.end
+	}
+
+	return streamExport(app, c, fmt.Sprintf("route-%d", id), format, routeExportHeader, routeExportRecord,
+		func(fn func(routeExportRow) error) error {
+			place := 0
+			return app.db.EachRouteRating(id, start, end, func(r models.ExportRouteRating) error {
+				place++
+				return fn(routeExportRow{Place: place, ExportRouteRating: r})
+			})
+		})
+}
+
+// exportTourSprints - выгрузка всех спринтов участников соревнования на его маршрутах во время соревнования.
+func (app *App) exportTourSprints(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while exporting the tournament results")
+	user, _ := app.getUser(c, wrapErr)
+
+	id, err := strconv.Atoi(c.Params("tour"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(errors.Join(wrapErr, err).Error())
+	}
+	format, err := getExportFormat(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(errors.Join(wrapErr, err).Error())
+	}
+
+	tour, err := app.db.GetTournament(id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).SendString(errors.Join(wrapErr, err).Error())
+	}
+	if !app.canSeeTour(tour, user.Id) {
+		return c.Status(fiber.StatusForbidden).SendString(errors.Join(wrapErr, errors.New("the tournament is private")).Error())
+	}
+
+	return streamExport(app, c, fmt.Sprintf("tournament-%d", id), format, sprintExportHeader, sprintExportRecord,
+		func(fn func(models.ExportSprint) error) error {
+			return app.db.EachTourSprint(id, fn)
+		})
+}
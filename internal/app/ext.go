@@ -13,7 +13,7 @@ import (
 // renderStartExt - функция, производящая рендер страницы запуска спринта в расширении.
 func (app *App) renderStartExt(c *fiber.Ctx) error {
 	return c.Render("ext/start", fiber.Map{
-		"baseUrl": c.BaseURL(),
+		"baseUrl": app.baseUrl,
 	})
 }
 
@@ -26,25 +26,41 @@ func (app *App) startRouteExt(c *fiber.Ctx) error {
 		return app.errToResult(c, errors.Join(wrapErr, err))
 	}
 
-	return c.Render("ext/startRoute", fiber.Map{
-		"rid":     route.Id,
-		"start":   route.Start,
-		"finish":  route.Finish,
-		"baseUrl": c.BaseURL(),
-	})
+	res := fiber.Map{
+		"rid":      route.Id,
+		"start":    route.Start,
+		"finish":   route.Finish,
+		"baseUrl":  app.baseUrl,
+		"ghostId":  0,
+		"ghostUrl": "",
+	}
+
+	if kind := c.FormValue("ghost"); kind != "" {
+		user, _ := app.getUser(c, wrapErr)
+		ghost, err := app.getGhost(route.Id, user.Id, kind, c.FormValue("ghost_user"))
+		if err != nil {
+			return app.errToResult(c, errors.Join(wrapErr, err))
+		}
+		res["ghostId"] = ghost.SprintId
+		res["ghostName"] = ghost.UserName
+		res["ghostUrl"] = ghostUrl(app.baseUrl, route.Id, kind, c.FormValue("ghost_user"))
+	}
+
+	return c.Render("ext/startRoute", res)
 }
 
 // authExt - функция, проводящая авторизацию в расширении.
 func (app *App) authExt(c *fiber.Ctx) error {
 	return c.Render("ext/auth", fiber.Map{
-		"baseUrl": c.BaseURL(),
+		"baseUrl":   app.baseUrl,
+		"providers": app.getProvidersView(),
 	})
 }
 
 // renderMainExt - функция, производящая рендер главной страницы расширения.
 func (app *App) renderMainExt(c *fiber.Ctx) error {
 	return c.Render("ext/main", fiber.Map{
-		"baseUrl": c.BaseURL(),
+		"baseUrl": app.baseUrl,
 	})
 }
 
@@ -61,7 +77,7 @@ func (app *App) renderToursExt(c *fiber.Ctx) error {
 	var b bytes.Buffer
 	q := fmt.Sprintf(
 		`{{range .}}<tr><td><a href={{printf "%s/tournament/%%d" .Id }} target="_blank">#{{.Id}}</a></td></tr>{{end}}`,
-		c.BaseURL())
+		app.baseUrl)
 
 	temp := template.Must(template.New("").Parse(q))
 	if err := temp.Execute(&b, tours); err != nil {
@@ -71,7 +87,7 @@ func (app *App) renderToursExt(c *fiber.Ctx) error {
 	return c.Render("partials/tourList", fiber.Map{
 		"name":    "Tours in which I participate",
 		"tbody":   b.String(),
-		"baseUrl": c.BaseURL(),
+		"baseUrl": app.baseUrl,
 	})
 }
 
@@ -87,7 +103,7 @@ func (app *App) renderRoutesExt(c *fiber.Ctx) error {
 	var b bytes.Buffer
 	q := fmt.Sprintf(`{{range .}}<tr>
 	<td><a href={{printf "%s/route/%%d" .Id }} target="_blank">#{{.Id}}</a></td><td>{{.Start}}</td><td>{{.Finish}}</td>
-	</tr>{{end}}`, c.BaseURL())
+	</tr>{{end}}`, app.baseUrl)
 
 	temp := template.Must(template.New("").Parse(q))
 	if err := temp.Execute(&b, routes); err != nil {
@@ -96,7 +112,7 @@ func (app *App) renderRoutesExt(c *fiber.Ctx) error {
 
 	return c.Render("partials/tourList", fiber.Map{
 		"tbody":   b.String(),
-		"baseUrl": c.BaseURL(),
+		"baseUrl": app.baseUrl,
 	})
 }
 
@@ -109,11 +125,23 @@ func (app *App) addSprintExt(c *fiber.Ctx) error {
 	if err := c.BodyParser(&sprint); err != nil {
 		return app.errToResult(c, errors.Join(wrapErr, err))
 	}
+	if err := checkSprintSteps(sprint); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err))
+	}
 	sprint.UserId = user.Id
 	id, err := app.db.AddSprint(sprint)
 	if err != nil {
 		return app.errToResult(c, errors.Join(wrapErr, err))
 	}
+	app.races.Finish(user.Id, sprint.RouteId, id, sprint.LengthTime, sprint.Success)
+	sprint.Id = id
+	if ghostId := getGhostSprintId(c); ghostId != 0 {
+		if _, err := app.recordGhostResult(sprint, ghostId); err != nil {
+			app.errLog.Println(errors.Join(errors.New("error while recording the ghost result"), err))
+		}
+	}
+	app.updateSkill(sprint)
+	go app.awardAchievements(user.Id)
 
-	return c.SendString(fmt.Sprintf("%s/sprint/%d", c.BaseURL(), id))
+	return c.SendString(fmt.Sprintf("%s/sprint/%d", app.baseUrl, id))
 }
@@ -0,0 +1,120 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// feedSize - количество спринтов в ленте подписок на главной странице.
+const feedSize = 20
+
+// getFriendsCircle - функция, возвращающая id пользователя и игроков, на которых он подписан.
+func (app *App) getFriendsCircle(userId int) ([]int, error) {
+	ids, err := app.db.GetFollowingIds(userId)
+	if err != nil {
+		return []int{}, err
+	}
+
+	return append(ids, userId), nil
+}
+
+// follow - подписка текущего пользователя на игрока.
+func (app *App) follow(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while following the player")
+	user, _ := app.getUser(c, wrapErr)
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#follow")
+	}
+	if id == user.Id {
+		return app.errToResult(c, errors.Join(wrapErr, errors.New("you can not follow yourself")), "#follow")
+	}
+
+	followee, err := app.db.GetUserById(id)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#follow")
+	}
+	if followee.Hidden {
+		return app.errToResult(c, errors.Join(wrapErr, errors.New("this profile is private")), "#follow")
+	}
+
+	if err := app.db.AddFollow(user.Id, id); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#follow")
+	}
+
+	return c.Render("partials/follow", fiber.Map{
+		"id":        id,
+		"following": true,
+	})
+}
+
+// unfollow - отмена подписки текущего пользователя на игрока.
+func (app *App) unfollow(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while unfollowing the player")
+	user, _ := app.getUser(c, wrapErr)
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#follow")
+	}
+
+	if err := app.db.DeleteFollow(user.Id, id); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#follow")
+	}
+
+	return c.Render("partials/follow", fiber.Map{
+		"id":        id,
+		"following": false,
+	})
+}
+
+// renderFeed - рендер ленты последних спринтов игроков, на которых подписан пользователь.
+func (app *App) renderFeed(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while getting the feed")
+	user, _ := app.getUser(c, wrapErr)
+
+	feed, err := app.db.GetFollowFeed(user.Id, feedSize)
+	if err != nil {
+		return app.renderErr(c, fiber.StatusInternalServerError, errors.Join(wrapErr, err))
+	}
+
+	type feedRow struct {
+		Id, UserId            int
+		UserName, Route       string
+		Success               bool
+		Length, Steps, Played string
+	}
+	rows := make([]feedRow, len(feed))
+	for i, s := range feed {
+		rows[i] = feedRow{
+			Id:       s.SprintId,
+			UserId:   s.UserId,
+			UserName: s.UserName,
+			Route:    s.Start + " → " + s.Finish,
+			Success:  s.Success,
+			Length:   formatLength(s.LengthTime),
+			Steps:    strconv.Itoa(s.Steps),
+			Played:   s.StartTime.Format("2006 Jan 2 15:04"),
+		}
+	}
+
+	var b bytes.Buffer
+	q := `{{range .}}<tr hx-get={{printf "/sprint/%d" .Id }} hx-target="body">
+	<td><a href={{printf "/user/%d" .UserId }} onclick="event.stopPropagation()">{{.UserName}}</a></td>
+	<td>{{.Route}}</td>
+	<td>{{if .Success}}{{.Length}}{{else}}gave up{{end}}</td>
+	<td>{{.Steps}}</td>
+	<td>{{.Played}}</td>
+	</tr>{{else}}<tr><td colspan="5">Follow players on their profiles to see their sprints here.</td></tr>{{end}}`
+	t := template.Must(template.New("").Parse(q))
+	if err := t.Execute(&b, rows); err != nil {
+		return app.renderErr(c, fiber.StatusInternalServerError, errors.Join(wrapErr, err))
+	}
+
+	return c.SendString(b.String())
+}
@@ -0,0 +1,127 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/famusovsky/WikiSurfBack/internal/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// getGhost - функция, выбирающая призрака на маршруте для пользователя.
+//
+// Принимает: id маршрута, id пользователя, вид призрака и id игрока для вида user.
+func (app *App) getGhost(routeId, userId int, kind, other string) (models.Ghost, error) {
+	var (
+		sprint models.Sprint
+		err    error
+	)
+
+	switch kind {
+	case models.GhostPersonalBest:
+		if sprint, err = app.db.GetUserBestSprint(userId, routeId); err != nil {
+			return models.Ghost{}, errors.New("you have no successful sprint on this route yet")
+		}
+	case models.GhostRecord:
+		if sprint, err = app.db.GetRouteRecordSprint(routeId); err != nil {
+			return models.Ghost{}, errors.New("nobody has finished this route yet")
+		}
+	case models.GhostUser:
+		id, err := strconv.Atoi(other)
+		if err != nil {
+			return models.Ghost{}, errors.New("choose the player to race against by id")
+		}
+		opponent, err := app.db.GetUserById(id)
+		if err != nil || (opponent.Hidden && opponent.Id != userId) {
+			return models.Ghost{}, errors.New("this player can not be raced against")
+		}
+		if sprint, err = app.db.GetUserBestSprint(id, routeId); err != nil {
+			return models.Ghost{}, errors.New("this player has no successful sprint on this route")
+		}
+	default:
+		return models.Ghost{}, fmt.Errorf("unknown ghost kind %q", kind)
+	}
+
+	name := fmt.Sprintf("User with id:%d", sprint.UserId)
+	if user, err := app.db.GetUserById(sprint.UserId); err == nil {
+		name = user.Name
+	}
+
+	return models.Ghost{
+		Kind:       kind,
+		SprintId:   sprint.Id,
+		UserId:     sprint.UserId,
+		UserName:   name,
+		LengthTime: sprint.LengthTime,
+		Path:       sprint.Path,
+		StepTimes:  sprint.StepTimes,
+		BackSteps:  sprint.BackSteps,
+	}, nil
+}
+
+// getGhostJson - получение призрака на маршруте: ?vs=pb, ?vs=record или ?vs=user&user=<id>.
+func (app *App) getGhostJson(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while getting the ghost")
+	user, _ := app.getUser(c, wrapErr)
+
+	routeId, err := strconv.Atoi(c.Params("route"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": errors.Join(wrapErr, err).Error()})
+	}
+
+	ghost, err := app.getGhost(routeId, user.Id, c.Query("vs", models.GhostRecord), c.Query("user"))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": errors.Join(wrapErr, err).Error()})
+	}
+
+	return c.JSON(ghost)
+}
+
+// ghostUrl - функция, возвращающая адрес, по которому расширение получает призрака.
+func ghostUrl(baseUrl string, routeId int, kind, other string) string {
+	q := url.Values{"vs": {kind}}
+	if kind == models.GhostUser {
+		q.Set("user", other)
+	}
+
+	return fmt.Sprintf("%s/ext/ghost/%d?%s", baseUrl, routeId, q.Encode())
+}
+
+// getGhostSprintId - функция, получающая из присланного спринта id спринта призрака, 0 - если гонки с призраком не было.
+func getGhostSprintId(c *fiber.Ctx) int {
+	form := struct {
+		GhostSprintId int `json:"ghost_sprint_id" form:"ghost_sprint_id"`
+	}{}
+	if err := c.BodyParser(&form); err != nil {
+		return 0
+	}
+
+	return form.GhostSprintId
+}
+
+// recordGhostResult - функция, записывающая, обогнал ли спринт призрака.
+func (app *App) recordGhostResult(sprint models.Sprint, ghostSprintId int) (models.GhostResult, error) {
+	ghost, err := app.db.GetSprint(ghostSprintId)
+	if err != nil {
+		return models.GhostResult{}, err
+	}
+	if ghost.RouteId != sprint.RouteId || ghost.Id == sprint.Id {
+		return models.GhostResult{}, errors.New("the ghost must be another sprint on the same route")
+	}
+
+	result := models.GhostResult{
+		SprintId:      sprint.Id,
+		GhostSprintId: ghost.Id,
+		Beaten:        sprint.Success && sprint.LengthTime < ghost.LengthTime,
+		Margin:        ghost.LengthTime - sprint.LengthTime,
+		CreatedAt:     time.Now(),
+	}
+	if err := app.db.AddGhostResult(result); err != nil {
+		return models.GhostResult{}, err
+	}
+
+	return result, nil
+}
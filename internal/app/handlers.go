@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"html/template"
 	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/famusovsky/WikiSurfBack/internal/models"
@@ -14,7 +15,9 @@ import (
 
 // auth - функция, производящая авторизацию пользователя / рендер страницы аутентификации.
 func (app *App) auth(c *fiber.Ctx) error {
-	return c.Render("auth/auth", fiber.Map{}, "layouts/mini")
+	return c.Render("auth/auth", fiber.Map{
+		"providers": app.getProvidersView(),
+	}, "layouts/mini")
 }
 
 // renderSignin - функция производящая рендер страницы входа.
@@ -31,6 +34,7 @@ func (app *App) renderSignup(c *fiber.Ctx) error {
 func (app *App) renderMain(c *fiber.Ctx) error {
 	return c.Render("main", fiber.Map{
 		"ratingType": "/service/rating",
+		"seasons":    app.getSeasonsView(),
 	}, "layouts/base")
 }
 
@@ -79,8 +83,12 @@ func (app *App) renderHistory(c *fiber.Ctx) error {
 func (app *App) renderSettings(c *fiber.Ctx) error {
 	usr, _ := app.getUser(c, errors.New(""))
 	return c.Render("settings", fiber.Map{
-		"email": usr.Email,
-		"name":  usr.Name,
+		"email":       usr.Email,
+		"name":        usr.Name,
+		"verified":    usr.Verified,
+		"hidden":      usr.Hidden,
+		"hasPassword": usr.Password != "",
+		"notice":      c.Locals("notice"),
 	}, "layouts/base")
 }
 
@@ -145,13 +153,24 @@ func (app *App) renderSprint(c *fiber.Ctx) error {
 		}
 	}
 
-	return c.Render("sprint", fiber.Map{
+	res := fiber.Map{
 		"ind":        strconv.Itoa(sprint.Id),
 		"infoTbody":  infoTbody.String(),
 		"place":      strconv.Itoa(place),
 		"routeId":    sprint.RouteId,
 		"stepsTbody": stepsTbody.String(),
-	}, "layouts/base")
+	}
+	if ghost, err := app.db.GetGhostResult(sprint.Id); err == nil {
+		margin := ghost.Margin
+		if margin < 0 {
+			margin = -margin
+		}
+		res["ghostId"] = ghost.GhostSprintId
+		res["ghostBeaten"] = ghost.Beaten
+		res["ghostMargin"] = formatLength(margin)
+	}
+
+	return c.Render("sprint", res, "layouts/base")
 }
 
 // renderRoute - функция производящая рендер страницы маршрута.
@@ -188,6 +207,7 @@ func (app *App) renderRoute(c *fiber.Ctx) error {
 		"finish":     route.Finish,
 		"link":       route.Start,
 		"ratingType": fmt.Sprintf("/service/rating/route/%s", c.Params("id")),
+		"seasons":    app.getSeasonsView(),
 	}, "layouts/base")
 }
 
@@ -254,6 +274,49 @@ func (app *App) renderCreatorTournaments(c *fiber.Ctx) error {
 	})
 }
 
+// renderPastTournaments - функция производящая рендер страницы прошедших соревнований.
+func (app *App) renderPastTournaments(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while getting past tours")
+	user, _ := app.getUser(c, wrapErr)
+
+	tours, err := app.db.GetArchivedTournaments(user.Id)
+	if err != nil {
+		return app.renderErr(c, fiber.StatusNotFound, errors.Join(wrapErr, err))
+	}
+
+	res, err := getToursTable(tours)
+	if err != nil {
+		return app.renderErr(c, fiber.StatusNotFound, wrapErr)
+	}
+	return c.Render("partials/tourList", fiber.Map{
+		"name":  "Past tours",
+		"tbody": res,
+	})
+}
+
+// renderUserPlacements - функция производящая рендер списка итоговых мест пользователя в соревнованиях.
+func (app *App) renderUserPlacements(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while getting user placements")
+	user, _ := app.getUser(c, wrapErr)
+
+	placements, err := app.db.GetUserPlacements(user.Id)
+	if err != nil {
+		return app.renderErr(c, fiber.StatusNotFound, errors.Join(wrapErr, err))
+	}
+
+	var b bytes.Buffer
+	q := `{{range .}}<tr><td hx-get={{printf "/tournament/%d" .TournamentId }} hx-target="body">#{{.TournamentId}}: place {{.Place}} ({{.Points}} points)</td></tr>{{end}}`
+	t := template.Must(template.New("").Parse(q))
+	if err := t.Execute(&b, placements); err != nil {
+		return app.renderErr(c, fiber.StatusInternalServerError, errors.Join(wrapErr, err))
+	}
+
+	return c.Render("partials/tourList", fiber.Map{
+		"name":  "My placements",
+		"tbody": b.String(),
+	})
+}
+
 // renderCreateTour - функция производящая рендер страницы соревнования.
 func (app *App) renderTournament(c *fiber.Ctx) error {
 	wrapErr := errors.New("error while getting tour")
@@ -266,14 +329,18 @@ func (app *App) renderTournament(c *fiber.Ctx) error {
 
 	var (
 		body         bytes.Buffer
+		winnersBody  bytes.Buffer
 		tour         models.Tournament
 		participates bool
 		isCreator    bool
+		archived     bool
+		invited      bool
+		requested    bool
 	)
 
 	wg := sync.WaitGroup{}
-	errs := make([]error, 4)
-	wg.Add(4)
+	errs := make([]error, 8)
+	wg.Add(8)
 
 	go func(t *models.Tournament, wg *sync.WaitGroup, e []error) {
 		if tmp, err := app.db.GetTournament(id); err == nil {
@@ -322,6 +389,54 @@ func (app *App) renderTournament(c *fiber.Ctx) error {
 		wg.Done()
 	}(&isCreator, &wg, errs)
 
+	go func(b *bool, wg *sync.WaitGroup, e []error) {
+		if archived, err := app.db.CheckTournamentArchived(id); err == nil {
+			*b = archived
+		} else {
+			e[4] = err
+		}
+		wg.Done()
+	}(&archived, &wg, errs)
+
+	go func(b *bytes.Buffer, wg *sync.WaitGroup, e []error) {
+		winners, err := app.db.GetTournamentWinners(id)
+		if err != nil {
+			e[5] = err
+			wg.Done()
+			return
+		}
+		q := `{{range .}}<tr hx-get={{printf "/sprint/%d" .SprintId }} hx-target="body">
+	<td>{{.RouteId}}</td>
+	<td>{{.Start}}</td>
+	<td>{{.Finish}}</td>
+	<td>{{.UserName}}</td>
+	<td>{{.SprintId}}</td>
+	</tr>{{end}}`
+		t := template.Must(template.New("").Parse(q))
+		if err := t.Execute(b, winners); err != nil {
+			e[5] = err
+		}
+		wg.Done()
+	}(&winnersBody, &wg, errs)
+
+	go func(b *bool, wg *sync.WaitGroup, e []error) {
+		if invited, err := app.db.CheckTourInvite(id, user.Id); err == nil {
+			*b = invited
+		} else {
+			e[6] = err
+		}
+		wg.Done()
+	}(&invited, &wg, errs)
+
+	go func(b *bool, wg *sync.WaitGroup, e []error) {
+		if requested, err := app.db.CheckTourJoinRequest(id, user.Id); err == nil {
+			*b = requested
+		} else {
+			e[7] = err
+		}
+		wg.Done()
+	}(&requested, &wg, errs)
+
 	wg.Wait()
 
 	for _, err := range errs {
@@ -331,26 +446,135 @@ func (app *App) renderTournament(c *fiber.Ctx) error {
 	}
 
 	return c.Render("tournament", fiber.Map{
+		"archived":     archived,
+		"winnersTbody": winnersBody.String(),
 		"ind":          c.Params("id"),
-		"password":     tour.Pswd,
 		"routesTbody":  body.String(),
 		"participates": participates,
 		"isCreator":    isCreator,
+		"private":      tour.Private,
+		"invited":      invited,
+		"requested":    requested,
+		"maxUsers":     tour.MaxUsers,
 		"ratingType":   "/service/rating/tour/" + c.Params("id"),
 		"start":        tour.StartTime.Format("2006 Jan 2 15:04"),
 		"end":          tour.EndTime.Format("2006 Jan 2 15:04"),
 	}, "layouts/base")
 }
 
+// renderTeams - функция производящая рендер блока команд соревнования.
+func (app *App) renderTeams(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while getting tour teams")
+	user, _ := app.getUser(c, wrapErr)
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return app.renderErr(c, fiber.StatusBadRequest, errors.Join(wrapErr, err), "")
+	}
+
+	teams, err := app.db.GetTournamentTeams(id)
+	if err != nil {
+		return app.renderErr(c, fiber.StatusNotFound, errors.Join(wrapErr, err), "")
+	}
+
+	type teamData struct {
+		Name    string
+		Captain string
+		Members string
+	}
+	data := make([]teamData, len(teams))
+	var own *models.Team
+	for i, t := range teams {
+		members, err := app.db.GetTeamMembers(t.Id)
+		if err != nil {
+			return app.renderErr(c, fiber.StatusInternalServerError, errors.Join(wrapErr, err), "")
+		}
+
+		names := make([]string, 0, len(members))
+		for _, m := range members {
+			names = append(names, m.Name)
+			if m.Id == t.CaptainId {
+				data[i].Captain = m.Name
+			}
+			if m.Id == user.Id {
+				own = &teams[i]
+			}
+		}
+		data[i].Name = t.Name
+		data[i].Members = strings.Join(names, ", ")
+	}
+
+	var teamsBody, invitesBody bytes.Buffer
+	q := `{{range .}}<tr><td>{{.Name}}</td><td>{{.Captain}}</td><td>{{.Members}}</td></tr>{{end}}`
+	t := template.Must(template.New("").Parse(q))
+	if err := t.Execute(&teamsBody, data); err != nil {
+		return app.renderErr(c, fiber.StatusInternalServerError, errors.Join(wrapErr, err), "")
+	}
+
+	if own == nil {
+		invites, err := app.db.GetUserTeamInvites(id, user.Id)
+		if err != nil {
+			return app.renderErr(c, fiber.StatusInternalServerError, errors.Join(wrapErr, err), "")
+		}
+
+		q := `{{range .}}<tr><td>{{.Name}}</td><td>
+		<button hx-post={{printf "/service/tour/%d/team/%d/join" .TournamentId .Id }} hx-target="#teams">Join</button>
+		</td></tr>{{end}}`
+		t := template.Must(template.New("").Parse(q))
+		if err := t.Execute(&invitesBody, invites); err != nil {
+			return app.renderErr(c, fiber.StatusInternalServerError, errors.Join(wrapErr, err), "")
+		}
+	}
+
+	participates, err := app.db.CheckTournamentParticipator(id, user.Id)
+	if err != nil {
+		return app.renderErr(c, fiber.StatusInternalServerError, errors.Join(wrapErr, err), "")
+	}
+
+	res := fiber.Map{
+		"ind":          c.Params("id"),
+		"teamsTbody":   teamsBody.String(),
+		"invitesTbody": invitesBody.String(),
+		"participates": participates,
+		"hasTeam":      own != nil,
+	}
+	if own != nil {
+		res["teamName"] = own.Name
+		res["isCaptain"] = own.CaptainId == user.Id
+	}
+
+	return c.Render("partials/teams", res)
+}
+
+// renderLeaderboard - функция производящая рендер индивидуального или командного рейтинга соревнования.
+func (app *App) renderLeaderboard(c *fiber.Ctx) error {
+	if c.Params("kind") == "teams" {
+		return c.Render("partials/teamRating", fiber.Map{
+			"ratingType": fmt.Sprintf("/service/rating/tour/%s/teams", c.Params("id")),
+		})
+	}
+
+	return c.Render("partials/rating", fiber.Map{
+		"ratingType": fmt.Sprintf("/service/rating/tour/%s", c.Params("id")),
+	})
+}
+
 // renderCreateTour - функция производящая рендер страницы создания соревнования.
 func (app *App) renderEditTour(c *fiber.Ctx) error {
-	wrapErr := errors.New("error while rendering tour editor")
-
 	id, err := strconv.Atoi(c.Params("id"))
 	if err != nil {
-		return app.renderErr(c, fiber.StatusBadRequest, errors.Join(wrapErr, err))
+		return app.renderErr(c, fiber.StatusBadRequest, errors.Join(errors.New("error while rendering tour editor"), err))
 	}
 
+	return app.renderTourEditor(c, id, "")
+}
+
+// renderTourEditor - функция производящая рендер страницы редактирования соревнования,
+// joinCode - только что созданный код-пароль, который показывается один раз.
+func (app *App) renderTourEditor(c *fiber.Ctx, id int, joinCode string) error {
+	wrapErr := errors.New("error while rendering tour editor")
+	ind := strconv.Itoa(id)
+
 	user, _ := app.getUser(c, wrapErr)
 	ok, err := app.db.CheckTournamentCreator(id, user.Id)
 	if !ok || err != nil {
@@ -362,10 +586,10 @@ func (app *App) renderEditTour(c *fiber.Ctx) error {
 		return app.renderErr(c, fiber.StatusNotFound, errors.Join(wrapErr, err))
 	}
 
-	var creatorsTbody, routesTbody bytes.Buffer
+	var creatorsTbody, routesTbody, participantsTbody, requestsTbody, bansTbody bytes.Buffer
 	wg := sync.WaitGroup{}
-	errs := make([]error, 2)
-	wg.Add(2)
+	errs := make([]error, 5)
+	wg.Add(5)
 
 	go func(b *bytes.Buffer, wg *sync.WaitGroup, e []error) {
 		q := `{{range .}}<tr><td>{{.Name}}</td><td>{{.Email}}</td></tr>{{end}}`
@@ -395,6 +619,56 @@ func (app *App) renderEditTour(c *fiber.Ctx) error {
 		wg.Done()
 	}(&routesTbody, &wg, errs)
 
+	go func(b *bytes.Buffer, wg *sync.WaitGroup, e []error) {
+		q := `{{range .users}}<tr><td>{{.Name}}</td><td>{{.Email}}</td><td>
+	<button hx-delete={{printf "/service/tour/%s/participant/%d" $.ind .Id }} hx-confirm="Are you sure?" hx-target="body">Kick</button>
+	<button hx-delete={{printf "/service/tour/%s/participant/%d?ban=true" $.ind .Id }} hx-confirm="Are you sure?" hx-target="body">Ban</button>
+	</td></tr>{{end}}`
+
+		if users, err := app.db.GetTournamentParticipants(id); err == nil {
+			t := template.Must(template.New("").Parse(q))
+			if err := t.Execute(b, fiber.Map{"ind": ind, "users": users}); err != nil {
+				e[2] = err
+			}
+		} else {
+			e[2] = err
+		}
+		wg.Done()
+	}(&participantsTbody, &wg, errs)
+
+	go func(b *bytes.Buffer, wg *sync.WaitGroup, e []error) {
+		q := `{{range .users}}<tr><td>{{.Name}}</td><td>{{.Email}}</td><td>
+	<button hx-post={{printf "/service/tour/%s/request/%d" $.ind .Id }} hx-target="body">Approve</button>
+	<button hx-delete={{printf "/service/tour/%s/request/%d" $.ind .Id }} hx-target="body">Reject</button>
+	</td></tr>{{end}}`
+
+		if users, err := app.db.GetTourJoinRequests(id); err == nil {
+			t := template.Must(template.New("").Parse(q))
+			if err := t.Execute(b, fiber.Map{"ind": ind, "users": users}); err != nil {
+				e[3] = err
+			}
+		} else {
+			e[3] = err
+		}
+		wg.Done()
+	}(&requestsTbody, &wg, errs)
+
+	go func(b *bytes.Buffer, wg *sync.WaitGroup, e []error) {
+		q := `{{range .users}}<tr><td>{{.Name}}</td><td>{{.Email}}</td><td>
+	<button hx-delete={{printf "/service/tour/%s/ban/%d" $.ind .Id }} hx-target="body">Unban</button>
+	</td></tr>{{end}}`
+
+		if users, err := app.db.GetTourBans(id); err == nil {
+			t := template.Must(template.New("").Parse(q))
+			if err := t.Execute(b, fiber.Map{"ind": ind, "users": users}); err != nil {
+				e[4] = err
+			}
+		} else {
+			e[4] = err
+		}
+		wg.Done()
+	}(&bansTbody, &wg, errs)
+
 	wg.Wait()
 
 	for _, err := range errs {
@@ -404,13 +678,18 @@ func (app *App) renderEditTour(c *fiber.Ctx) error {
 	}
 
 	return c.Render("editTour", fiber.Map{
-		"start":         tour.StartTime.Format("2006 Jan 2 15:04"),
-		"end":           tour.EndTime.Format("2006 Jan 2 15:04"),
-		"ind":           c.Params("id"),
-		"routesTbody":   routesTbody.String(),
-		"creatorsTbody": creatorsTbody.String(),
-		"password":      tour.Pswd,
-		"privacy":       tour.Private,
+		"start":             tour.StartTime.Format("2006 Jan 2 15:04"),
+		"end":               tour.EndTime.Format("2006 Jan 2 15:04"),
+		"ind":               ind,
+		"routesTbody":       routesTbody.String(),
+		"creatorsTbody":     creatorsTbody.String(),
+		"participantsTbody": participantsTbody.String(),
+		"requestsTbody":     requestsTbody.String(),
+		"bansTbody":         bansTbody.String(),
+		"maxUsers":          tour.MaxUsers,
+		"hasCode":           tour.Pswd != "",
+		"joinCode":          joinCode,
+		"privacy":           tour.Private,
 	}, "layouts/base")
 }
 
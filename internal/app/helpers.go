@@ -2,17 +2,29 @@ package app
 
 import (
 	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"html/template"
-	"regexp"
+	"strconv"
+	"strings"
 
+	"github.com/famusovsky/WikiSurfBack/internal/achievements"
 	"github.com/famusovsky/WikiSurfBack/internal/models"
+	"github.com/famusovsky/WikiSurfBack/internal/tourspec"
 	"github.com/gofiber/fiber/v2"
 )
 
 // checkAuth - middleware, проверяющий авторизацию пользователя.
 func (app *App) checkReg(c *fiber.Ctx) error {
+	// Персональные токены дают доступ только к /ext и /api в пределах своих областей.
+	if _, ok := c.Locals("scopes").([]string); ok {
+		return c.Status(fiber.StatusUnauthorized).SendString("api tokens are not accepted here, sign in instead")
+	}
+
 	_, ok := app.getUser(c, errors.New("error while checking authorization"))
 	if !ok {
 		return c.Redirect("/auth")
@@ -21,8 +33,15 @@ func (app *App) checkReg(c *fiber.Ctx) error {
 	return c.Next()
 }
 
-// checkAuthExt - middleware, проверяющий авторизацию пользователя в расширении.
+// checkAuthExt - middleware, проверяющий авторизацию пользователя в расширении по cookie или персональному токену.
 func (app *App) checkRegExt(c *fiber.Ctx) error {
+	if used, err := app.authByToken(c); used {
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).SendString(err.Error())
+		}
+		return c.Next()
+	}
+
 	_, ok := app.getUser(c, errors.New("error while checking authorization"))
 	if !ok {
 		return c.Redirect("/ext/auth")
@@ -31,23 +50,70 @@ func (app *App) checkRegExt(c *fiber.Ctx) error {
 	return c.Next()
 }
 
+// canSeeTour - функция, проверяющая, что пользователь может видеть результаты соревнования:
+// открытого - любой, закрытого - только его участники и создатели.
+func (app *App) canSeeTour(tour models.Tournament, userId int) bool {
+	if !tour.Private {
+		return true
+	}
+
+	participates, _ := app.db.CheckTournamentParticipator(tour.Id, userId)
+	isCreator, _ := app.db.CheckTournamentCreator(tour.Id, userId)
+	return participates || isCreator
+}
+
 // getUser - функция, возвращающая пользователя по fiber.Ctx.
 func (app *App) getUser(c *fiber.Ctx, wrapErr error) (models.User, bool) {
-	email, err := app.ch.Read(c)
+	if user, ok := c.Locals("user").(models.User); ok {
+		return user, true
+	}
+
+	id, session, err := app.readSession(c)
 	if err != nil {
-		// app.errLog.Println(errors.Join(wrapErr, err))
 		return models.User{}, false
 	}
 
-	user, err := app.db.GetUser(email)
+	user, err := app.db.GetUserById(id)
 	if err != nil {
 		app.errLog.Println(errors.Join(wrapErr, err))
 		return models.User{}, false
 	}
+	if user.Session != session {
+		return models.User{}, false
+	}
 
 	return user, true
 }
 
+// setSession - функция, выдающая пользователю cookie сессии, привязанной к его id и версии сессий.
+func (app *App) setSession(c *fiber.Ctx, user models.User) {
+	app.ch.Set(c, fmt.Sprintf("%d:%d", user.Id, user.Session))
+	c.Locals("user", user)
+}
+
+// readSession - функция, получающая из cookie id пользователя и версию его сессии.
+func (app *App) readSession(c *fiber.Ctx) (int, int, error) {
+	value, err := app.ch.Read(c)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	idStr, sessionStr, ok := strings.Cut(value, ":")
+	if !ok {
+		return 0, 0, errors.New("malformed session cookie")
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return 0, 0, err
+	}
+	session, err := strconv.Atoi(sessionStr)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return id, session, nil
+}
+
 // sprintData - структура, хранящая полные данные о спринте.
 type sprintData struct {
 	Id         int
@@ -58,16 +124,17 @@ type sprintData struct {
 	Steps      int
 }
 
+// formatLength - функция, переводящая длительность спринта в ms в читаемый вид.
+func formatLength(ms int64) string {
+	return fmt.Sprintf("%d min, %d s, %d ms", ms/60000, ms/1000%60, ms%1000)
+}
+
 // getFullSprintData - функция, возвращающая полные данные о спринте.
 func (app *App) getFullSprintData(sprint models.Sprint) sprintData {
 	res := sprintData{}
 	res.Id = sprint.Id
 	res.StartTime = sprint.StartTime.Format("2006 Jan 2 15:04")
-	s := sprint.LengthTime / 1000
-	ms := sprint.LengthTime % 1000
-	min := s / 60
-	s = min % 60
-	res.LengthTime = fmt.Sprintf("%d min, %d s, %d ms", min, s, ms)
+	res.LengthTime = formatLength(sprint.LengthTime)
 	res.Steps = len(sprint.Path)
 	r, err := app.db.GetRoute(sprint.RouteId)
 	if err != nil {
@@ -120,10 +187,25 @@ func (app *App) renderErr(c *fiber.Ctx, status int, err error, layout ...string)
 
 // renderSimpleRating - функция, рендерящая простую таблицу рейтинга.
 func (app *App) renderSimpleRating(c *fiber.Ctx, ratings []models.TourRating, wrapErr error) error {
+	ids := make([]int, len(ratings))
+	for i, r := range ratings {
+		ids[i] = r.UserId
+	}
+	badges := app.getBadges(ids)
+
+	data := make([]struct {
+		models.TourRating
+		Badges []achievements.Achievement
+	}, len(ratings))
+	for i, r := range ratings {
+		data[i].TourRating = r
+		data[i].Badges = badges[r.UserId]
+	}
+
 	var b bytes.Buffer
-	q := `{{range .}}<tr><td>{{.UserName}}</td><td>{{.Points}}</td></tr>{{end}}`
+	q := `{{range .}}<tr><td><a href={{printf "/user/%d" .UserId }}>{{.UserName}}</a> ` + badgesTemplate + `</td><td>{{.Points}}</td></tr>{{end}}`
 	t := template.Must(template.New("").Parse(q))
-	if err := t.Execute(&b, ratings); err != nil {
+	if err := t.Execute(&b, data); err != nil {
 		return app.renderErr(c, fiber.StatusInternalServerError, errors.Join(wrapErr, err))
 	}
 
@@ -139,14 +221,8 @@ func (app *App) getOrCreateRoute(c *fiber.Ctx) (models.Route, error) {
 	if err := c.BodyParser(&route); err != nil {
 		return models.Route{}, errors.Join(wrapErr, err)
 	}
-	if route.Start == "" || route.Finish == "" {
-		return models.Route{}, errors.Join(wrapErr, errors.New("empty input"))
-	}
-	if route.Start == route.Finish {
-		return models.Route{}, errors.Join(wrapErr, errors.New("start and finish must be different"))
-	}
-	if r := regexp.MustCompile(`.*wikipedia\.org\/wiki\/[^\s"]+`); !(r.Match([]byte(route.Start)) && r.Match([]byte(route.Finish))) {
-		return models.Route{}, errors.Join(wrapErr, errors.New("input must be a wikipedia article link"))
+	if err := tourspec.CheckRoute(route.Start, route.Finish); err != nil {
+		return models.Route{}, errors.Join(wrapErr, err)
 	}
 	route.CreatorId = user.Id
 
@@ -162,3 +238,79 @@ func (app *App) getOrCreateRoute(c *fiber.Ctx) (models.Route, error) {
 
 	return route, nil
 }
+
+// errTourArchived - ошибка изменения соревнования, итоги которого уже зафиксированы.
+var errTourArchived = errors.New("the tournament is finished and its results are archived")
+
+// checkTourNotArchived - функция, возвращающая ошибку, если итоги соревнования уже зафиксированы.
+func (app *App) checkTourNotArchived(id int) error {
+	archived, err := app.db.CheckTournamentArchived(id)
+	if err != nil {
+		return err
+	}
+	if archived {
+		return errTourArchived
+	}
+
+	return nil
+}
+
+// newToken - функция, генерирующая криптографически случайный секретный токен для ссылок.
+func newToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashToken - функция, возвращающая sha256 хэш секретного токена в hex формате.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// joinCodeAlphabet - алфавит кодов-паролей соревнований без легко путаемых символов.
+const joinCodeAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// newJoinCode - функция, генерирующая криптографически случайный код-пароль соревнования вида XXXX-XXXX.
+func newJoinCode() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	var code strings.Builder
+	for i, b := range buf {
+		if i == 4 {
+			code.WriteByte('-')
+		}
+		code.WriteByte(joinCodeAlphabet[int(b)%len(joinCodeAlphabet)])
+	}
+
+	return code.String(), nil
+}
+
+// hashJoinCode - функция, возвращающая хэш введённого пользователем кода-пароля соревнования.
+// Короткие коды приводятся к каноническому виду, устаревшие 32-символьные пароли хэшируются как есть.
+func hashJoinCode(code string) string {
+	code = strings.TrimSpace(code)
+	if len(code) == 32 {
+		return hashToken(code)
+	}
+
+	normalized := strings.Map(func(r rune) rune {
+		switch r {
+		case '-', ' ':
+			return -1
+		case 'O':
+			return '0'
+		case 'I', 'L':
+			return '1'
+		}
+		return r
+	}, strings.ToUpper(code))
+
+	return hashToken(normalized)
+}
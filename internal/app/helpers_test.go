@@ -0,0 +1,25 @@
+package app
+
+import "testing"
+
+func TestFormatLength(t *testing.T) {
+	tests := []struct {
+		name string
+		ms   int64
+		want string
+	}{
+		{name: "zero", ms: 0, want: "0 min, 0 s, 0 ms"},
+		{name: "milliseconds", ms: 999, want: "0 min, 0 s, 999 ms"},
+		{name: "seconds", ms: 59_001, want: "0 min, 59 s, 1 ms"},
+		{name: "minutes keep their seconds", ms: 125_250, want: "2 min, 5 s, 250 ms"},
+		{name: "an hour is counted in minutes", ms: 3_661_000, want: "61 min, 1 s, 0 ms"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatLength(tt.ms); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
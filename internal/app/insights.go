@@ -0,0 +1,141 @@
+package app
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/famusovsky/WikiSurfBack/internal/analytics"
+	"github.com/famusovsky/WikiSurfBack/internal/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// insightsTop - количество статей каждого вида, сохраняемое в статистике маршрута.
+const insightsTop = 10
+
+// runInsights - функция, периодически пересчитывающая статистику путей до закрытия канала stop.
+func (app *App) runInsights(period time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		app.computeInsights()
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// computeInsights - функция, пересчитывающая статистику путей по всем спринтам.
+func (app *App) computeInsights() {
+	wrapErr := errors.New("error while computing path insights")
+
+	sprints, err := app.db.GetSprintPaths()
+	if err != nil {
+		app.errLog.Println(errors.Join(wrapErr, err))
+		return
+	}
+
+	insights := analytics.Compute(sprints, insightsTop, time.Now())
+	if err := app.db.ReplacePathInsights(insights); err != nil {
+		app.errLog.Println(errors.Join(wrapErr, err))
+		return
+	}
+
+	app.infoLog.Printf("path insights computed from %d sprints\n", len(sprints))
+}
+
+// getInsightsRouteId - функция, получающая id маршрута статистики из запроса, отсутствие маршрута или global - статистика по всем маршрутам.
+func getInsightsRouteId(c *fiber.Ctx) (int, error) {
+	param := c.Params("route", "global")
+	if param == "global" {
+		return analytics.Global, nil
+	}
+
+	return strconv.Atoi(param)
+}
+
+// groupInsights - функция, разбивающая статистику путей по видам.
+func groupInsights(insights []models.PathInsight) (map[string][]models.PathInsight, time.Time) {
+	res := map[string][]models.PathInsight{
+		models.InsightHub:        {},
+		models.InsightDeadEnd:    {},
+		models.InsightFirstClick: {},
+	}
+
+	var computedAt time.Time
+	for _, i := range insights {
+		res[i.Kind] = append(res[i.Kind], i)
+		computedAt = i.ComputedAt
+	}
+
+	return res, computedAt
+}
+
+// renderInsights - рендер страницы статистики путей маршрута или всех маршрутов.
+func (app *App) renderInsights(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while getting the path insights")
+
+	routeId, err := getInsightsRouteId(c)
+	if err != nil {
+		return app.renderErr(c, fiber.StatusBadRequest, errors.Join(wrapErr, err))
+	}
+
+	res := fiber.Map{"routeId": routeId}
+	if routeId != analytics.Global {
+		route, err := app.db.GetRoute(routeId)
+		if err != nil {
+			return app.renderErr(c, fiber.StatusNotFound, errors.Join(wrapErr, err))
+		}
+		res["start"] = route.Start
+		res["finish"] = route.Finish
+	}
+
+	insights, err := app.db.GetPathInsights(routeId)
+	if err != nil {
+		return app.renderErr(c, fiber.StatusInternalServerError, errors.Join(wrapErr, err))
+	}
+	groups, computedAt := groupInsights(insights)
+	res["hubs"] = groups[models.InsightHub]
+	res["deadEnds"] = groups[models.InsightDeadEnd]
+	res["firstClicks"] = groups[models.InsightFirstClick]
+	if !computedAt.IsZero() {
+		res["computedAt"] = computedAt.Format("2006 Jan 2 15:04")
+	}
+
+	return c.Render("insights", res, "layouts/base")
+}
+
+// getInsightsJson - получение статистики путей маршрута или всех маршрутов через API.
+func (app *App) getInsightsJson(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while getting the path insights")
+
+	routeId, err := getInsightsRouteId(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": errors.Join(wrapErr, err).Error()})
+	}
+
+	insights, err := app.db.GetPathInsights(routeId)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": errors.Join(wrapErr, err).Error()})
+	}
+	groups, computedAt := groupInsights(insights)
+
+	res := fiber.Map{
+		"route_id":    routeId,
+		"hubs":        groups[models.InsightHub],
+		"dead_ends":   groups[models.InsightDeadEnd],
+		"computed_at": nil,
+	}
+	if routeId != analytics.Global {
+		res["first_clicks"] = groups[models.InsightFirstClick]
+	}
+	if !computedAt.IsZero() {
+		res["computed_at"] = computedAt
+	}
+
+	return c.JSON(res)
+}
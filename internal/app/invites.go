@@ -0,0 +1,246 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/famusovsky/WikiSurfBack/internal/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// defaultInviteLinkTtl - время жизни ссылки-приглашения по умолчанию.
+const defaultInviteLinkTtl = 48 * time.Hour
+
+// renderTourInvites - рендер списка соревнований, в которые приглашён пользователь.
+func (app *App) renderTourInvites(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while getting user's tour invites")
+	user, _ := app.getUser(c, wrapErr)
+
+	tours, err := app.db.GetUserTourInvites(user.Id)
+	if err != nil {
+		return app.renderErr(c, fiber.StatusNotFound, errors.Join(wrapErr, err))
+	}
+
+	res, err := getToursTable(tours)
+	if err != nil {
+		return app.renderErr(c, fiber.StatusNotFound, wrapErr)
+	}
+	return c.Render("partials/tourList", fiber.Map{
+		"name":  "Tours I am invited to",
+		"tbody": res,
+	})
+}
+
+// inviteToTour - приглашение пользователя в соревнование по почте или имени.
+func (app *App) inviteToTour(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while inviting user to the tour")
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#inviteResult")
+	}
+
+	user, _ := app.getUser(c, wrapErr)
+
+	form := struct {
+		User string
+	}{}
+	if err := c.BodyParser(&form); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#inviteResult")
+	}
+
+	invited, err := app.findUser(strings.TrimSpace(form.User))
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#inviteResult")
+	}
+
+	if err := app.db.InviteUserToTour(models.TURelation{
+		TournamentId: id,
+		UserId:       invited.Id,
+	}, user.Id); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#inviteResult")
+	}
+
+	return c.Redirect(fmt.Sprintf("/tournament/edit/%d", id))
+}
+
+// declineTourInvite - отклонение приглашения в соревнование.
+func (app *App) declineTourInvite(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while declining the tour invite")
+	user, _ := app.getUser(c, wrapErr)
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err))
+	}
+
+	if err := app.db.DeclineTourInvite(id, user.Id); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err))
+	}
+
+	return c.Redirect(fmt.Sprintf("/tournament/%d", id))
+}
+
+// createTourInviteLink - создание одноразовой ссылки-приглашения в соревнование.
+func (app *App) createTourInviteLink(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while creating the tour invite link")
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#inviteLinkResult")
+	}
+
+	user, _ := app.getUser(c, wrapErr)
+
+	form := struct {
+		Hours string
+	}{}
+	if err := c.BodyParser(&form); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#inviteLinkResult")
+	}
+
+	ttl := defaultInviteLinkTtl
+	if form.Hours != "" {
+		hours, err := strconv.Atoi(form.Hours)
+		if err != nil || hours <= 0 {
+			return app.errToResult(c, errors.Join(wrapErr, errors.New("link lifetime must be a positive number of hours")), "#inviteLinkResult")
+		}
+		ttl = time.Duration(hours) * time.Hour
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#inviteLinkResult")
+	}
+
+	if err := app.db.AddTourInviteLink(id, hashToken(token), time.Now().Add(ttl), user.Id); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#inviteLinkResult")
+	}
+
+	return c.SendString(fmt.Sprintf("%s/tournament/join/%s (single use, valid until %s)",
+		app.baseUrl, token, time.Now().Add(ttl).Format("2006 Jan 2 15:04")))
+}
+
+// joinViaInviteLink - вступление в соревнование по одноразовой ссылке-приглашению.
+func (app *App) joinViaInviteLink(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while joining the tour via invite link")
+	user, _ := app.getUser(c, wrapErr)
+
+	id, err := app.db.UseTourInviteLink(hashToken(c.Params("token")), user.Id)
+	if err != nil {
+		return app.renderErr(c, fiber.StatusForbidden, errors.Join(wrapErr, err))
+	}
+
+	return c.Redirect(fmt.Sprintf("/tournament/%d", id))
+}
+
+// approveJoinRequest - одобрение заявки на участие в соревновании.
+func (app *App) approveJoinRequest(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while approving the join request")
+
+	tu, err := getTURelationParams(c)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#participantsResult")
+	}
+
+	user, _ := app.getUser(c, wrapErr)
+	if err := app.db.ApproveTourJoinRequest(tu, user.Id); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#participantsResult")
+	}
+
+	return c.Redirect(fmt.Sprintf("/tournament/edit/%d", tu.TournamentId))
+}
+
+// rejectJoinRequest - отклонение заявки на участие в соревновании.
+func (app *App) rejectJoinRequest(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while rejecting the join request")
+
+	tu, err := getTURelationParams(c)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#participantsResult")
+	}
+
+	user, _ := app.getUser(c, wrapErr)
+	if err := app.db.RejectTourJoinRequest(tu, user.Id); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#participantsResult")
+	}
+
+	return c.Redirect(fmt.Sprintf("/tournament/edit/%d", tu.TournamentId))
+}
+
+// kickParticipant - исключение участника из соревнования, при ban=true - с блокировкой.
+func (app *App) kickParticipant(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while removing the participant")
+
+	tu, err := getTURelationParams(c)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#participantsResult")
+	}
+
+	user, _ := app.getUser(c, wrapErr)
+	if err := app.db.KickUserFromTour(tu, user.Id, c.QueryBool("ban")); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#participantsResult")
+	}
+
+	return c.Redirect(fmt.Sprintf("/tournament/edit/%d", tu.TournamentId))
+}
+
+// unbanParticipant - снятие блокировки пользователя в соревновании.
+func (app *App) unbanParticipant(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while unbanning the user")
+
+	tu, err := getTURelationParams(c)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#participantsResult")
+	}
+
+	user, _ := app.getUser(c, wrapErr)
+	if err := app.db.UnbanUserInTour(tu, user.Id); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#participantsResult")
+	}
+
+	return c.Redirect(fmt.Sprintf("/tournament/edit/%d", tu.TournamentId))
+}
+
+// getTURelationParams - функция, получающая отношение соревнования и пользователя из параметров запроса.
+func getTURelationParams(c *fiber.Ctx) (models.TURelation, error) {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return models.TURelation{}, err
+	}
+
+	userId, err := strconv.Atoi(c.Params("user"))
+	if err != nil {
+		return models.TURelation{}, err
+	}
+
+	return models.TURelation{TournamentId: id, UserId: userId}, nil
+}
+
+// findUser - функция, ищущая пользователя по почте или однозначному имени.
+func (app *App) findUser(key string) (models.User, error) {
+	if key == "" {
+		return models.User{}, errors.New("email or name is required")
+	}
+
+	if strings.Contains(key, "@") {
+		return app.db.GetUser(key)
+	}
+
+	users, err := app.db.GetUsersByName(key)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	switch len(users) {
+	case 0:
+		return models.User{}, fmt.Errorf("there is no user named %q", key)
+	case 1:
+		return users[0], nil
+	default:
+		return models.User{}, fmt.Errorf("there are several users named %q, use an email instead", key)
+	}
+}
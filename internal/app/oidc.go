@@ -0,0 +1,162 @@
+package app
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/famusovsky/WikiSurfBack/internal/models"
+	"github.com/famusovsky/WikiSurfBack/internal/oidc"
+	"github.com/gofiber/fiber/v2"
+)
+
+// getProvidersView - функция, возвращающая данные провайдеров входа для рендера кнопок.
+func (app *App) getProvidersView() []fiber.Map {
+	res := make([]fiber.Map, 0, len(app.providers))
+	for _, p := range app.providers {
+		res = append(res, fiber.Map{
+			"name":  p.Name(),
+			"title": p.Title(),
+		})
+	}
+
+	return res
+}
+
+// getProvider - функция, возвращающая провайдера входа по параметру запроса.
+func (app *App) getProvider(c *fiber.Ctx) (oidc.Provider, error) {
+	name := c.Params("provider")
+	for _, p := range app.providers {
+		if p.Name() == name {
+			return p, nil
+		}
+	}
+
+	return nil, errors.New("unknown sign in provider")
+}
+
+// oidcRedirect - функция, возвращающая адрес возврата от провайдера входа.
+func (app *App) oidcRedirect(p oidc.Provider) string {
+	return app.baseUrl + "/auth/oidc/" + p.Name() + "/callback"
+}
+
+// startOidc - перенаправление пользователя на страницу входа у внешнего провайдера.
+func (app *App) startOidc(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while starting sign in via provider")
+
+	p, err := app.getProvider(c)
+	if err != nil {
+		return app.renderErr(c, fiber.StatusNotFound, errors.Join(wrapErr, err), "layouts/mini")
+	}
+
+	var secrets [3]string
+	for i := range secrets {
+		if secrets[i], err = newToken(); err != nil {
+			return app.renderErr(c, fiber.StatusInternalServerError, errors.Join(wrapErr, err), "layouts/mini")
+		}
+	}
+	state, nonce, verifier := secrets[0], secrets[1], secrets[2]
+
+	url, err := p.AuthURL(c.UserContext(), app.oidcRedirect(p), state, nonce, verifier)
+	if err != nil {
+		return app.renderErr(c, fiber.StatusBadGateway, errors.Join(wrapErr, err), "layouts/mini")
+	}
+
+	ext := "web"
+	if c.QueryBool("ext") {
+		ext = "ext"
+	}
+	app.oidcState.Set(c, strings.Join([]string{p.Name(), state, nonce, verifier, ext}, "|"))
+
+	return c.Redirect(url)
+}
+
+// finishOidc - вход пользователя по ответу внешнего провайдера.
+func (app *App) finishOidc(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while signing in via provider")
+
+	p, err := app.getProvider(c)
+	if err != nil {
+		return app.renderErr(c, fiber.StatusNotFound, errors.Join(wrapErr, err), "layouts/mini")
+	}
+
+	value, err := app.oidcState.Read(c)
+	app.oidcState.Remove(c)
+	if err != nil {
+		return app.renderErr(c, fiber.StatusBadRequest, errors.Join(wrapErr, errors.New("sign in session is expired, try again")), "layouts/mini")
+	}
+	parts := strings.Split(value, "|")
+	if len(parts) != 5 || parts[0] != p.Name() ||
+		subtle.ConstantTimeCompare([]byte(parts[1]), []byte(c.Query("state"))) != 1 {
+		return app.renderErr(c, fiber.StatusBadRequest, errors.Join(wrapErr, errors.New("sign in state does not match, try again")), "layouts/mini")
+	}
+	nonce, verifier, ext := parts[2], parts[3], parts[4] == "ext"
+
+	if e := c.Query("error"); e != "" {
+		return app.renderErr(c, fiber.StatusUnauthorized, errors.Join(wrapErr, errors.New(e+": "+c.Query("error_description"))), "layouts/mini")
+	}
+
+	identity, err := p.Exchange(c.UserContext(), app.oidcRedirect(p), c.Query("code"), nonce, verifier)
+	if err != nil {
+		return app.renderErr(c, fiber.StatusBadGateway, errors.Join(wrapErr, err), "layouts/mini")
+	}
+
+	user, err := app.getIdentityUser(p.Name(), identity)
+	if err != nil {
+		return app.renderErr(c, fiber.StatusUnauthorized, errors.Join(wrapErr, err), "layouts/mini")
+	}
+
+	app.setSession(c, user)
+	app.infoLog.Printf("user %s signed in via %s\n", user.Name, p.Name())
+
+	if ext {
+		return app.renderNotice(c, "Signed in", "You are signed in, return to the WikiSurf extension.")
+	}
+	return c.Redirect("/")
+}
+
+// getIdentityUser - функция, возвращающая пользователя по данным внешнего провайдера.
+// Пользователь ищется по привязке, затем по подтверждённой провайдером почте, иначе создаётся новый.
+// К найденному по почте аккаунту провайдер привязывается, только если почта аккаунта подтверждена.
+func (app *App) getIdentityUser(provider string, identity oidc.Identity) (models.User, error) {
+	user, err := app.db.GetUserByIdentity(provider, identity.Subject)
+	if err == nil {
+		return user, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return models.User{}, err
+	}
+
+	if identity.Email == "" || !identity.EmailVerified {
+		return models.User{}, errors.New("the provider did not share a verified email")
+	}
+
+	if user, err := app.db.GetUser(identity.Email); err == nil {
+		// Неподтверждённая почта могла быть занята кем угодно: привязка отдала бы ему аккаунт владельца почты.
+		if !user.Verified {
+			return models.User{}, errors.New("an account with this email exists but its email is not confirmed: sign in with the password and confirm the email, or reset the password, then sign in via the provider")
+		}
+		if err := app.db.AddUserIdentity(provider, identity.Subject, user.Id); err != nil {
+			return models.User{}, err
+		}
+		return app.db.GetUserById(user.Id)
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return models.User{}, err
+	}
+
+	name := identity.Name
+	if name == "" {
+		name, _, _ = strings.Cut(identity.Email, "@")
+	}
+
+	id, err := app.db.AddUserWithIdentity(models.User{
+		Name:  name,
+		Email: identity.Email,
+	}, provider, identity.Subject)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	return app.db.GetUserById(id)
+}
@@ -0,0 +1,216 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"strconv"
+	"sync"
+
+	"github.com/famusovsky/WikiSurfBack/internal/achievements"
+	"github.com/famusovsky/WikiSurfBack/internal/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// renderProfile - рендер публичного профиля пользователя, /user/me - профиль текущего пользователя.
+func (app *App) renderProfile(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while getting the profile")
+	viewer, _ := app.getUser(c, wrapErr)
+
+	id := viewer.Id
+	if c.Params("id") != "me" {
+		var err error
+		if id, err = strconv.Atoi(c.Params("id")); err != nil {
+			return app.renderErr(c, fiber.StatusBadRequest, errors.Join(wrapErr, err))
+		}
+	}
+
+	user, err := app.db.GetUserById(id)
+	if err != nil {
+		return app.renderErr(c, fiber.StatusNotFound, errors.Join(wrapErr, err))
+	}
+	own := user.Id == viewer.Id
+	if user.Hidden && !own {
+		return app.renderErr(c, fiber.StatusForbidden, errors.Join(wrapErr, errors.New("this profile is private")))
+	}
+
+	var (
+		stats                                                          models.UserStats
+		following                                                      bool
+		bestsBody, activityBody, placesBody, badgesBody, followingBody bytes.Buffer
+	)
+	wg := sync.WaitGroup{}
+	errs := make([]error, 7)
+	wg.Add(7)
+
+	go func(s *models.UserStats, wg *sync.WaitGroup, e []error) {
+		*s, e[0] = app.db.GetUserStats(id)
+		wg.Done()
+	}(&stats, &wg, errs)
+
+	go func(b *bytes.Buffer, wg *sync.WaitGroup, e []error) {
+		defer wg.Done()
+		bests, err := app.db.GetUserBests(id)
+		if err != nil {
+			e[1] = err
+			return
+		}
+		data := make([]struct {
+			models.PersonalBest
+			Length string
+		}, len(bests))
+		for i := range bests {
+			data[i].PersonalBest = bests[i]
+			data[i].Length = formatLength(bests[i].LengthTime)
+		}
+		q := `{{range .}}<tr hx-get={{printf "/sprint/%d" .SprintId }} hx-target="body">
+	<td>{{.Start}}</td>
+	<td>{{.Finish}}</td>
+	<td>{{.Length}}</td>
+	<td>{{.Steps}}</td>
+	</tr>{{end}}`
+		t := template.Must(template.New("").Parse(q))
+		e[1] = t.Execute(b, data)
+	}(&bestsBody, &wg, errs)
+
+	go func(b *bytes.Buffer, wg *sync.WaitGroup, e []error) {
+		defer wg.Done()
+		activity, err := app.db.GetUserActivity(id)
+		if err != nil {
+			e[2] = err
+			return
+		}
+		max := 1
+		for _, a := range activity {
+			if a.Sprints > max {
+				max = a.Sprints
+			}
+		}
+		q := `{{range .activity}}<tr>
+	<td>{{.Month.Format "2006 Jan"}}</td>
+	<td>{{.Sprints}}</td>
+	<td>{{.Successful}}</td>
+	<td><progress value="{{.Sprints}}" max="{{$.max}}"></progress></td>
+	</tr>{{end}}`
+		t := template.Must(template.New("").Parse(q))
+		e[2] = t.Execute(b, fiber.Map{"activity": activity, "max": max})
+	}(&activityBody, &wg, errs)
+
+	go func(b *bytes.Buffer, wg *sync.WaitGroup, e []error) {
+		defer wg.Done()
+		placements, err := app.db.GetUserPlacements(id)
+		if err != nil {
+			e[3] = err
+			return
+		}
+		if !own {
+			visible := placements[:0]
+			for _, p := range placements {
+				tour, err := app.db.GetTournament(p.TournamentId)
+				if err != nil {
+					e[3] = err
+					return
+				}
+				if app.canSeeTour(tour, viewer.Id) {
+					visible = append(visible, p)
+				}
+			}
+			placements = visible
+		}
+		q := `{{range .}}<tr hx-get={{printf "/tournament/%d" .TournamentId }} hx-target="body">
+	<td>#{{.TournamentId}}</td>
+	<td>{{.Place}}</td>
+	<td>{{.Points}}</td>
+	</tr>{{end}}`
+		t := template.Must(template.New("").Parse(q))
+		e[3] = t.Execute(b, placements)
+	}(&placesBody, &wg, errs)
+
+	go func(b *bytes.Buffer, wg *sync.WaitGroup, e []error) {
+		defer wg.Done()
+		awards, err := app.db.GetUserAchievements(id)
+		if err != nil {
+			e[4] = err
+			return
+		}
+		type earned struct {
+			achievements.Achievement
+			AwardedAt string
+		}
+		data := make([]earned, 0, len(awards))
+		for _, award := range awards {
+			if a, ok := achievements.Get(award.Achievement); ok {
+				data = append(data, earned{a, award.AwardedAt.Format("2006 Jan 2")})
+			}
+		}
+		q := `{{range .}}<tr>
+	<td>{{.Icon}} {{.Title}}</td>
+	<td>{{.Description}}</td>
+	<td>{{.AwardedAt}}</td>
+	</tr>{{end}}`
+		t := template.Must(template.New("").Parse(q))
+		e[4] = t.Execute(b, data)
+	}(&badgesBody, &wg, errs)
+
+	go func(b *bytes.Buffer, wg *sync.WaitGroup, e []error) {
+		defer wg.Done()
+		follows, err := app.db.GetFollowing(id, viewer.Id)
+		if err != nil {
+			e[5] = err
+			return
+		}
+		q := `{{range .}}<tr hx-get={{printf "/user/%d" .UserId }} hx-target="body">
+	<td>{{.UserName}}</td>
+	<td>{{.Since.Format "2006 Jan 2"}}</td>
+	</tr>{{end}}`
+		t := template.Must(template.New("").Parse(q))
+		e[5] = t.Execute(b, follows)
+	}(&followingBody, &wg, errs)
+
+	go func(f *bool, wg *sync.WaitGroup, e []error) {
+		defer wg.Done()
+		if !own {
+			*f, e[6] = app.db.IsFollowing(viewer.Id, id)
+		}
+	}(&following, &wg, errs)
+
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return app.renderErr(c, fiber.StatusInternalServerError, errors.Join(wrapErr, err))
+	}
+
+	return c.Render("profile", fiber.Map{
+		"id":            user.Id,
+		"name":          user.Name,
+		"own":           own,
+		"following":     following,
+		"followers":     stats.Followers,
+		"followingNum":  stats.Following,
+		"followingBody": followingBody.String(),
+		"hidden":        user.Hidden,
+		"sprints":       stats.Sprints,
+		"successful":    stats.Successful,
+		"wins":          stats.Wins,
+		"routesCreated": stats.RoutesCreated,
+		"avgClicks":     fmt.Sprintf("%.1f", stats.AvgClicks),
+		"bestsTbody":    bestsBody.String(),
+		"activityTbody": activityBody.String(),
+		"placesTbody":   placesBody.String(),
+		"badgesTbody":   badgesBody.String(),
+	}, "layouts/base")
+}
+
+// toggleProfilePrivacy - скрытие или открытие профиля текущего пользователя.
+func (app *App) toggleProfilePrivacy(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while changing the profile privacy")
+	user, _ := app.getUser(c, wrapErr)
+
+	if err := app.db.SetUserHidden(user.Id, !user.Hidden); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err))
+	}
+	user.Hidden = !user.Hidden
+	c.Locals("user", user)
+
+	return app.renderSettings(c)
+}
@@ -0,0 +1,270 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	"github.com/famusovsky/WikiSurfBack/internal/race"
+	"github.com/gofiber/fiber/v2"
+)
+
+// raceView - структура, хранящая данные для рендера состояния комнаты заезда.
+type raceView struct {
+	race.Room
+	Start    string
+	Finish   string
+	IsHost   bool
+	IsPlayer bool
+	LeftMs   int64
+	Rows     []raceRow
+}
+
+// raceRow - структура, хранящая данные игрока для таблицы комнаты заезда.
+type raceRow struct {
+	Name     string
+	Position string
+	Length   string
+	SprintId int
+}
+
+// renderRaces - функция производящая рендер страницы заездов.
+func (app *App) renderRaces(c *fiber.Ctx) error {
+	return c.Render("races", fiber.Map{}, "layouts/base")
+}
+
+// renderRace - функция производящая рендер страницы комнаты заезда.
+func (app *App) renderRace(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while getting race room")
+	user, _ := app.getUser(c, wrapErr)
+
+	room, err := app.races.Get(raceCode(c.Params("code")))
+	if err != nil {
+		return app.renderErr(c, fiber.StatusNotFound, errors.Join(wrapErr, err))
+	}
+
+	state, err := app.getRaceState(room, user.Id)
+	if err != nil {
+		return app.renderErr(c, fiber.StatusInternalServerError, errors.Join(wrapErr, err))
+	}
+
+	return c.Render("race", fiber.Map{
+		"code":  room.Code,
+		"state": state,
+	}, "layouts/base")
+}
+
+// getRaceStateHtml - функция, возвращающая html состояния комнаты заезда.
+func (app *App) getRaceStateHtml(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while getting race room state")
+	user, _ := app.getUser(c, wrapErr)
+
+	room, err := app.races.Get(raceCode(c.Params("code")))
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#raceResult")
+	}
+
+	state, err := app.getRaceState(room, user.Id)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#raceResult")
+	}
+
+	return c.SendString(state)
+}
+
+// createRace - функция, создающая комнату заезда по маршруту.
+func (app *App) createRace(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while creating race room")
+	user, _ := app.getUser(c, wrapErr)
+
+	route, err := app.getOrCreateRoute(c)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err))
+	}
+
+	room, err := app.races.Create(user.Id, user.Name, route.Id)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err))
+	}
+
+	c.Set("HX-Location", "/race/"+room.Code)
+	return c.SendString("OK")
+}
+
+// raceCode - функция, приводящая введённый пользователем код комнаты к виду, в котором его выдаёт хаб.
+func raceCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// joinRaceViaCode - функция, добавляющая пользователя в комнату заезда по коду из формы.
+func (app *App) joinRaceViaCode(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while joining race room")
+	user, _ := app.getUser(c, wrapErr)
+
+	code := struct {
+		Code string
+	}{}
+	if err := c.BodyParser(&code); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err))
+	}
+
+	room, err := app.races.Join(raceCode(code.Code), user.Id, user.Name)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err))
+	}
+
+	c.Set("HX-Location", "/race/"+room.Code)
+	return c.SendString("OK")
+}
+
+// joinRace - функция, добавляющая пользователя в комнату заезда.
+func (app *App) joinRace(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while joining race room")
+	user, _ := app.getUser(c, wrapErr)
+
+	if _, err := app.races.Join(raceCode(c.Params("code")), user.Id, user.Name); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#raceResult")
+	}
+
+	return app.getRaceStateHtml(c)
+}
+
+// leaveRace - функция, удаляющая пользователя из комнаты заезда.
+func (app *App) leaveRace(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while leaving race room")
+	user, _ := app.getUser(c, wrapErr)
+
+	if err := app.races.Leave(raceCode(c.Params("code")), user.Id); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#raceResult")
+	}
+
+	c.Set("HX-Location", "/races")
+	return c.SendString("OK")
+}
+
+// startRace - функция, запускающая обратный отсчёт в комнате заезда.
+func (app *App) startRace(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while starting the race")
+	user, _ := app.getUser(c, wrapErr)
+
+	if _, err := app.races.Start(raceCode(c.Params("code")), user.Id); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#raceResult")
+	}
+
+	return app.getRaceStateHtml(c)
+}
+
+// raceEvents - функция, отправляющая клиенту состояние комнаты заезда при каждом его изменении (Server-Sent Events).
+func (app *App) raceEvents(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while subscribing to race room")
+	user, _ := app.getUser(c, wrapErr)
+	code := raceCode(c.Params("code"))
+
+	updates, cancel, err := app.races.Subscribe(code)
+	if err != nil {
+		return app.renderErr(c, fiber.StatusNotFound, errors.Join(wrapErr, err), "")
+	}
+
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+		ping := time.NewTicker(15 * time.Second)
+		defer ping.Stop()
+
+		for {
+			room, err := app.races.Get(code)
+			if err != nil {
+				writeEvent(w, "closed", "")
+				w.Flush()
+				return
+			}
+
+			state, err := app.getRaceState(room, user.Id)
+			if err != nil {
+				app.errLog.Println(errors.Join(wrapErr, err))
+				return
+			}
+			writeEvent(w, "state", state)
+			if err := w.Flush(); err != nil {
+				return
+			}
+
+			select {
+			case <-app.stop:
+				return
+			case <-updates:
+			case <-ping.C:
+			}
+		}
+	})
+
+	return nil
+}
+
+// writeEvent - функция, записывающая событие в формате Server-Sent Events.
+func writeEvent(w *bufio.Writer, event, data string) {
+	fmt.Fprintf(w, "event: %s\n", event)
+	for _, line := range strings.Split(data, "\n") {
+		fmt.Fprintf(w, "data: %s\n", line)
+	}
+	w.WriteString("\n")
+}
+
+// getRaceState - функция, возвращающая html состояния комнаты заезда для данного пользователя.
+func (app *App) getRaceState(room race.Room, userId int) (string, error) {
+	view := raceView{
+		Room:     room,
+		IsHost:   room.HostId == userId,
+		IsPlayer: room.HasPlayer(userId),
+		LeftMs:   time.Until(room.StartAt).Milliseconds(),
+		Rows:     make([]raceRow, len(room.Players)),
+	}
+
+	route, err := app.db.GetRoute(room.RouteId)
+	if err != nil {
+		return "", err
+	}
+	view.Start, view.Finish = route.Start, route.Finish
+
+	for i, p := range room.Players {
+		view.Rows[i] = raceRow{Name: p.Name, SprintId: p.SprintId}
+		switch {
+		case p.Done && p.Position > 0:
+			view.Rows[i].Position = fmt.Sprint(p.Position)
+			view.Rows[i].Length = formatLength(p.LengthTime)
+		case p.Done:
+			view.Rows[i].Position = "DNF"
+		default:
+			view.Rows[i].Position = "-"
+		}
+	}
+
+	q := `<div>Room code: <strong>{{.Code}}</strong></div>
+<div>Route: {{.Start}} - {{.Finish}}</div>
+{{if eq .Status 0}}<div>Waiting for the players</div>
+{{if .IsHost}}<button hx-post={{printf "/service/race/%s/start" .Code }} hx-target="#raceState">Start the race</button>{{end}}
+{{if .IsPlayer}}<button hx-delete={{printf "/service/race/%s/join" .Code }} hx-confirm="Are you sure?">Leave the room</button>
+{{else}}<button hx-post={{printf "/service/race/%s/join" .Code }} hx-target="#raceState">Join the race</button>{{end}}
+{{else if eq .Status 1}}<div>Starting in <strong class="countdown" data-left="{{.LeftMs}}"></strong></div>
+{{else if eq .Status 2}}<div>Go! Start the route in the extension: <a href={{.Start}} target="_blank">{{.Start}}</a></div>
+{{else}}<div>The race is over</div>{{end}}
+<table>
+<thead><tr><th>Player</th><th>Position</th><th>Time length</th></tr></thead>
+<tbody>{{range .Rows}}<tr{{if .SprintId}} hx-get={{printf "/sprint/%d" .SprintId }} hx-target="body"{{end}}><td>{{.Name}}</td><td>{{.Position}}</td><td>{{.Length}}</td></tr>{{end}}</tbody>
+</table>`
+
+	var b bytes.Buffer
+	t := template.Must(template.New("").Parse(q))
+	if err := t.Execute(&b, view); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
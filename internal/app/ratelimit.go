@@ -0,0 +1,97 @@
+package app
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/famusovsky/WikiSurfBack/internal/models"
+	"github.com/famusovsky/WikiSurfBack/internal/ratelimit"
+	"github.com/gofiber/fiber/v2"
+)
+
+// rateLimits - структура, хранящая ограничители частоты запросов приложения.
+type rateLimits struct {
+	store         ratelimit.Store    // store - хранилище корзин токенов.
+	authIp        *ratelimit.Limiter // authIp - ограничение входа и регистрации по IP.
+	authAccount   *ratelimit.Limiter // authAccount - ограничение входа и регистрации по почте.
+	joinIp        *ratelimit.Limiter // joinIp - ограничение ввода кодов соревнований по IP.
+	joinAccount   *ratelimit.Limiter // joinAccount - ограничение ввода кодов соревнований по аккаунту.
+	sprintIp      *ratelimit.Limiter // sprintIp - ограничение сохранения спринтов по IP.
+	sprintAccount *ratelimit.Limiter // sprintAccount - ограничение сохранения спринтов по аккаунту.
+	lockout       *ratelimit.Lockout // lockout - блокировка аккаунтов после неудачных попыток входа.
+}
+
+// newRateLimits - функция, создающая ограничители частоты запросов поверх данного хранилища.
+func newRateLimits(store ratelimit.Store) *rateLimits {
+	return &rateLimits{
+		store:         store,
+		authIp:        ratelimit.New(store, "auth-ip", ratelimit.PerMinute(20)),
+		authAccount:   ratelimit.New(store, "auth-account", ratelimit.PerMinute(10)),
+		joinIp:        ratelimit.New(store, "join-ip", ratelimit.PerMinute(10)),
+		joinAccount:   ratelimit.New(store, "join-account", ratelimit.PerMinute(10)),
+		sprintIp:      ratelimit.New(store, "sprint-ip", ratelimit.PerMinute(60)),
+		sprintAccount: ratelimit.New(store, "sprint-account", ratelimit.PerMinute(30)),
+		lockout:       ratelimit.NewLockout(5, 30*time.Second, 15*time.Minute),
+	}
+}
+
+// cleanup - функция, удаляющая устаревшие данные ограничителей.
+func (l *rateLimits) cleanup(maxIdle time.Duration) {
+	if s, ok := l.store.(interface{ Cleanup(time.Duration) }); ok {
+		s.Cleanup(maxIdle)
+	}
+	l.lockout.Cleanup(maxIdle)
+}
+
+// limit - middleware, ограничивающий частоту запросов по IP и по аккаунту.
+func (app *App) limit(byIp, byAccount *ratelimit.Limiter, account func(*fiber.Ctx) string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if ok, wait := byIp.Allow(c.IP()); !ok {
+			return app.tooManyRequests(c, wait)
+		}
+		if key := account(c); key != "" {
+			if ok, wait := byAccount.Allow(key); !ok {
+				return app.tooManyRequests(c, wait)
+			}
+		}
+
+		return c.Next()
+	}
+}
+
+// tooManyRequests - функция, отвечающая статусом 429 с ошибкой в поле результата.
+func (app *App) tooManyRequests(c *fiber.Ctx, wait time.Duration, name ...string) error {
+	secs := int(math.Ceil(wait.Seconds()))
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(secs))
+	c.Status(fiber.StatusTooManyRequests)
+	return app.errToResult(c, fmt.Errorf("too many requests, try again in %d s", secs), name...)
+}
+
+// bodyEmail - функция, получающая почту из тела запроса.
+func bodyEmail(c *fiber.Ctx) string {
+	body := struct {
+		Email string
+	}{}
+	if err := c.BodyParser(&body); err != nil {
+		return ""
+	}
+
+	return strings.ToLower(strings.TrimSpace(body.Email))
+}
+
+// cookieAccount - функция, получающая id авторизованного пользователя из cookie или уже проверенного токена.
+func (app *App) cookieAccount(c *fiber.Ctx) string {
+	if user, ok := c.Locals("user").(models.User); ok {
+		return strconv.Itoa(user.Id)
+	}
+
+	id, _, err := app.readSession(c)
+	if err != nil {
+		return ""
+	}
+
+	return strconv.Itoa(id)
+}
@@ -0,0 +1,168 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/famusovsky/WikiSurfBack/internal/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// checkSprintSteps - функция, проверяющая время шагов и флаги возврата назад присланного спринта.
+// Оба поля необязательны, чтобы старые версии расширения продолжали работать.
+func checkSprintSteps(sprint models.Sprint) error {
+	if len(sprint.StepTimes) != 0 {
+		if len(sprint.StepTimes) != len(sprint.Path) {
+			return errors.New("step_times must have a time for every step of the path")
+		}
+		var prev int64
+		for _, at := range sprint.StepTimes {
+			if at < prev || at > sprint.LengthTime {
+				return errors.New("step_times must grow and fit into the sprint length")
+			}
+			prev = at
+		}
+	}
+	if len(sprint.BackSteps) != 0 && len(sprint.BackSteps) != len(sprint.Path) {
+		return errors.New("back_steps must have a flag for every step of the path")
+	}
+
+	return nil
+}
+
+// replayStep - структура, описывающая шаг спринта при воспроизведении.
+type replayStep struct {
+	Article string // Article - статья шага.
+	At      int64  // At - время перехода на шаг в ms от старта.
+	Split   int64  // Split - время, потраченное на шаг, в ms.
+	Timed   bool   // Timed - флаг, указывающий, что время шага записано, а не оценено.
+	Back    bool   // Back - флаг перехода кнопкой "назад".
+}
+
+// getReplaySteps - функция, возвращающая шаги спринта со временем.
+// У спринтов без записанного времени шаги равномерно распределяются по длительности спринта.
+func getReplaySteps(sprint models.Sprint) []replayStep {
+	timed := len(sprint.StepTimes) == len(sprint.Path)
+	steps := make([]replayStep, len(sprint.Path))
+
+	var prev int64
+	for i, article := range sprint.Path {
+		at := sprint.LengthTime * int64(i+1) / int64(len(sprint.Path))
+		if timed {
+			at = sprint.StepTimes[i]
+		}
+		steps[i] = replayStep{
+			Article: article,
+			At:      at,
+			Split:   at - prev,
+			Timed:   timed,
+			Back:    i < len(sprint.BackSteps) && sprint.BackSteps[i],
+		}
+		prev = at
+	}
+
+	return steps
+}
+
+// formatSplit - функция, переводящая время шага в ms в вид m:ss.mmm.
+func formatSplit(ms int64) string {
+	sign := ""
+	if ms < 0 {
+		sign, ms = "-", -ms
+	}
+
+	return fmt.Sprintf("%s%d:%02d.%03d", sign, ms/60000, ms/1000%60, ms%1000)
+}
+
+// renderReplay - рендер страницы воспроизведения спринта.
+func (app *App) renderReplay(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while getting the sprint replay")
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return app.renderErr(c, fiber.StatusBadRequest, errors.Join(wrapErr, err))
+	}
+	sprint, err := app.db.GetSprint(id)
+	if err != nil {
+		return app.renderErr(c, fiber.StatusNotFound, errors.Join(wrapErr, err))
+	}
+
+	type row struct {
+		Num             int
+		Article         string
+		At              int64
+		Time, SplitTime string
+		Timed, Back     bool
+	}
+	steps := getReplaySteps(sprint)
+	rows := make([]row, len(steps))
+	for i, s := range steps {
+		rows[i] = row{i + 1, s.Article, s.At, formatSplit(s.At), formatSplit(s.Split), s.Timed, s.Back}
+	}
+
+	return c.Render("replay", fiber.Map{
+		"ind":     sprint.Id,
+		"routeId": sprint.RouteId,
+		"length":  formatLength(sprint.LengthTime),
+		"total":   sprint.LengthTime,
+		"timed":   len(steps) != 0 && steps[0].Timed,
+		"steps":   rows,
+	}, "layouts/base")
+}
+
+// renderCompare - рендер страницы сравнения двух спринтов по шагам.
+func (app *App) renderCompare(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while comparing the sprints")
+
+	var sprints [2]models.Sprint
+	for i, param := range []string{c.Params("id"), c.Query("with")} {
+		id, err := strconv.Atoi(param)
+		if err != nil {
+			return app.renderErr(c, fiber.StatusBadRequest, errors.Join(wrapErr, fmt.Errorf("invalid sprint id %q", param)))
+		}
+		if sprints[i], err = app.db.GetSprint(id); err != nil {
+			return app.renderErr(c, fiber.StatusNotFound, errors.Join(wrapErr, err))
+		}
+	}
+
+	var names [2]string
+	for i, sprint := range sprints {
+		names[i] = fmt.Sprintf("User with id:%d", sprint.UserId)
+		if user, err := app.db.GetUserById(sprint.UserId); err == nil {
+			names[i] = user.Name
+		}
+	}
+
+	type side struct {
+		Article, Time string
+		Back, Ok      bool
+	}
+	type row struct {
+		Num   int
+		Sides [2]side
+		Delta string
+	}
+
+	left, right := getReplaySteps(sprints[0]), getReplaySteps(sprints[1])
+	rows := make([]row, max(len(left), len(right)))
+	for i := range rows {
+		rows[i].Num = i + 1
+		for j, steps := range [][]replayStep{left, right} {
+			if i < len(steps) {
+				rows[i].Sides[j] = side{steps[i].Article, formatSplit(steps[i].At), steps[i].Back, true}
+			}
+		}
+		if i < len(left) && i < len(right) {
+			rows[i].Delta = formatSplit(left[i].At - right[i].At)
+		}
+	}
+
+	return c.Render("compare", fiber.Map{
+		"ids":       [2]int{sprints[0].Id, sprints[1].Id},
+		"names":     names,
+		"lengths":   [2]string{formatLength(sprints[0].LengthTime), formatLength(sprints[1].LengthTime)},
+		"sameRoute": sprints[0].RouteId == sprints[1].RouteId,
+		"rows":      rows,
+	}, "layouts/base")
+}
@@ -1,30 +1,52 @@
 package app
 
+import "github.com/famusovsky/WikiSurfBack/internal/models"
+
 // TODO получать роуты по названию и ссылку показывать тоже его
 
 // setRoutes - устанавливает маршрутизацию.
 func setRoutes(app *App) {
 	app.web.Static("/static", "./ui/static")
+	app.web.Use(app.csrfProtection())
 
 	auth := app.web.Group("/auth")
 	auth.Get("/", app.auth)
-	auth.Put("/", app.signIn)
-	auth.Post("/", app.signUp)
+	auth.Put("/", app.limit(app.limits.authIp, app.limits.authAccount, bodyEmail), app.signIn)
+	auth.Post("/", app.limit(app.limits.authIp, app.limits.authAccount, bodyEmail), app.signUp)
 	auth.Delete("/", app.signOut)
 	auth.Get("/signin", app.renderSignin)
 	auth.Get("/signup", app.renderSignup)
+	auth.Get("/oidc/:provider", app.startOidc)
+	auth.Get("/oidc/:provider/callback", app.finishOidc)
+	auth.Get("/verify/:token", app.verifyEmail)
+	auth.Get("/email/:token", app.confirmEmailChange)
+	auth.Get("/reset", app.renderForgotPassword)
+	auth.Post("/reset", app.limit(app.limits.authIp, app.limits.authAccount, bodyEmail), app.requestPasswordReset)
+	auth.Get("/reset/:token", app.renderResetPassword)
+	auth.Put("/reset/:token", app.limit(app.limits.authIp, app.limits.authAccount, bodyEmail), app.resetPassword)
 
 	service := app.web.Group("/service", app.checkReg)
 	service.Get("/rating/route/:route", app.getRouteRating)
 	service.Get("/rating/tour/:tour", app.getTourRating)
+	service.Get("/rating/tour/:tour/teams", app.getTourTeamRating)
 	service.Get("/rating/", app.getRating)
 	service.Get("/tours", app.renderOpenedTournaments)
 	service.Get("/tours/my", app.renderUserTournaments)
 	service.Get("/tours/created", app.renderCreatorTournaments)
+	service.Get("/tours/past", app.renderPastTournaments)
+	service.Get("/tours/placements", app.renderUserPlacements)
+	service.Get("/tours/invites", app.renderTourInvites)
+	service.Get("/tours/templates", app.renderTourTemplates)
 	service.Post("/tour/participate/:id", app.participateViaId, app.renderTournament)
 	service.Delete("/tour/participate/:id", app.quitViaId, app.renderTournament)
-	service.Post("/tour/participate/", app.participateViaPassword)
+	service.Post("/tour/participate/", app.limit(app.limits.joinIp, app.limits.joinAccount, app.cookieAccount), app.participateViaPassword)
 	service.Get("/tour/create", app.createTour)
+	service.Post("/tour/import", app.importTour)
+	service.Get("/tour/:id/export", app.exportTour)
+	service.Post("/tour/:id/clone", app.cloneTour)
+	service.Post("/tour/:id/template", app.saveTourTemplate)
+	service.Post("/template/:id/create", app.createFromTemplate)
+	service.Delete("/template/:id", app.deleteTourTemplate)
 	service.Delete("/tour/:id", app.deleteTour)
 	service.Put("/tour/:id/route", app.addRouteToTour)
 	service.Delete("/tour/:id/route", app.removeRouteFromTour)
@@ -32,26 +54,103 @@ func setRoutes(app *App) {
 	service.Delete("/tour/:id/creator", app.removeCreatorFromTour)
 	service.Put("/tour/:id", app.updateTour)
 	service.Post("/tour/:id/privacy", app.toggleTourPrivace)
+	service.Post("/tour/:id/code", app.regenerateTourCode)
+	service.Delete("/tour/:id/code", app.revokeTourCode)
+	service.Put("/tour/:id/invite", app.inviteToTour)
+	service.Delete("/tour/:id/invite", app.declineTourInvite)
+	service.Post("/tour/:id/invite/link", app.createTourInviteLink)
+	service.Post("/tour/:id/request/:user", app.approveJoinRequest)
+	service.Delete("/tour/:id/request/:user", app.rejectJoinRequest)
+	service.Delete("/tour/:id/participant/:user", app.kickParticipant)
+	service.Delete("/tour/:id/ban/:user", app.unbanParticipant)
+	service.Get("/tour/:id/leaderboard/:kind", app.renderLeaderboard)
+	service.Get("/tour/:id/teams", app.renderTeams)
+	service.Post("/tour/:id/team", app.createTeam)
+	service.Delete("/tour/:id/team", app.leaveTeam)
+	service.Put("/tour/:id/team/invite", app.inviteToTeam)
+	service.Post("/tour/:id/team/:team/join", app.joinTeam)
+	service.Get("/tour/:id/bracket", app.renderBracket)
+	service.Get("/tour/:id/bracket/json", app.getBracketJson)
+	service.Post("/tour/:id/bracket", app.createBracket)
+	service.Delete("/tour/:id/bracket", app.deleteBracket)
+	service.Post("/tour/:id/bracket/:num/settle", app.settleBracketMatch)
+	service.Post("/tour/:id/bracket/:num/winner", app.setBracketWinner)
+	service.Put("/tour/:id/collection", app.addCollectionToTour)
+	service.Get("/collections", app.renderCollectionList)
+	service.Post("/collection", app.createCollection)
+	service.Put("/collection/:id/route", app.addRouteToCollection)
+	service.Delete("/collection/:id/route/:route", app.removeRouteFromCollection)
+	service.Post("/collection/:id/route/:route/move", app.moveCollectionRoute)
+	service.Post("/collection/:id/privacy", app.toggleCollectionPrivacy)
+	service.Delete("/collection/:id", app.deleteCollection)
+	service.Post("/follow/:id", app.follow)
+	service.Delete("/follow/:id", app.unfollow)
+	service.Get("/feed", app.renderFeed)
 	service.Post("/route/create", app.createRoute)
+	service.Get("/route/:id/comments", app.renderComments)
+	service.Post("/route/:id/comment", app.addComment)
+	service.Delete("/route/:id/comment/:comment", app.deleteComment)
+	service.Get("/route/:id/vote", app.renderVotes)
+	service.Put("/route/:id/vote", app.voteRoute)
+	service.Post("/route/:id/report", app.reportRoute)
+	service.Post("/race", app.createRace)
+	service.Post("/race/join", app.joinRaceViaCode)
+	service.Get("/race/:code/state", app.getRaceStateHtml)
+	service.Get("/race/:code/events", app.raceEvents)
+	service.Post("/race/:code/join", app.joinRace)
+	service.Delete("/race/:code/join", app.leaveRace)
+	service.Post("/race/:code/start", app.startRace)
 
 	app.web.Get("/ext/auth", app.authExt)
 	ext := app.web.Group("/ext", app.checkRegExt)
-	ext.Get("/", app.renderMainExt)
-	ext.Get("/start", app.renderStartExt)
-	ext.Post("/start", app.startRouteExt)
-	ext.Get("/routes", app.renderRoutesExt)
-	ext.Get("/tours", app.renderToursExt)
-	ext.Post("/sprint", app.addSprintExt)
+	ext.Get("/", app.requireScope(models.ScopeRead), app.renderMainExt)
+	ext.Get("/start", app.requireScope(models.ScopeSprintWrite), app.renderStartExt)
+	ext.Post("/start", app.requireScope(models.ScopeSprintWrite), app.startRouteExt)
+	ext.Get("/routes", app.requireScope(models.ScopeRead), app.renderRoutesExt)
+	ext.Get("/tours", app.requireScope(models.ScopeRead), app.renderToursExt)
+	ext.Get("/ghost/:route", app.requireScope(models.ScopeRead), app.getGhostJson)
+	ext.Post("/sprint", app.requireScope(models.ScopeSprintWrite), app.limit(app.limits.sprintIp, app.limits.sprintAccount, app.cookieAccount), app.addSprintExt)
+
+	api := app.web.Group("/api", app.checkApi)
+	api.Get("/user", app.requireScope(models.ScopeRead), app.getApiUser)
+	api.Get("/sprints", app.requireScope(models.ScopeRead), app.getApiSprints)
+	api.Get("/tours", app.requireScope(models.ScopeRead), app.getApiTours)
+	api.Get("/rating/route/:route", app.requireScope(models.ScopeRead), app.getApiRouteRating)
+	api.Get("/ghost/:route", app.requireScope(models.ScopeRead), app.getGhostJson)
+	api.Get("/insights/:route", app.requireScope(models.ScopeRead), app.getInsightsJson)
+	api.Get("/export/history", app.requireScope(models.ScopeRead), app.exportHistory)
+	api.Get("/export/route/:route", app.requireScope(models.ScopeRead), app.exportRouteRating)
+	api.Get("/export/tour/:tour", app.requireScope(models.ScopeRead), app.exportTourSprints)
+	api.Post("/sprint", app.requireScope(models.ScopeSprintWrite), app.limit(app.limits.sprintIp, app.limits.sprintAccount, app.cookieAccount), app.addApiSprint)
 
 	base := app.web.Group("/", app.checkReg)
 	base.All("/", app.renderMain)
 	base.Get("/history", app.renderHistory)
+	base.Get("/export/history", app.exportHistory)
+	base.Get("/export/route/:route", app.exportRouteRating)
+	base.Get("/export/tour/:tour", app.exportTourSprints)
 	base.Get("/settings", app.renderSettings)
 	base.Put("/service/user", app.updateUser)
+	base.Post("/service/user/verify", app.resendVerification)
+	base.Delete("/service/user", app.deleteAccount)
+	base.Post("/service/user/privacy", app.toggleProfilePrivacy)
+	base.Get("/service/user/tokens", app.renderApiTokens)
+	base.Post("/service/user/token", app.createApiToken)
+	base.Delete("/service/user/token/:id", app.revokeApiToken)
+	base.Get("/user/:id", app.renderProfile)
 	base.Get("/sprint/:id", app.renderSprint)
+	base.Get("/sprint/:id/replay", app.renderReplay)
+	base.Get("/sprint/:id/compare", app.renderCompare)
 	base.Get("/route/:id", app.renderRoute)
+	base.Get("/route/:route/insights", app.renderInsights)
+	base.Get("/insights", app.renderInsights)
+	base.All("/collections", app.renderCollections)
+	base.All("/collection/:id", app.renderCollection)
 	base.Get("/tournaments", app.renderTournaments)
 	base.Get("/tournament/:id", app.renderTournament) // do not show if tour is private and user not participates or creates
 	base.All("/tournament/edit/:id", app.renderEditTour)
+	base.Get("/tournament/join/:token", app.joinViaInviteLink)
+	base.Get("/races", app.renderRaces)
+	base.Get("/race/:code", app.renderRace)
 	base.Get("favicon.ico", app.favicon)
 }
@@ -0,0 +1,57 @@
+package app
+
+import (
+	"errors"
+	"time"
+)
+
+// runScheduler - функция, периодически выполняющая фоновые задачи приложения до закрытия канала stop.
+func (app *App) runScheduler(period time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		app.archiveTournaments()
+		app.archiveSeasons()
+		app.createRecurringTours()
+		app.races.Cleanup(time.Hour)
+		app.limits.cleanup(time.Hour)
+		if err := app.db.DeleteExpiredUserTokens(); err != nil {
+			app.errLog.Println(err)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// archiveTournaments - функция, фиксирующая итоги завершившихся соревнований.
+func (app *App) archiveTournaments() {
+	wrapErr := errors.New("error while archiving finished tournaments")
+
+	tours, err := app.db.GetTournamentsToArchive()
+	if err != nil {
+		app.errLog.Println(errors.Join(wrapErr, err))
+		return
+	}
+
+	for _, tour := range tours {
+		if err := app.db.ArchiveTournament(tour.Id); err != nil {
+			app.errLog.Println(errors.Join(wrapErr, err))
+			continue
+		}
+		app.infoLog.Printf("tournament #%d archived\n", tour.Id)
+
+		ratings, err := app.db.GetTournamentRatings(tour.Id)
+		if err != nil {
+			app.errLog.Println(errors.Join(wrapErr, err))
+			continue
+		}
+		for _, r := range ratings {
+			app.awardAchievements(r.UserId)
+		}
+	}
+}
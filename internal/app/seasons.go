@@ -0,0 +1,85 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/famusovsky/WikiSurfBack/internal/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ratingPeriod - структура, описывающая период рейтинга, выбранный в запросе.
+type ratingPeriod struct {
+	set        bool      // set - флаг, указывающий, что период выбран, иначе рейтинг за всё время.
+	start, end time.Time // start, end - границы периода.
+	season     int       // season - id выбранного сезона, 0 - если выбран не сезон.
+}
+
+// getRatingPeriod - функция, получающая период рейтинга из параметров запроса.
+//
+// period=week и period=month - текущие календарная неделя и месяц,
+// period=season-<id> или season=<id> - сезон, без параметров - всё время.
+func (app *App) getRatingPeriod(c *fiber.Ctx) (ratingPeriod, error) {
+	period, season := c.Query("period"), c.Query("season")
+	if id, ok := strings.CutPrefix(period, "season-"); ok {
+		season = id
+	}
+
+	now := time.Now()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch {
+	case season != "":
+		id, err := strconv.Atoi(season)
+		if err != nil {
+			return ratingPeriod{}, err
+		}
+		s, err := app.db.GetSeason(id)
+		if err != nil {
+			return ratingPeriod{}, err
+		}
+		return ratingPeriod{set: true, start: s.StartTime, end: s.EndTime, season: s.Id}, nil
+	case period == "week":
+		start := day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+		return ratingPeriod{set: true, start: start, end: start.AddDate(0, 0, 7)}, nil
+	case period == "month":
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		return ratingPeriod{set: true, start: start, end: start.AddDate(0, 1, 0)}, nil
+	case period == "" || period == "all":
+		return ratingPeriod{}, nil
+	default:
+		return ratingPeriod{}, fmt.Errorf("unknown rating period %q", period)
+	}
+}
+
+// getSeasonsView - функция, возвращающая сезоны для выбора периода рейтинга.
+func (app *App) getSeasonsView() []models.Season {
+	seasons, err := app.db.GetSeasons()
+	if err != nil {
+		app.errLog.Println(errors.Join(errors.New("error while getting seasons"), err))
+		return []models.Season{}
+	}
+
+	return seasons
+}
+
+// archiveSeasons - функция, фиксирующая итоги закончившихся сезонов.
+func (app *App) archiveSeasons() {
+	wrapErr := errors.New("error while archiving finished seasons")
+
+	seasons, err := app.db.GetSeasonsToArchive()
+	if err != nil {
+		app.errLog.Println(errors.Join(wrapErr, err))
+		return
+	}
+
+	for _, season := range seasons {
+		if err := app.db.ArchiveSeason(season.Id); err != nil {
+			app.errLog.Println(errors.Join(wrapErr, err))
+			continue
+		}
+		app.infoLog.Printf("season #%d %q archived\n", season.Id, season.Name)
+	}
+}
@@ -2,15 +2,18 @@ package app
 
 import (
 	"bytes"
+	"database/sql"
 	"errors"
 	"fmt"
 	"html/template"
-	"math/rand"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/badoux/checkmail"
+	"github.com/famusovsky/WikiSurfBack/internal/achievements"
 	"github.com/famusovsky/WikiSurfBack/internal/models"
 	"github.com/gofiber/fiber/v2"
 	"golang.org/x/crypto/bcrypt"
@@ -24,27 +27,53 @@ func (app *App) getRouteRating(c *fiber.Ctx) error {
 		return app.renderErr(c, fiber.StatusBadRequest, errors.Join(wrapErr, err))
 	}
 
-	ratings, err := app.db.GetRouteRatings(id)
+	period, err := app.getRatingPeriod(c)
+	if err != nil {
+		return app.renderErr(c, fiber.StatusBadRequest, errors.Join(wrapErr, err))
+	}
+
+	var ratings []models.RouteRating
+	if period.set {
+		ratings, err = app.db.GetRouteRatingsBetween(id, period.start, period.end)
+	} else {
+		ratings, err = app.db.GetRouteRatings(id)
+	}
 	if err != nil {
 		return app.renderErr(c, fiber.StatusNotFound, errors.Join(wrapErr, err))
 	}
+	if c.Query("friends") != "" {
+		user, _ := app.getUser(c, wrapErr)
+		circle, err := app.getFriendsCircle(user.Id)
+		if err != nil {
+			return app.renderErr(c, fiber.StatusInternalServerError, errors.Join(wrapErr, err))
+		}
+		ratings = slices.DeleteFunc(ratings, func(r models.RouteRating) bool {
+			return !slices.Contains(circle, r.UserId)
+		})
+	}
+	ids := make([]int, len(ratings))
+	for i, r := range ratings {
+		ids[i] = r.UserId
+	}
+	badges := app.getBadges(ids)
+
 	ratingsData := make([]struct {
 		Id     int
+		UserId int
 		Name   string
 		Length string
 		Steps  string
+		Badges []achievements.Achievement
 	}, len(ratings))
 	for i := 0; i < len(ratings); i++ {
 		sprint, _ := app.db.GetSprint(ratings[i].SprintId) // TODO get route ratings directly from db
 		data := app.getFullSprintData(sprint)
 
 		ratingsData[i].Id = ratings[i].SprintId
+		ratingsData[i].UserId = ratings[i].UserId
+		ratingsData[i].Badges = badges[ratings[i].UserId]
 
-		s := ratings[i].SprintLengthTime / 1000
-		ms := ratings[i].SprintLengthTime % 1000
-		min := s / 60
-		s = min % 60
-		ratingsData[i].Length = fmt.Sprintf("%d min, %d s, %d ms", min, s, ms)
+		ratingsData[i].Length = formatLength(ratings[i].SprintLengthTime)
 
 		ratingsData[i].Steps = strconv.Itoa(data.Steps)
 
@@ -58,7 +87,7 @@ func (app *App) getRouteRating(c *fiber.Ctx) error {
 
 	var b bytes.Buffer
 	q := `{{range .}}<tr hx-get={{printf "/sprint/%d" .Id }} hx-target="body">
-	<td>{{.Name}}</td>
+	<td><a href={{printf "/user/%d" .UserId }} onclick="event.stopPropagation()">{{.Name}}</a> ` + badgesTemplate + `</td>
 	<td>{{.Length}}</td>
 	<td>{{.Steps}}</td>
 	</tr>{{end}}`
@@ -86,11 +115,69 @@ func (app *App) getTourRating(c *fiber.Ctx) error {
 	return app.renderSimpleRating(c, ratings, wrapErr)
 }
 
+// getTourTeamRating - функция, возвращяющая командный рейтинг по соревнованию.
+func (app *App) getTourTeamRating(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while getting tournament team ratings in api")
+	id, err := strconv.Atoi(c.Params("tour"))
+	if err != nil {
+		return app.renderErr(c, fiber.StatusBadRequest, errors.Join(wrapErr, err))
+	}
+
+	ratings, err := app.db.GetTournamentTeamRatings(id)
+	if err != nil {
+		return app.renderErr(c, fiber.StatusNotFound, errors.Join(wrapErr, err))
+	}
+
+	var b bytes.Buffer
+	q := `{{range .}}<tr><td>{{.TeamName}}</td><td>{{.Points}}</td><td>{{.Members}}</td></tr>{{end}}`
+	t := template.Must(template.New("").Parse(q))
+	if err := t.Execute(&b, ratings); err != nil {
+		return app.renderErr(c, fiber.StatusInternalServerError, errors.Join(wrapErr, err))
+	}
+
+	return c.SendString(b.String())
+}
+
 // getRating - функция, возвращяющая общий рейтинг.
 func (app *App) getRating(c *fiber.Ctx) error {
 	wrapErr := errors.New("error while getting ratings in api")
 
-	ratings, err := app.db.GetRatings()
+	if c.Query("ladder") == "skill" {
+		return app.renderSkillLadder(c)
+	}
+
+	period, err := app.getRatingPeriod(c)
+	if err != nil {
+		return app.renderErr(c, fiber.StatusBadRequest, errors.Join(wrapErr, err))
+	}
+
+	var ratings []models.TourRating
+	if c.Query("friends") != "" {
+		user, _ := app.getUser(c, wrapErr)
+		circle, err := app.getFriendsCircle(user.Id)
+		if err != nil {
+			return app.renderErr(c, fiber.StatusInternalServerError, errors.Join(wrapErr, err))
+		}
+		if period.set {
+			ratings, err = app.db.GetRatingsAmongBetween(circle, period.start, period.end)
+		} else {
+			ratings, err = app.db.GetRatingsAmong(circle)
+		}
+		if err != nil {
+			return app.renderErr(c, fiber.StatusNotFound, errors.Join(wrapErr, err))
+		}
+
+		return app.renderSimpleRating(c, ratings, wrapErr)
+	}
+
+	switch {
+	case period.season != 0:
+		ratings, err = app.db.GetSeasonRatings(period.season)
+	case period.set:
+		ratings, err = app.db.GetRatingsBetween(period.start, period.end)
+	default:
+		ratings, err = app.db.GetRatings()
+	}
 	if err != nil {
 		return app.renderErr(c, fiber.StatusNotFound, errors.Join(wrapErr, err))
 	}
@@ -101,32 +188,82 @@ func (app *App) getRating(c *fiber.Ctx) error {
 // updateUser - функция, обновляющая данные пользователя.
 func (app *App) updateUser(c *fiber.Ctx) error {
 	wrapErr := errors.New("error while updating user")
-	user, _ := app.getUser(c, wrapErr)
+	user, ok := app.getUser(c, wrapErr)
+	if !ok {
+		return app.errToResult(c, errors.Join(wrapErr, errors.New("you are not signed in")))
+	}
 
 	creds := struct {
 		Name     string
 		Email    string
 		Password string
+		Current  string
 	}{}
-	c.BodyParser(&creds)
-	if creds.Name != "" {
+	if err := c.BodyParser(&creds); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err))
+	}
+	creds.Email = strings.TrimSpace(creds.Email)
+
+	newEmail := ""
+	if creds.Email != "" && !strings.EqualFold(creds.Email, user.Email) {
+		if err := checkmail.ValidateFormat(creds.Email); err != nil {
+			return app.errToResult(c, errors.Join(wrapErr, err))
+		}
+		if _, err := app.db.GetUser(creds.Email); err == nil {
+			return app.errToResult(c, errors.Join(wrapErr, errors.New("this email is already taken")))
+		}
+		newEmail = creds.Email
+	}
+
+	if newEmail != "" || creds.Password != "" {
+		if err := app.checkCurrentPassword(c, user, creds.Current); err != nil {
+			return app.errToResult(c, errors.Join(wrapErr, err))
+		}
+	}
+
+	rename := creds.Name != "" && creds.Name != user.Name
+	if rename {
 		user.Name = creds.Name
 	}
+
+	var (
+		hashedPassword []byte
+		err            error
+	)
 	if creds.Password != "" {
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(creds.Password), 8)
-		if err != nil {
+		if err := validatePassword(creds.Password, user); err != nil {
 			return app.errToResult(c, errors.Join(wrapErr, err))
 		}
+		if hashedPassword, err = bcrypt.GenerateFromPassword([]byte(creds.Password), 8); err != nil {
+			return app.errToResult(c, errors.Join(wrapErr, err))
+		}
+	}
 
-		user.Password = string(hashedPassword)
+	// изменения сохраняются только после проверки всех полей
+	if rename {
+		if err := app.db.UpdateUser(user); err != nil {
+			return app.errToResult(c, errors.Join(wrapErr, err))
+		}
 	}
-	if creds.Email != "" {
-		user.Email = creds.Email
+
+	if hashedPassword != nil {
+		if err := app.db.ChangeUserPassword(user.Id, string(hashedPassword)); err != nil {
+			return app.errToResult(c, errors.Join(wrapErr, err))
+		}
+
+		// смена пароля завершает все сессии, текущую нужно выдать заново
+		if user, err = app.db.GetUserById(user.Id); err != nil {
+			return app.errToResult(c, errors.Join(wrapErr, err))
+		}
+		app.setSession(c, user)
+		c.Locals("notice", "The password is changed, other sessions are signed out")
 	}
 
-	err := app.db.UpdateUser(user)
-	if err != nil {
-		return app.errToResult(c, errors.Join(wrapErr, err))
+	if newEmail != "" {
+		if err := app.sendUserToken(c, user, models.TokenEmail, newEmail); err != nil {
+			return app.errToResult(c, errors.Join(wrapErr, err))
+		}
+		c.Locals("notice", fmt.Sprintf("A confirmation link has been sent to %s, the email will change after you open it", newEmail))
 	}
 
 	return app.renderSettings(c)
@@ -142,6 +279,24 @@ func (app *App) participateViaId(c *fiber.Ctx) error {
 		return app.errToResult(c, errors.Join(wrapErr, err))
 	}
 
+	tour, err := app.db.GetTournament(tourId)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err))
+	}
+
+	if tour.Private {
+		invited, err := app.db.CheckTourInvite(tourId, user.Id)
+		if err != nil {
+			return app.errToResult(c, errors.Join(wrapErr, err))
+		}
+		if !invited {
+			if err := app.db.AddTourJoinRequest(tourId, user.Id); err != nil {
+				return app.errToResult(c, errors.Join(wrapErr, err))
+			}
+			return c.Next()
+		}
+	}
+
 	err = app.db.AddUserToTour(tourId, user.Id)
 	if err != nil {
 		return app.errToResult(c, errors.Join(wrapErr, err))
@@ -180,9 +335,9 @@ func (app *App) participateViaPassword(c *fiber.Ctx) error {
 		return app.errToResult(c, errors.Join(wrapErr, err))
 	}
 
-	id, err := app.db.CheckTournamentPassword(pswd.Password)
+	id, err := app.db.CheckTournamentPassword(hashJoinCode(pswd.Password))
 	if err != nil {
-		return app.errToResult(c, errors.Join(wrapErr, err))
+		return app.errToResult(c, errors.Join(wrapErr, errors.New("invalid join code")))
 	}
 
 	err = app.db.AddUserToTour(id, user.Id)
@@ -220,42 +375,28 @@ func (app *App) createRoute(c *fiber.Ctx) error {
 
 // createTour - функция, создающая соревнование.
 func (app *App) createTour(c *fiber.Ctx) error {
-	var pswd strings.Builder
-	getRand := func(out *strings.Builder) {
-		charSet := []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ1234567890")
-		for i := 0; i < 32; i++ {
-			random := rand.Intn(len(charSet))
-			randomChar := charSet[random]
-			out.WriteRune(randomChar)
-		}
-	}
-
-	for {
-		getRand(&pswd)
-		_, err := app.db.CheckTournamentPassword(pswd.String())
-		if err != nil {
-			break
-		}
-		pswd.Reset()
-	}
-
 	t := models.Tournament{
 		StartTime: time.Now(),
 		EndTime:   time.Now().AddDate(0, 0, 7),
 		Private:   true,
-		Pswd:      pswd.String(),
 	}
 
 	wrapErr := errors.New("error while creating tour")
 
 	user, _ := app.getUser(c, wrapErr)
 
+	code, err := app.newUniqueJoinCode()
+	if err != nil {
+		return app.renderErr(c, fiber.StatusInternalServerError, errors.Join(wrapErr, err))
+	}
+	t.Pswd = hashJoinCode(code)
+
 	id, err := app.db.AddTournament(t, user.Id)
 	if err != nil {
 		return app.renderErr(c, fiber.StatusInternalServerError, errors.Join(wrapErr, err))
 	}
 
-	return c.Redirect(fmt.Sprintf("/tournament/edit/%d", id))
+	return app.renderTourEditor(c, id, code)
 }
 
 // updateTour - функция, обновляющая соревнование.
@@ -273,9 +414,14 @@ func (app *App) updateTour(c *fiber.Ctx) error {
 		return app.errToResult(c, errors.Join(wrapErr, err))
 	}
 
+	if err := app.checkTourNotArchived(id); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err))
+	}
+
 	times := struct {
 		Begin string
 		End   string
+		Max   string
 	}{}
 	if err := c.BodyParser(&times); err != nil {
 		return app.errToResult(c, errors.Join(wrapErr, err))
@@ -291,6 +437,13 @@ func (app *App) updateTour(c *fiber.Ctx) error {
 	if t, err := time.Parse("2006-01-02T15:04:00Z", times.End+":00Z"); err == nil && !t.IsZero() {
 		tour.EndTime = t
 	}
+	if times.Max != "" {
+		max, err := strconv.Atoi(times.Max)
+		if err != nil || max < 0 {
+			return app.errToResult(c, errors.Join(wrapErr, errors.New("participant limit must be a non-negative number")))
+		}
+		tour.MaxUsers = max
+	}
 
 	if err := app.db.UpdateTournament(tour, user.Id); err != nil {
 		return app.errToResult(c, errors.Join(wrapErr, err))
@@ -323,6 +476,70 @@ func (app *App) toggleTourPrivace(c *fiber.Ctx) error {
 	return c.Redirect(fmt.Sprintf("/tournament/edit/%d", id))
 }
 
+// regenerateTourCode - генерация нового кода-пароля соревнования, код показывается только один раз.
+func (app *App) regenerateTourCode(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while regenerating the tour join code")
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#codeResult")
+	}
+	user, _ := app.getUser(c, wrapErr)
+	tour, err := app.db.GetTournament(id)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#codeResult")
+	}
+
+	code, err := app.newUniqueJoinCode()
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#codeResult")
+	}
+
+	tour.Pswd = hashJoinCode(code)
+	if err := app.db.UpdateTournament(tour, user.Id); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#codeResult")
+	}
+
+	return c.SendString(fmt.Sprintf("New join code: %s (it will not be shown again, the previous code no longer works)", code))
+}
+
+// newUniqueJoinCode - функция, генерирующая код-пароль, не совпадающий с кодами других соревнований.
+func (app *App) newUniqueJoinCode() (string, error) {
+	for {
+		code, err := newJoinCode()
+		if err != nil {
+			return "", err
+		}
+		_, err = app.db.CheckTournamentPassword(hashJoinCode(code))
+		if errors.Is(err, sql.ErrNoRows) {
+			return code, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// revokeTourCode - отзыв кода-пароля соревнования.
+func (app *App) revokeTourCode(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while revoking the tour join code")
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#codeResult")
+	}
+	user, _ := app.getUser(c, wrapErr)
+	tour, err := app.db.GetTournament(id)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#codeResult")
+	}
+
+	tour.Pswd = ""
+	if err := app.db.UpdateTournament(tour, user.Id); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#codeResult")
+	}
+
+	return c.Redirect(fmt.Sprintf("/tournament/edit/%d", id))
+}
+
 // deleteTour - функция, удаляющая соревнование.
 func (app *App) deleteTour(c *fiber.Ctx) error {
 	wrapErr := errors.New("error while deleting the tour")
@@ -360,6 +577,10 @@ func (app *App) addRouteToTour(c *fiber.Ctx) error {
 		return app.errToResult(c, errors.Join(wrapErr, err), "#routesResult")
 	}
 
+	if err := app.checkTourNotArchived(id); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#routesResult")
+	}
+
 	route, err := app.getOrCreateRoute(c)
 	if err != nil {
 		return app.errToResult(c, errors.Join(wrapErr, err), "#routesResult")
@@ -390,6 +611,10 @@ func (app *App) removeRouteFromTour(c *fiber.Ctx) error {
 		return app.errToResult(c, errors.Join(wrapErr, err), "#routesResult")
 	}
 
+	if err := app.checkTourNotArchived(id); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#routesResult")
+	}
+
 	route := models.Route{}
 	if err := c.BodyParser(&route); err != nil {
 		return app.errToResult(c, errors.Join(wrapErr, err), "#routesResult")
@@ -485,3 +710,134 @@ func (app *App) removeCreatorFromTour(c *fiber.Ctx) error {
 
 	return c.Redirect(fmt.Sprintf("/tournament/edit/%d", id))
 }
+
+// createTeam - функция, создающая команду в соревновании.
+func (app *App) createTeam(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while creating the team")
+	user, _ := app.getUser(c, wrapErr)
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#teamsResult")
+	}
+
+	ok, err := app.db.CheckTournamentParticipator(id, user.Id)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#teamsResult")
+	}
+	if !ok {
+		return app.errToResult(c, errors.Join(wrapErr, errors.New("only participants can create teams")), "#teamsResult")
+	}
+
+	if err := app.checkTourNotArchived(id); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#teamsResult")
+	}
+
+	team := struct {
+		Name string
+	}{}
+	if err := c.BodyParser(&team); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#teamsResult")
+	}
+	if strings.TrimSpace(team.Name) == "" {
+		return app.errToResult(c, errors.Join(wrapErr, errors.New("empty team name")), "#teamsResult")
+	}
+
+	if _, err := app.db.AddTeam(models.Team{
+		TournamentId: id,
+		Name:         strings.TrimSpace(team.Name),
+		CaptainId:    user.Id,
+	}); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#teamsResult")
+	}
+
+	return app.renderTeams(c)
+}
+
+// inviteToTeam - функция, приглашающая пользователя в команду по email.
+func (app *App) inviteToTeam(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while inviting to the team")
+	user, _ := app.getUser(c, wrapErr)
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#teamsResult")
+	}
+
+	if err := app.checkTourNotArchived(id); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#teamsResult")
+	}
+
+	team, err := app.db.GetUserTeam(id, user.Id)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#teamsResult")
+	}
+
+	email := struct {
+		Email string
+	}{}
+	if err := c.BodyParser(&email); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#teamsResult")
+	}
+	invitee, err := app.db.GetUser(email.Email)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#teamsResult")
+	}
+
+	if err := app.db.InviteToTeam(team.Id, invitee.Id, user.Id); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#teamsResult")
+	}
+
+	return app.renderTeams(c)
+}
+
+// joinTeam - функция, добавляющая приглашённого пользователя в команду.
+func (app *App) joinTeam(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while joining the team")
+	user, _ := app.getUser(c, wrapErr)
+
+	teamId, err := strconv.Atoi(c.Params("team"))
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#teamsResult")
+	}
+
+	team, err := app.db.GetTeam(teamId)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#teamsResult")
+	}
+	if err := app.checkTourNotArchived(team.TournamentId); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#teamsResult")
+	}
+
+	if err := app.db.JoinTeam(teamId, user.Id); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#teamsResult")
+	}
+
+	return app.renderTeams(c)
+}
+
+// leaveTeam - функция, удаляющая пользователя из его команды в соревновании.
+func (app *App) leaveTeam(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while leaving the team")
+	user, _ := app.getUser(c, wrapErr)
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#teamsResult")
+	}
+
+	if err := app.checkTourNotArchived(id); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#teamsResult")
+	}
+
+	team, err := app.db.GetUserTeam(id, user.Id)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#teamsResult")
+	}
+
+	if err := app.db.LeaveTeam(team.Id, user.Id); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#teamsResult")
+	}
+
+	return app.renderTeams(c)
+}
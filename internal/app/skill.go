@@ -0,0 +1,93 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"time"
+
+	"github.com/famusovsky/WikiSurfBack/internal/models"
+	"github.com/famusovsky/WikiSurfBack/internal/skill"
+	"github.com/gofiber/fiber/v2"
+)
+
+// updateSkill - функция, проводящая матч рейтинга мастерства, если спринт - новый личный рекорд на маршруте.
+// Вызывается синхронно сразу после сохранения спринта, чтобы матчи проводились в порядке сохранения спринтов.
+func (app *App) updateSkill(sprint models.Sprint) {
+	if !sprint.Success {
+		return
+	}
+	wrapErr := errors.New("error while updating the skill rating")
+
+	app.skillMu.Lock()
+	defer app.skillMu.Unlock()
+
+	history, err := app.db.GetUserRouteHistory(sprint.UserId, sprint.RouteId)
+	if err != nil {
+		app.errLog.Println(errors.Join(wrapErr, err))
+		return
+	}
+	for _, s := range history {
+		if s.Id != sprint.Id && s.Success && s.LengthTime <= sprint.LengthTime {
+			return
+		}
+	}
+
+	bests, err := app.db.GetRouteRatings(sprint.RouteId)
+	if err != nil {
+		app.errLog.Println(errors.Join(wrapErr, err))
+		return
+	}
+	opponents := make(map[int]int64, len(bests))
+	ids := []int{sprint.UserId}
+	for _, b := range bests {
+		if b.UserId != sprint.UserId {
+			opponents[b.UserId] = b.SprintLengthTime
+			ids = append(ids, b.UserId)
+		}
+	}
+
+	current, err := app.db.GetPlayerSkills(ids)
+	if err != nil {
+		app.errLog.Println(errors.Join(wrapErr, err))
+		return
+	}
+	ratings := make(map[int]models.PlayerSkill, len(current))
+	for _, r := range current {
+		ratings[r.UserId] = r
+	}
+
+	if err := app.db.SavePlayerSkills(skill.Play(sprint.UserId, sprint.LengthTime, opponents, ratings, sprint.StartTime)); err != nil {
+		app.errLog.Println(errors.Join(wrapErr, err))
+	}
+}
+
+// renderSkillLadder - функция, рендерящая рейтинг мастерства игроков с учётом неактивности.
+func (app *App) renderSkillLadder(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while getting the skill ladder")
+
+	skills, err := app.db.GetSkillLadder()
+	if err != nil {
+		return app.renderErr(c, fiber.StatusNotFound, errors.Join(wrapErr, err))
+	}
+	ladder := skill.Ladder(skills, time.Now())
+
+	data := make([]struct {
+		models.PlayerSkill
+		Skill string
+	}, len(ladder))
+	for i, s := range ladder {
+		data[i].PlayerSkill = s
+		data[i].Skill = fmt.Sprintf("%.0f", s.Rating)
+	}
+
+	var b bytes.Buffer
+	q := `{{range .}}<tr><td><a href={{printf "/user/%d" .UserId }}>{{.UserName}}</a></td><td>{{.Skill}}</td><td>{{.Games}}</td></tr>{{end}}`
+	t := template.Must(template.New("").Parse(q))
+	if err := t.Execute(&b, data); err != nil {
+		return app.renderErr(c, fiber.StatusInternalServerError, errors.Join(wrapErr, err))
+	}
+
+	return c.SendString(b.String())
+}
@@ -0,0 +1,281 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/famusovsky/WikiSurfBack/internal/mailer"
+	"github.com/famusovsky/WikiSurfBack/internal/models"
+	"github.com/famusovsky/WikiSurfBack/internal/postgres"
+	"github.com/famusovsky/WikiSurfBack/internal/tourspec"
+	"github.com/gofiber/fiber/v2"
+)
+
+// templateLead - время до начала, за которое по повторяющемуся шаблону создаётся соревнование.
+const templateLead = 24 * time.Hour
+
+// getCreatorSpec - функция, получающая настройку соревнования, доступную только его создателям.
+func (app *App) getCreatorSpec(c *fiber.Ctx, userId int) (int, tourspec.Spec, error) {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return 0, tourspec.Spec{}, err
+	}
+	if ok, err := app.db.CheckTournamentCreator(id, userId); !ok || err != nil {
+		return 0, tourspec.Spec{}, errors.Join(errors.New("only the tour creators can copy it"), err)
+	}
+
+	spec, err := tourspec.Export(app.db, id)
+	return id, spec, err
+}
+
+// cloneTour - создание копии соревнования с тем же набором маршрутов, создателей и настроек.
+func (app *App) cloneTour(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while cloning the tour")
+	user, _ := app.getUser(c, wrapErr)
+
+	id, spec, err := app.getCreatorSpec(c, user.Id)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#templateResult")
+	}
+
+	start := time.Now()
+	if t, err := time.Parse("2006-01-02T15:04:00Z", c.FormValue("begin")+":00Z"); err == nil && !t.IsZero() {
+		start = t
+	}
+
+	cloneId, err := tourspec.Import(app.db, spec.Shift(start), user.Id)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#templateResult")
+	}
+	app.infoLog.Printf("tournament #%d cloned to #%d by user #%d\n", id, cloneId, user.Id)
+
+	c.Set("HX-Location", fmt.Sprintf("/tournament/edit/%d", cloneId))
+	return c.SendString("OK")
+}
+
+// saveTourTemplate - сохранение соревнования как шаблона, при необходимости повторяющегося.
+func (app *App) saveTourTemplate(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while saving the tour template")
+	user, _ := app.getUser(c, wrapErr)
+
+	id, spec, err := app.getCreatorSpec(c, user.Id)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#templateResult")
+	}
+
+	name := strings.TrimSpace(c.FormValue("name"))
+	if name == "" {
+		name = fmt.Sprintf("Tournament #%d", id)
+	}
+	recurrence := c.FormValue("recurrence")
+	if err := tourspec.CheckRecurrence(recurrence); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#templateResult")
+	}
+
+	var b bytes.Buffer
+	if err := tourspec.Encode(&b, spec, "json"); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#templateResult")
+	}
+
+	now := time.Now()
+	tmpl := models.TourTemplate{
+		Name:       name,
+		OwnerId:    user.Id,
+		Spec:       b.String(),
+		Recurrence: recurrence,
+		CreatedAt:  now,
+	}
+	if recurrence != tourspec.Once {
+		next := tourspec.Next(spec.StartTime, recurrence, now)
+		tmpl.NextStart = &next
+	}
+
+	tmplId, err := app.db.AddTourTemplate(tmpl)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#templateResult")
+	}
+	app.infoLog.Printf("tournament #%d saved as template #%d by user #%d\n", id, tmplId, user.Id)
+
+	if tmpl.NextStart != nil {
+		return c.SendString(fmt.Sprintf("Template %q saved, the next tour starts %s", name, tmpl.NextStart.Format("2006-01-02 15:04")))
+	}
+	return c.SendString(fmt.Sprintf("Template %q saved", name))
+}
+
+// renderTourTemplates - функция производящая рендер списка шаблонов соревнований пользователя.
+func (app *App) renderTourTemplates(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while getting tour templates")
+	user, _ := app.getUser(c, wrapErr)
+
+	templates, err := app.db.GetUserTourTemplates(user.Id)
+	if err != nil {
+		return app.renderErr(c, fiber.StatusNotFound, errors.Join(wrapErr, err))
+	}
+
+	var b bytes.Buffer
+	q := `{{range .}}<tr><td>{{.Name}}{{if .Recurrence}} ({{.Recurrence}}, next {{.NextStart.Format "2006-01-02 15:04"}}){{end}}
+	<button hx-post={{printf "/service/template/%d/create" .Id }} hx-target="body">Create now</button>
+	<button hx-delete={{printf "/service/template/%d" .Id }} hx-confirm="Are you sure?" hx-target="#list">Delete</button></td></tr>{{end}}`
+	t := template.Must(template.New("").Parse(q))
+	if err := t.Execute(&b, templates); err != nil {
+		return app.renderErr(c, fiber.StatusInternalServerError, errors.Join(wrapErr, err))
+	}
+
+	return c.Render("partials/tourList", fiber.Map{
+		"name":  "My templates",
+		"tbody": b.String(),
+	})
+}
+
+// createFromTemplate - создание соревнования по шаблону, начинающегося сейчас.
+func (app *App) createFromTemplate(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while creating the tour from the template")
+	user, _ := app.getUser(c, wrapErr)
+
+	tmpl, err := app.getOwnTemplate(c, user.Id)
+	if err != nil {
+		return app.renderErr(c, fiber.StatusNotFound, errors.Join(wrapErr, err))
+	}
+
+	id, err := app.createTemplateTour(app.db, tmpl, time.Now())
+	if err != nil {
+		return app.renderErr(c, fiber.StatusBadRequest, errors.Join(wrapErr, err))
+	}
+
+	c.Set("HX-Location", fmt.Sprintf("/tournament/edit/%d", id))
+	return c.SendString("OK")
+}
+
+// deleteTourTemplate - удаление шаблона соревнования.
+func (app *App) deleteTourTemplate(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while deleting the tour template")
+	user, _ := app.getUser(c, wrapErr)
+
+	tmpl, err := app.getOwnTemplate(c, user.Id)
+	if err != nil {
+		return app.renderErr(c, fiber.StatusNotFound, errors.Join(wrapErr, err))
+	}
+	if err := app.db.DeleteTourTemplate(tmpl.Id, user.Id); err != nil {
+		return app.renderErr(c, fiber.StatusInternalServerError, errors.Join(wrapErr, err))
+	}
+
+	return app.renderTourTemplates(c)
+}
+
+// getOwnTemplate - функция, получающая шаблон соревнования из параметров запроса и проверяющая владельца.
+func (app *App) getOwnTemplate(c *fiber.Ctx, userId int) (models.TourTemplate, error) {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return models.TourTemplate{}, err
+	}
+
+	tmpl, err := app.db.GetTourTemplate(id)
+	if err != nil {
+		return models.TourTemplate{}, err
+	}
+	if tmpl.OwnerId != userId {
+		return models.TourTemplate{}, errors.New("the template belongs to another user")
+	}
+
+	return tmpl, nil
+}
+
+// templateStore - хранилище для импорта соревнования по повторяющемуся шаблону,
+// сдвигающее время следующего запуска шаблона в одной транзакции с созданием соревнования.
+type templateStore struct {
+	postgres.DbHandler
+	templateId int
+	next       time.Time
+}
+
+// ImportTournament - функция, создающая соревнование и сдвигающая время следующего запуска шаблона.
+func (s templateStore) ImportTournament(tour models.Tournament, routes []models.Route, creators []int, userId int) (int, error) {
+	return s.ImportTemplateTournament(s.templateId, s.next, tour, routes, creators, userId)
+}
+
+// createTemplateTour - функция, создающая соревнование по шаблону с заданным временем начала.
+func (app *App) createTemplateTour(store tourspec.Store, tmpl models.TourTemplate, start time.Time) (int, error) {
+	spec, err := tourspec.Parse([]byte(tmpl.Spec), "json")
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := tourspec.Import(store, spec.Shift(start), tmpl.OwnerId)
+	if err != nil {
+		return 0, err
+	}
+	app.infoLog.Printf("tournament #%d created from template #%d\n", id, tmpl.Id)
+
+	return id, nil
+}
+
+// createRecurringTours - функция, создающая соревнования по повторяющимся шаблонам незадолго до их начала.
+func (app *App) createRecurringTours() {
+	wrapErr := errors.New("error while creating recurring tournaments")
+
+	now := time.Now()
+	templates, err := app.db.GetDueTourTemplates(now.Add(templateLead))
+	if err != nil {
+		app.errLog.Println(errors.Join(wrapErr, err))
+		return
+	}
+
+	for _, tmpl := range templates {
+		spec, err := tourspec.Parse([]byte(tmpl.Spec), "json")
+		if err != nil {
+			app.errLog.Println(errors.Join(wrapErr, err))
+			continue
+		}
+
+		next := *tmpl.NextStart
+		// Пропущенные за время простоя запуски не создаются задним числом.
+		if !next.After(now) {
+			if err := app.db.SetTemplateNextStart(tmpl.Id, tourspec.Next(spec.StartTime, tmpl.Recurrence, now)); err != nil {
+				app.errLog.Println(errors.Join(wrapErr, err))
+			}
+			continue
+		}
+
+		// Время следующего запуска сдвигается в одной транзакции с созданием соревнования, чтобы следующий проход не создал его копию.
+		store := templateStore{app.db, tmpl.Id, tourspec.Next(spec.StartTime, tmpl.Recurrence, next)}
+		if _, err := app.createTemplateTour(store, tmpl, next); err != nil {
+			if errors.Is(err, tourspec.ErrUnknownCreator) {
+				app.disableTemplate(tmpl, err)
+			} else {
+				// Запуск повторяется при следующих проходах планировщика, пока не наступит его время.
+				app.errLog.Println(errors.Join(wrapErr, err))
+			}
+		}
+	}
+}
+
+// disableTemplate - функция, отключающая повторение шаблона, по которому нельзя создать соревнование, и сообщающая об этом владельцу.
+func (app *App) disableTemplate(tmpl models.TourTemplate, cause error) {
+	wrapErr := fmt.Errorf("error while disabling tournament template #%d", tmpl.Id)
+
+	if err := app.db.DisableTemplateRecurrence(tmpl.Id); err != nil {
+		app.errLog.Println(errors.Join(wrapErr, err))
+		return
+	}
+	app.infoLog.Printf("recurrence of tournament template #%d disabled: %v\n", tmpl.Id, cause)
+
+	owner, err := app.db.GetUserById(tmpl.OwnerId)
+	if err != nil {
+		app.errLog.Println(errors.Join(wrapErr, err))
+		return
+	}
+	if err := app.mail.Send(mailer.Message{
+		To:      owner.Email,
+		Subject: "Your WikiSurf tournament template is paused",
+		Body: fmt.Sprintf("Hi, %s!\n\nThe next tournament of the template %q could not be created: %v.\n"+
+			"The template will not repeat any more. Update the co-creators of the tournament and save it as a template again:\n%s",
+			owner.Name, tmpl.Name, cause, app.baseUrl+"/tournaments"),
+	}); err != nil {
+		app.errLog.Println(errors.Join(wrapErr, err))
+	}
+}
@@ -0,0 +1,260 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/famusovsky/WikiSurfBack/internal/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// apiTokenPrefix - префикс персональных токенов, позволяющий узнать их в логах и конфигурациях.
+const apiTokenPrefix = "wst_"
+
+// bearerToken - функция, получающая токен из заголовка Authorization.
+func bearerToken(c *fiber.Ctx) (string, bool) {
+	header := c.Get(fiber.HeaderAuthorization)
+	if header == "" {
+		return "", false
+	}
+
+	scheme, token, _ := strings.Cut(header, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", true
+	}
+
+	return strings.TrimSpace(token), true
+}
+
+// authByToken - функция, авторизующая пользователя по персональному токену из заголовка Authorization.
+// Возвращает false, если заголовка нет, и ошибку, если токен не подходит.
+func (app *App) authByToken(c *fiber.Ctx) (bool, error) {
+	if _, ok := c.Locals("scopes").([]string); ok {
+		return true, nil
+	}
+
+	token, ok := bearerToken(c)
+	if !ok {
+		return false, nil
+	}
+	if token == "" {
+		return true, errors.New("the authorization header must hold a bearer token")
+	}
+
+	apiToken, err := app.db.UseApiToken(hashToken(token))
+	if err != nil {
+		return true, errors.New("the api token is invalid or revoked")
+	}
+
+	user, err := app.db.GetUserById(apiToken.UserId)
+	if err != nil {
+		return true, errors.Join(errors.New("error while getting the token's owner"), err)
+	}
+
+	c.Locals("user", user)
+	c.Locals("scopes", strings.Fields(apiToken.Scopes))
+	return true, nil
+}
+
+// checkApi - middleware, проверяющий авторизацию пользователя в API по персональному токену или cookie.
+func (app *App) checkApi(c *fiber.Ctx) error {
+	if used, err := app.authByToken(c); used {
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Next()
+	}
+
+	if _, ok := app.getUser(c, errors.New("error while checking authorization")); !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "authorization is required"})
+	}
+
+	return c.Next()
+}
+
+// requireScope - middleware, проверяющий наличие области доступа у персонального токена.
+// Запросы, авторизованные через cookie, имеют все области доступа.
+func (app *App) requireScope(scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		scopes, ok := c.Locals("scopes").([]string)
+		if ok && !slices.Contains(scopes, scope) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": fmt.Sprintf("the api token lacks the %q scope", scope)})
+		}
+
+		return c.Next()
+	}
+}
+
+// getApiUser - получение данных пользователя через API.
+func (app *App) getApiUser(c *fiber.Ctx) error {
+	user, _ := app.getUser(c, errors.New("error while getting the user"))
+
+	return c.JSON(fiber.Map{
+		"id":       user.Id,
+		"name":     user.Name,
+		"email":    user.Email,
+		"verified": user.Verified,
+	})
+}
+
+// getApiSprints - получение истории спринтов пользователя через API.
+func (app *App) getApiSprints(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while getting the sprints")
+	user, _ := app.getUser(c, wrapErr)
+
+	sprints, err := app.db.GetUserHistory(user.Id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": errors.Join(wrapErr, err).Error()})
+	}
+
+	return c.JSON(sprints)
+}
+
+// getApiTours - получение соревнований, в которых участвует пользователь, через API.
+func (app *App) getApiTours(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while getting the tours")
+	user, _ := app.getUser(c, wrapErr)
+
+	tours, err := app.db.GetUserTournaments(user.Id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": errors.Join(wrapErr, err).Error()})
+	}
+
+	return c.JSON(tours)
+}
+
+// getApiRouteRating - получение рейтинга по маршруту через API.
+func (app *App) getApiRouteRating(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while getting the route rating")
+
+	id, err := strconv.Atoi(c.Params("route"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": errors.Join(wrapErr, err).Error()})
+	}
+
+	ratings, err := app.db.GetRouteRatings(id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": errors.Join(wrapErr, err).Error()})
+	}
+
+	return c.JSON(ratings)
+}
+
+// addApiSprint - сохранение пройденного спринта через API.
+func (app *App) addApiSprint(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while adding a sprint")
+	user, _ := app.getUser(c, wrapErr)
+
+	sprint := models.Sprint{}
+	if err := c.BodyParser(&sprint); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": errors.Join(wrapErr, err).Error()})
+	}
+	if err := checkSprintSteps(sprint); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": errors.Join(wrapErr, err).Error()})
+	}
+	sprint.UserId = user.Id
+
+	id, err := app.db.AddSprint(sprint)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": errors.Join(wrapErr, err).Error()})
+	}
+	app.races.Finish(user.Id, sprint.RouteId, id, sprint.LengthTime, sprint.Success)
+	sprint.Id = id
+	res := fiber.Map{
+		"id":  id,
+		"url": fmt.Sprintf("%s/sprint/%d", app.baseUrl, id),
+	}
+	if ghostId := getGhostSprintId(c); ghostId != 0 {
+		if result, err := app.recordGhostResult(sprint, ghostId); err != nil {
+			app.errLog.Println(errors.Join(errors.New("error while recording the ghost result"), err))
+		} else {
+			res["ghost"] = result
+		}
+	}
+	app.updateSkill(sprint)
+	go app.awardAchievements(user.Id)
+
+	return c.Status(fiber.StatusCreated).JSON(res)
+}
+
+// createApiToken - создание персонального токена, токен показывается пользователю один раз.
+func (app *App) createApiToken(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while creating the api token")
+	user, _ := app.getUser(c, wrapErr)
+
+	form := struct {
+		Name   string
+		Scopes []string
+	}{}
+	if err := c.BodyParser(&form); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#tokenResult")
+	}
+
+	form.Name = strings.TrimSpace(form.Name)
+	if form.Name == "" {
+		return app.errToResult(c, errors.Join(wrapErr, errors.New("the token needs a name")), "#tokenResult")
+	}
+	if len(form.Scopes) == 0 {
+		return app.errToResult(c, errors.Join(wrapErr, errors.New("choose at least one scope")), "#tokenResult")
+	}
+	for _, scope := range form.Scopes {
+		if !slices.Contains(models.Scopes, scope) {
+			return app.errToResult(c, errors.Join(wrapErr, fmt.Errorf("unknown scope %q", scope)), "#tokenResult")
+		}
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#tokenResult")
+	}
+	token = apiTokenPrefix + token
+
+	if _, err := app.db.AddApiToken(models.ApiToken{
+		UserId:    user.Id,
+		Name:      form.Name,
+		TokenHash: hashToken(token),
+		Scopes:    strings.Join(form.Scopes, " "),
+		CreatedAt: time.Now(),
+	}); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#tokenResult")
+	}
+
+	c.Set("HX-Trigger", "tokensChanged")
+	return c.SendString(fmt.Sprintf("Your new token: %s (copy it now, it will not be shown again)", token))
+}
+
+// renderApiTokens - рендер таблицы персональных токенов пользователя.
+func (app *App) renderApiTokens(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while getting the api tokens")
+	user, _ := app.getUser(c, wrapErr)
+
+	tokens, err := app.db.GetUserApiTokens(user.Id)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#tokenResult")
+	}
+
+	return c.Render("partials/apiTokens", fiber.Map{
+		"tokens": tokens,
+	})
+}
+
+// revokeApiToken - отзыв персонального токена пользователя.
+func (app *App) revokeApiToken(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while revoking the api token")
+	user, _ := app.getUser(c, wrapErr)
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#tokenResult")
+	}
+
+	if err := app.db.DeleteApiToken(id, user.Id); err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#tokenResult")
+	}
+
+	return app.renderApiTokens(c)
+}
@@ -0,0 +1,92 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/famusovsky/WikiSurfBack/internal/tourspec"
+	"github.com/gofiber/fiber/v2"
+)
+
+// importTour - создание соревнования из файла настройки в формате JSON, YAML или CSV.
+func (app *App) importTour(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while importing the tour")
+	user, _ := app.getUser(c, wrapErr)
+
+	var (
+		data   []byte
+		format string
+	)
+	if file, err := c.FormFile("spec"); err == nil {
+		if format, err = tourspec.FormatOf(file.Filename, file.Header.Get(fiber.HeaderContentType)); err != nil {
+			return app.errToResult(c, errors.Join(wrapErr, err), "#importResult")
+		}
+		f, err := file.Open()
+		if err != nil {
+			return app.errToResult(c, errors.Join(wrapErr, err), "#importResult")
+		}
+		defer f.Close()
+		if data, err = io.ReadAll(f); err != nil {
+			return app.errToResult(c, errors.Join(wrapErr, err), "#importResult")
+		}
+	} else {
+		if format, err = tourspec.FormatOf("", c.Get(fiber.HeaderContentType)); err != nil {
+			return app.errToResult(c, errors.Join(wrapErr, err), "#importResult")
+		}
+		data = c.Body()
+	}
+
+	spec, err := tourspec.Parse(data, format)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#importResult")
+	}
+
+	id, err := tourspec.Import(app.db, spec, user.Id)
+	if err != nil {
+		return app.errToResult(c, errors.Join(wrapErr, err), "#importResult")
+	}
+	app.infoLog.Printf("tournament #%d imported with %d routes by user #%d\n", id, len(spec.Routes), user.Id)
+
+	c.Set("HX-Location", fmt.Sprintf("/tournament/edit/%d", id))
+	return c.SendString("OK")
+}
+
+// exportTour - выгрузка настройки соревнования, по которой его можно воссоздать импортом.
+func (app *App) exportTour(c *fiber.Ctx) error {
+	wrapErr := errors.New("error while exporting the tour")
+	user, _ := app.getUser(c, wrapErr)
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(errors.Join(wrapErr, err).Error())
+	}
+	if ok, err := app.db.CheckTournamentCreator(id, user.Id); !ok || err != nil {
+		return c.Status(fiber.StatusForbidden).SendString(errors.Join(wrapErr, errors.New("only the tour creators can export it"), err).Error())
+	}
+
+	format := c.Query("format", "json")
+	if format != "json" && format != "yaml" && format != "csv" {
+		return c.Status(fiber.StatusBadRequest).SendString(errors.Join(wrapErr, fmt.Errorf("unknown format %q, use json, yaml or csv", format)).Error())
+	}
+
+	spec, err := tourspec.Export(app.db, id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).SendString(errors.Join(wrapErr, err).Error())
+	}
+
+	var b bytes.Buffer
+	if err := tourspec.Encode(&b, spec, format); err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString(errors.Join(wrapErr, err).Error())
+	}
+
+	contentType := exportContentTypes[format]
+	if format == "yaml" {
+		contentType = "application/yaml; charset=utf-8"
+	}
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="tournament-%d.%s"`, id, format))
+	return c.Send(b.Bytes())
+}
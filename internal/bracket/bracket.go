@@ -0,0 +1,283 @@
+// Пакет для построения сеток соревнований на выбывание.
+package bracket
+
+import (
+	"errors"
+	"time"
+
+	"github.com/famusovsky/WikiSurfBack/internal/models"
+)
+
+var (
+	ErrNoMatch      = errors.New("bracket match not found")
+	ErrNotReady     = errors.New("bracket match participants are not determined yet")
+	ErrResolved     = errors.New("bracket match is already resolved")
+	ErrNotAPlayer   = errors.New("user does not play in the bracket match")
+	ErrTooFew       = errors.New("at least two participants are required")
+	ErrTooFewDouble = errors.New("at least three participants are required for double elimination")
+)
+
+// none - отсутствие следующего матча.
+const none = -1
+
+// Single - функция, строящая сетку на выбывание после первого поражения.
+//
+// Принимает: id участников в порядке посева (первый - сильнейший).
+//
+// Возвращает: матчи сетки с уже разыгранными проходами без соперника.
+func Single(seeds []int) ([]models.BracketMatch, error) {
+	if len(seeds) < 2 {
+		return nil, ErrTooFew
+	}
+
+	matches, _ := winnersSide(seeds)
+	settleAll(matches)
+
+	return matches, nil
+}
+
+// Double - функция, строящая сетку на выбывание после второго поражения.
+// Победители верхней и нижней сеток встречаются в гранд-финале из одного матча.
+// Для двух участников нижней сетки не получается, поэтому нужно не меньше трёх.
+//
+// Принимает: id участников в порядке посева (первый - сильнейший).
+//
+// Возвращает: матчи сетки с уже разыгранными проходами без соперника.
+func Double(seeds []int) ([]models.BracketMatch, error) {
+	if len(seeds) < 3 {
+		return nil, ErrTooFewDouble
+	}
+
+	matches, rounds := winnersSide(seeds)
+
+	add := func(side string, round int) int {
+		matches = append(matches, models.BracketMatch{
+			Num:      len(matches),
+			Side:     side,
+			Round:    round,
+			NextWin:  none,
+			NextLose: none,
+		})
+		return len(matches) - 1
+	}
+
+	// Первый раунд нижней сетки: проигравшие первого раунда верхней.
+	var prev []int
+	for i := 0; i < len(rounds[0])/2; i++ {
+		num := add(models.BracketLosers, 1)
+		link(matches, rounds[0][2*i], num, 0, true)
+		link(matches, rounds[0][2*i+1], num, 1, true)
+		prev = append(prev, num)
+	}
+
+	round := 1
+	for j := 1; j < len(rounds); j++ {
+		// Чётный раунд: победители нижней сетки против проигравших раунда j+1 верхней.
+		round++
+		wb := rounds[j]
+		cur := make([]int, 0, len(prev))
+		for i := range prev {
+			num := add(models.BracketLosers, round)
+			link(matches, prev[i], num, 0, false)
+			link(matches, wb[len(wb)-1-i], num, 1, true)
+			cur = append(cur, num)
+		}
+		prev = cur
+
+		if len(prev) < 2 {
+			continue
+		}
+
+		// Нечётный раунд: победители нижней сетки играют между собой.
+		round++
+		cur = make([]int, 0, len(prev)/2)
+		for i := 0; i < len(prev)/2; i++ {
+			num := add(models.BracketLosers, round)
+			link(matches, prev[2*i], num, 0, false)
+			link(matches, prev[2*i+1], num, 1, false)
+			cur = append(cur, num)
+		}
+		prev = cur
+	}
+
+	final := add(models.BracketFinal, 1)
+	wbFinal := rounds[len(rounds)-1][0]
+	link(matches, wbFinal, final, 0, false)
+	link(matches, prev[0], final, 1, false)
+
+	settleAll(matches)
+
+	return matches, nil
+}
+
+// SetWinner - функция, фиксирующая победителя матча и продвигающая участников по сетке.
+func SetWinner(matches []models.BracketMatch, num, winner int) error {
+	if num < 0 || num >= len(matches) {
+		return ErrNoMatch
+	}
+
+	m := &matches[num]
+	if m.Winner != models.SlotPending {
+		return ErrResolved
+	}
+	if m.Player1 == models.SlotPending || m.Player2 == models.SlotPending {
+		return ErrNotReady
+	}
+	if winner != m.Player1 && winner != m.Player2 {
+		return ErrNotAPlayer
+	}
+
+	resolve(matches, num, winner)
+
+	return nil
+}
+
+// MarkReady - функция, отмечающая временем at матчи, в которых только что определились оба участника.
+// От этого времени считаются спринты, по которым разыгрывается матч.
+func MarkReady(matches []models.BracketMatch, at time.Time) {
+	for i := range matches {
+		m := &matches[i]
+		if m.ReadyAt == nil && m.Player1 > 0 && m.Player2 > 0 {
+			m.ReadyAt = &at
+		}
+	}
+}
+
+// Loser - функция, возвращающая проигравшего в разыгранном матче.
+func Loser(m models.BracketMatch) int {
+	switch m.Winner {
+	case models.SlotPending:
+		return models.SlotPending
+	case m.Player1:
+		return m.Player2
+	default:
+		return m.Player1
+	}
+}
+
+// Champion - функция, возвращающая победителя сетки или SlotPending, если он ещё не определён.
+func Champion(matches []models.BracketMatch) int {
+	for _, m := range matches {
+		if m.NextWin == none {
+			if m.Winner > 0 {
+				return m.Winner
+			}
+			return models.SlotPending
+		}
+	}
+	return models.SlotPending
+}
+
+// winnersSide - функция, строящая верхнюю сетку по посеву.
+//
+// Возвращает: матчи и номера матчей по раундам.
+func winnersSide(seeds []int) ([]models.BracketMatch, [][]int) {
+	size := 2
+	for size < len(seeds) {
+		size *= 2
+	}
+
+	order := []int{1, 2}
+	for len(order) < size {
+		next := make([]int, 0, len(order)*2)
+		for _, s := range order {
+			next = append(next, s, len(order)*2+1-s)
+		}
+		order = next
+	}
+
+	player := func(seed int) int {
+		if seed > len(seeds) {
+			return models.SlotEmpty
+		}
+		return seeds[seed-1]
+	}
+
+	var (
+		matches []models.BracketMatch
+		rounds  [][]int
+	)
+	for n, round := size/2, 1; n >= 1; n, round = n/2, round+1 {
+		nums := make([]int, n)
+		for i := 0; i < n; i++ {
+			m := models.BracketMatch{
+				Num:      len(matches),
+				Side:     models.BracketWinners,
+				Round:    round,
+				NextWin:  none,
+				NextLose: none,
+			}
+			if round == 1 {
+				m.Player1 = player(order[2*i])
+				m.Player2 = player(order[2*i+1])
+			}
+			matches = append(matches, m)
+			nums[i] = m.Num
+		}
+
+		if len(rounds) > 0 {
+			for i, num := range rounds[len(rounds)-1] {
+				link(matches, num, nums[i/2], i%2, false)
+			}
+		}
+		rounds = append(rounds, nums)
+	}
+
+	return matches, rounds
+}
+
+// link - функция, связывающая исход матча from со слотом матча to.
+func link(matches []models.BracketMatch, from, to, slot int, loser bool) {
+	if loser {
+		matches[from].NextLose, matches[from].NextLoseSlot = to, slot
+	} else {
+		matches[from].NextWin, matches[from].NextWinSlot = to, slot
+	}
+}
+
+// settleAll - функция, разыгрывающая все матчи, в которых хотя бы один слот пуст.
+func settleAll(matches []models.BracketMatch) {
+	for i := range matches {
+		settle(matches, i)
+	}
+}
+
+// settle - функция, разыгрывающая матч автоматически, если его участники определены и хотя бы один слот пуст.
+func settle(matches []models.BracketMatch, num int) {
+	m := &matches[num]
+	if m.Winner != models.SlotPending || m.Player1 == models.SlotPending || m.Player2 == models.SlotPending {
+		return
+	}
+
+	switch {
+	case m.Player1 == models.SlotEmpty:
+		resolve(matches, num, m.Player2)
+	case m.Player2 == models.SlotEmpty:
+		resolve(matches, num, m.Player1)
+	}
+}
+
+// resolve - функция, фиксирующая победителя матча и передающая участников в следующие матчи.
+func resolve(matches []models.BracketMatch, num, winner int) {
+	m := &matches[num]
+	m.Winner = winner
+
+	place := func(to, slot, player int) {
+		if to == none {
+			return
+		}
+		if slot == 0 {
+			matches[to].Player1 = player
+		} else {
+			matches[to].Player2 = player
+		}
+		settle(matches, to)
+	}
+
+	loser := Loser(*m)
+	if winner == models.SlotEmpty {
+		loser = models.SlotEmpty
+	}
+	place(m.NextWin, m.NextWinSlot, winner)
+	place(m.NextLose, m.NextLoseSlot, loser)
+}
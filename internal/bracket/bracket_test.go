@@ -0,0 +1,225 @@
+package bracket
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/famusovsky/WikiSurfBack/internal/models"
+)
+
+// seeds - функция, возвращающая id участников 1..n в порядке посева.
+func seeds(n int) []int {
+	res := make([]int, n)
+	for i := range res {
+		res[i] = i + 1
+	}
+	return res
+}
+
+// count - функция, считающая матчи стороны сетки.
+func count(matches []models.BracketMatch, side string) int {
+	n := 0
+	for _, m := range matches {
+		if m.Side == side {
+			n++
+		}
+	}
+	return n
+}
+
+// playOut - функция, разыгрывающая все готовые матчи, в которых побеждает участник с меньшим id (лучший посев).
+func playOut(t *testing.T, matches []models.BracketMatch) {
+	t.Helper()
+	for changed := true; changed; {
+		changed = false
+		for _, m := range matches {
+			if m.Winner != models.SlotPending || m.Player1 <= 0 || m.Player2 <= 0 {
+				continue
+			}
+			if err := SetWinner(matches, m.Num, min(m.Player1, m.Player2)); err != nil {
+				t.Fatalf("match %d: %v", m.Num, err)
+			}
+			changed = true
+		}
+	}
+}
+
+func TestSingleSeeding(t *testing.T) {
+	tests := []struct {
+		name    string
+		players int
+		total   int
+		first   [][2]int // first - пары первого раунда.
+	}{
+		{name: "two players", players: 2, total: 1, first: [][2]int{{1, 2}}},
+		{name: "four players", players: 4, total: 3, first: [][2]int{{1, 4}, {2, 3}}},
+		{name: "eight players", players: 8, total: 7, first: [][2]int{{1, 8}, {4, 5}, {2, 7}, {3, 6}}},
+		{name: "byes go to the best seeds", players: 5, total: 7, first: [][2]int{{1, models.SlotEmpty}, {4, 5}, {2, models.SlotEmpty}, {3, models.SlotEmpty}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := Single(seeds(tt.players))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(matches) != tt.total {
+				t.Fatalf("got %d matches, want %d", len(matches), tt.total)
+			}
+			for i, pair := range tt.first {
+				m := matches[i]
+				if m.Round != 1 || m.Player1 != pair[0] || m.Player2 != pair[1] {
+					t.Errorf("match %d: got round %d %d vs %d, want round 1 %d vs %d", i, m.Round, m.Player1, m.Player2, pair[0], pair[1])
+				}
+				if pair[1] == models.SlotEmpty && m.Winner != pair[0] {
+					t.Errorf("match %d: a bye must be won by %d, got %d", i, pair[0], m.Winner)
+				}
+			}
+		})
+	}
+}
+
+func TestSingleAdvancement(t *testing.T) {
+	matches, err := Single(seeds(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetWinner(matches, 2, 1); !errors.Is(err, ErrNotReady) {
+		t.Fatalf("final before the semi-finals: got %v, want %v", err, ErrNotReady)
+	}
+	if err := SetWinner(matches, 0, 3); !errors.Is(err, ErrNotAPlayer) {
+		t.Fatalf("winner from another match: got %v, want %v", err, ErrNotAPlayer)
+	}
+	if err := SetWinner(matches, 5, 1); !errors.Is(err, ErrNoMatch) {
+		t.Fatalf("unknown match: got %v, want %v", err, ErrNoMatch)
+	}
+
+	if err := SetWinner(matches, 0, 4); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetWinner(matches, 0, 1); !errors.Is(err, ErrResolved) {
+		t.Fatalf("second result: got %v, want %v", err, ErrResolved)
+	}
+	if err := SetWinner(matches, 1, 2); err != nil {
+		t.Fatal(err)
+	}
+	if f := matches[2]; f.Player1 != 4 || f.Player2 != 2 {
+		t.Fatalf("final: got %d vs %d, want 4 vs 2", f.Player1, f.Player2)
+	}
+	if Champion(matches) != models.SlotPending {
+		t.Fatal("the champion must be pending before the final")
+	}
+
+	if err := SetWinner(matches, 2, 4); err != nil {
+		t.Fatal(err)
+	}
+	if got := Champion(matches); got != 4 {
+		t.Fatalf("champion: got %d, want 4", got)
+	}
+	if got := Loser(matches[2]); got != 2 {
+		t.Fatalf("final loser: got %d, want 2", got)
+	}
+}
+
+func TestDouble(t *testing.T) {
+	tests := []struct {
+		name    string
+		players int
+		err     error
+		losers  int
+	}{
+		{name: "one player", players: 1, err: ErrTooFewDouble},
+		{name: "two players", players: 2, err: ErrTooFewDouble},
+		{name: "three players", players: 3, losers: 2},
+		{name: "four players", players: 4, losers: 2},
+		{name: "eight players", players: 8, losers: 6},
+		{name: "six players", players: 6, losers: 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := Double(seeds(tt.players))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+
+			if got := count(matches, models.BracketLosers); got != tt.losers {
+				t.Errorf("got %d lower bracket matches, want %d", got, tt.losers)
+			}
+			if got := count(matches, models.BracketFinal); got != 1 {
+				t.Errorf("got %d grand finals, want 1", got)
+			}
+
+			playOut(t, matches)
+			if got := Champion(matches); got != 1 {
+				t.Fatalf("champion: got %d, want the top seed", got)
+			}
+
+			// Каждый участник, кроме победителя, выбывает после двух поражений или проигрыша в гранд-финале.
+			losses := map[int]int{}
+			for _, m := range matches {
+				if l := Loser(m); l > 0 {
+					losses[l]++
+				}
+			}
+			final := matches[len(matches)-1]
+			for _, p := range seeds(tt.players)[1:] {
+				if losses[p] != 2 && !(p == Loser(final) && losses[p] == 1) {
+					t.Errorf("player %d lost %d times", p, losses[p])
+				}
+			}
+		})
+	}
+}
+
+func TestSingleTooFew(t *testing.T) {
+	for _, n := range []int{0, 1} {
+		if _, err := Single(seeds(n)); !errors.Is(err, ErrTooFew) {
+			t.Errorf("%d players: got %v, want %v", n, err, ErrTooFew)
+		}
+	}
+}
+
+func TestMarkReady(t *testing.T) {
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	matches, err := Single(seeds(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	MarkReady(matches, created)
+
+	for _, m := range matches[:2] {
+		if m.ReadyAt == nil || !m.ReadyAt.Equal(created) {
+			t.Fatalf("first round match %d must be ready at creation, got %v", m.Num, m.ReadyAt)
+		}
+	}
+	if matches[2].ReadyAt != nil {
+		t.Fatal("the final must not be ready before the semi-finals")
+	}
+
+	later := created.Add(time.Hour)
+	if err := SetWinner(matches, 0, 1); err != nil {
+		t.Fatal(err)
+	}
+	MarkReady(matches, later)
+	if matches[2].ReadyAt != nil {
+		t.Fatal("the final must wait for both semi-finals")
+	}
+
+	last := created.Add(2 * time.Hour)
+	if err := SetWinner(matches, 1, 2); err != nil {
+		t.Fatal(err)
+	}
+	MarkReady(matches, last)
+	if r := matches[2].ReadyAt; r == nil || !r.Equal(last) {
+		t.Fatalf("final: got ready at %v, want %v", r, last)
+	}
+	if !matches[0].ReadyAt.Equal(created) {
+		t.Fatal("MarkReady must not move the ready time of earlier matches")
+	}
+}
@@ -0,0 +1,136 @@
+// Package mailer - пакет для отправки писем пользователям.
+package mailer
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Message - структура, описывающая письмо.
+type Message struct {
+	To      string // To - адрес получателя.
+	Subject string // Subject - тема письма.
+	Body    string // Body - текст письма.
+}
+
+// Mailer - интерфейс отправки писем.
+type Mailer interface {
+	Send(msg Message) error // Send - отправка письма.
+}
+
+// SMTPMailer - отправка писем через SMTP сервер.
+type SMTPMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// NewSMTP - функция, создающая SMTPMailer.
+// Если user пуст, письма отправляются без авторизации.
+func NewSMTP(host, port, user, password, from string) *SMTPMailer {
+	var auth smtp.Auth
+	if user != "" {
+		auth = smtp.PlainAuth("", user, password, host)
+	}
+
+	return &SMTPMailer{
+		addr: net.JoinHostPort(host, port),
+		from: from,
+		auth: auth,
+	}
+}
+
+// Send implements Mailer.
+func (m *SMTPMailer) Send(msg Message) error {
+	return smtp.SendMail(m.addr, m.auth, m.from, []string{msg.To}, format(m.from, msg))
+}
+
+// LogMailer - вывод писем в лог вместо отправки, для локальной разработки.
+type LogMailer struct {
+	log *log.Logger
+}
+
+// NewLog - функция, создающая LogMailer.
+func NewLog(logger *log.Logger) *LogMailer {
+	return &LogMailer{log: logger}
+}
+
+// Send implements Mailer.
+func (m *LogMailer) Send(msg Message) error {
+	m.log.Printf("mail to %s: %s\n%s\n", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// FileMailer - запись писем в файл вместо отправки, для локального тестирования.
+type FileMailer struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFile - функция, создающая FileMailer, дописывающий письма в файл path.
+func NewFile(path string) *FileMailer {
+	return &FileMailer{path: path}
+}
+
+// Send implements Mailer.
+func (m *FileMailer) Send(msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := os.OpenFile(m.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.Write(format("wikisurf@localhost", msg)); err != nil {
+		return err
+	}
+	_, err = f.WriteString("\r\n")
+	return err
+}
+
+// OpenViaEnvVars - выбор способа отправки писем через переменные окружения.
+// При заданном SMTP_HOST используется SMTP, при заданном MAIL_FILE - запись в файл,
+// при MAIL_LOG=1 - вывод в лог, иначе возвращается ошибка: письма содержат действующие токены.
+func OpenViaEnvVars(logger *log.Logger) (Mailer, error) {
+	if host := os.Getenv("SMTP_HOST"); host != "" {
+		port := os.Getenv("SMTP_PORT")
+		if port == "" {
+			port = "587"
+		}
+		return NewSMTP(host, port, os.Getenv("SMTP_USER"), os.Getenv("SMTP_PASSWORD"), os.Getenv("SMTP_FROM")), nil
+	}
+
+	if path := os.Getenv("MAIL_FILE"); path != "" {
+		return NewFile(path), nil
+	}
+
+	if os.Getenv("MAIL_LOG") == "1" {
+		return NewLog(logger), nil
+	}
+
+	return nil, errors.New("no mailer configured: set SMTP_HOST, MAIL_FILE or MAIL_LOG=1 for local development")
+}
+
+// format - функция, формирующая текст письма с заголовками.
+func format(from string, msg Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+
+	return []byte(b.String())
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// AchievementFacts - структура, хранящая данные пользователя, по которым выдаются достижения.
+type AchievementFacts struct {
+	Sprints       int   `db:"sprints"`        // Sprints - количество спринтов пользователя.
+	Successful    int   `db:"successful"`     // Successful - количество успешных спринтов пользователя.
+	Routes        int   `db:"routes"`         // Routes - количество разных маршрутов, пройденных пользователем.
+	Fastest       int64 `db:"fastest"`        // Fastest - длительность самого быстрого успешного спринта в ms, 0 - если таких нет.
+	Records       int   `db:"records"`        // Records - количество маршрутов, рекорд на которых принадлежит пользователю.
+	RoutesCreated int   `db:"routes_created"` // RoutesCreated - количество маршрутов, созданных пользователем.
+	Optimal       int   `db:"optimal"`        // Optimal - количество маршрутов, пройденных за наименьшее известное число шагов.
+	TourWins      int   `db:"tour_wins"`      // TourWins - количество соревнований, завершённых пользователем на первом месте.
+	Tournaments   int   `db:"tournaments"`    // Tournaments - количество завершённых соревнований с участием пользователя.
+}
+
+// UserAchievement - структура, описывающая достижение, выданное пользователю.
+type UserAchievement struct {
+	UserId      int       `json:"user_id" db:"user_id"`         // UserId - id пользователя.
+	Achievement string    `json:"achievement" db:"achievement"` // Achievement - id достижения.
+	AwardedAt   time.Time `json:"awarded_at" db:"awarded_at"`   // AwardedAt - время выдачи достижения.
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Области доступа персональных токенов.
+const (
+	ScopeRead        = "read"         // ScopeRead - чтение данных пользователя, маршрутов, соревнований и рейтингов.
+	ScopeSprintWrite = "sprint:write" // ScopeSprintWrite - запуск маршрутов и сохранение спринтов.
+)
+
+// Scopes - все области доступа персональных токенов.
+var Scopes = []string{ScopeRead, ScopeSprintWrite}
+
+// ApiToken - структура, описывающая персональный токен доступа к API.
+type ApiToken struct {
+	Id         int        `json:"id" db:"id"`                     // Id - id токена.
+	UserId     int        `json:"-" db:"user_id"`                 // UserId - id владельца токена.
+	Name       string     `json:"name" db:"name"`                 // Name - название токена.
+	TokenHash  string     `json:"-" db:"token_hash"`              // TokenHash - sha256 хэш токена.
+	Scopes     string     `json:"scopes" db:"scopes"`             // Scopes - области доступа токена через пробел.
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`     // CreatedAt - время создания токена.
+	LastUsedAt *time.Time `json:"last_used_at" db:"last_used_at"` // LastUsedAt - время последнего использования токена.
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// Стороны сетки соревнования на выбывание.
+const (
+	BracketWinners = "winners" // BracketWinners - верхняя сетка.
+	BracketLosers  = "losers"  // BracketLosers - нижняя сетка.
+	BracketFinal   = "final"   // BracketFinal - гранд-финал.
+)
+
+// Виды сеток соревнования на выбывание.
+const (
+	SingleElimination = "single" // SingleElimination - сетка на выбывание после первого поражения.
+	DoubleElimination = "double" // DoubleElimination - сетка на выбывание после второго поражения.
+)
+
+// Особые значения слотов матча.
+const (
+	SlotPending = 0  // SlotPending - участник слота ещё не определён.
+	SlotEmpty   = -1 // SlotEmpty - слот пуст (проход без соперника).
+)
+
+// Bracket - структура, представляющая сетку соревнования на выбывание.
+type Bracket struct {
+	TournamentId int       `json:"tour_id" db:"tour_id"`       // TournamentId - id соревнования.
+	Kind         string    `json:"kind" db:"kind"`             // Kind - вид сетки.
+	CreatedAt    time.Time `json:"created_at" db:"created_at"` // CreatedAt - время создания сетки.
+}
+
+// BracketMatch - структура, представляющая матч сетки, в котором пара участников проходит маршрут.
+type BracketMatch struct {
+	TournamentId int        `json:"tour_id" db:"tour_id"`               // TournamentId - id соревнования.
+	Num          int        `json:"num" db:"num"`                       // Num - номер матча в сетке.
+	Side         string     `json:"side" db:"side"`                     // Side - сторона сетки.
+	Round        int        `json:"round" db:"round"`                   // Round - номер раунда на стороне сетки.
+	Player1      int        `json:"player1" db:"player1"`               // Player1 - id первого участника, SlotPending или SlotEmpty.
+	Player2      int        `json:"player2" db:"player2"`               // Player2 - id второго участника, SlotPending или SlotEmpty.
+	Winner       int        `json:"winner" db:"winner"`                 // Winner - id победителя, SlotPending или SlotEmpty.
+	RouteId      int        `json:"route_id" db:"route_id"`             // RouteId - id маршрута матча.
+	NextWin      int        `json:"next_win" db:"next_win"`             // NextWin - номер матча, в который проходит победитель, -1 - если такого нет.
+	NextWinSlot  int        `json:"next_win_slot" db:"next_win_slot"`   // NextWinSlot - слот победителя в следующем матче.
+	NextLose     int        `json:"next_lose" db:"next_lose"`           // NextLose - номер матча, в который попадает проигравший, -1 - если такого нет.
+	NextLoseSlot int        `json:"next_lose_slot" db:"next_lose_slot"` // NextLoseSlot - слот проигравшего в следующем матче.
+	ReadyAt      *time.Time `json:"ready_at" db:"ready_at"`             // ReadyAt - время, когда определились оба участника матча.
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Collection - структура, описывающая подборку маршрутов.
+type Collection struct {
+	Id          int       `json:"id" db:"id"`                   // Id - id подборки.
+	Name        string    `json:"name" db:"name"`               // Name - название подборки.
+	Description string    `json:"description" db:"description"` // Description - описание подборки.
+	OwnerId     int       `json:"owner_id" db:"owner_id"`       // OwnerId - id пользователя, составившего подборку.
+	Private     bool      `json:"private" db:"private"`         // Private - флаг видимости подборки только её автору.
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`   // CreatedAt - время создания подборки.
+}
+
+// CollectionRoute - структура, описывающая маршрут подборки и его прохождение пользователем.
+type CollectionRoute struct {
+	Route
+	Position  int  `json:"position" db:"position"`   // Position - порядковый номер маршрута в подборке.
+	Completed bool `json:"completed" db:"completed"` // Completed - флаг успешного прохождения маршрута пользователем.
+}
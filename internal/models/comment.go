@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// RouteComment - структура, описывающая комментарий к маршруту.
+type RouteComment struct {
+	Id        int       `json:"id" db:"id"`                 // Id - id комментария.
+	RouteId   int       `json:"route_id" db:"route_id"`     // RouteId - id маршрута.
+	UserId    int       `json:"user_id" db:"user_id"`       // UserId - id автора комментария.
+	UserName  string    `json:"user_name" db:"user_name"`   // UserName - имя автора комментария.
+	ParentId  *int      `json:"parent_id" db:"parent_id"`   // ParentId - id комментария, на который дан ответ.
+	Body      string    `json:"body" db:"body"`             // Body - текст комментария.
+	Spoiler   bool      `json:"spoiler" db:"spoiler"`       // Spoiler - флаг обсуждения пути, скрытого под спойлер.
+	Hidden    bool      `json:"hidden" db:"hidden"`         // Hidden - флаг комментария, удалённого автором или модерацией.
+	CreatedAt time.Time `json:"created_at" db:"created_at"` // CreatedAt - время написания комментария.
+}
+
+// RouteVotes - структура, описывающая оценки маршрута.
+type RouteVotes struct {
+	Average float64 `json:"average" db:"average"` // Average - средняя оценка маршрута.
+	Count   int     `json:"count" db:"count"`     // Count - количество оценок.
+	Own     int     `json:"own" db:"own"`         // Own - оценка пользователя, 0 - нет оценки.
+}
+
+// RouteReport - структура, описывающая жалобу на маршрут или комментарий к нему.
+type RouteReport struct {
+	Id         int        `json:"id" db:"id"`                   // Id - id жалобы.
+	RouteId    int        `json:"route_id" db:"route_id"`       // RouteId - id маршрута.
+	CommentId  *int       `json:"comment_id" db:"comment_id"`   // CommentId - id комментария, пустой для жалобы на сам маршрут.
+	UserId     int        `json:"user_id" db:"user_id"`         // UserId - id пожаловавшегося пользователя.
+	Reason     string     `json:"reason" db:"reason"`           // Reason - причина жалобы.
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`   // CreatedAt - время жалобы.
+	ResolvedAt *time.Time `json:"resolved_at" db:"resolved_at"` // ResolvedAt - время рассмотрения жалобы.
+	Resolution string     `json:"resolution" db:"resolution"`   // Resolution - решение по жалобе.
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// ExportSprint - структура, описывающая спринт в выгрузке истории или результатов соревнования.
+type ExportSprint struct {
+	SprintId   int       `json:"sprint_id" db:"sprint_id"`     // SprintId - id спринта.
+	UserId     int       `json:"user_id" db:"user_id"`         // UserId - id пользователя, проведшего спринт.
+	UserName   string    `json:"user_name" db:"user_name"`     // UserName - имя пользователя, проведшего спринт.
+	RouteId    int       `json:"route_id" db:"route_id"`       // RouteId - id маршрута.
+	Start      string    `json:"start" db:"start"`             // Start - стартовая статья маршрута.
+	Finish     string    `json:"finish" db:"finish"`           // Finish - конечная статья маршрута.
+	StartTime  time.Time `json:"start_time" db:"start_time"`   // StartTime - время старта спринта.
+	LengthTime int64     `json:"length_time" db:"length_time"` // LengthTime - длительность спринта в ms.
+	Steps      int       `json:"steps" db:"steps"`             // Steps - количество шагов в спринте.
+	Success    bool      `json:"success" db:"success"`         // Success - успешность спринта.
+}
+
+// ExportRouteRating - структура, описывающая лучший спринт пользователя в выгрузке рейтинга маршрута.
+type ExportRouteRating struct {
+	UserId     int    `json:"user_id" db:"user_id"`         // UserId - id пользователя.
+	UserName   string `json:"user_name" db:"user_name"`     // UserName - имя пользователя.
+	SprintId   int    `json:"sprint_id" db:"sprint_id"`     // SprintId - id лучшего спринта.
+	LengthTime int64  `json:"length_time" db:"length_time"` // LengthTime - длительность лучшего спринта в ms.
+	Steps      int    `json:"steps" db:"steps"`             // Steps - количество шагов в лучшем спринте.
+}
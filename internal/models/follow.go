@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Follow - структура, описывающая игрока, на которого подписан пользователь.
+type Follow struct {
+	UserId   int       `json:"user_id" db:"user_id"`     // UserId - id игрока.
+	UserName string    `json:"user_name" db:"user_name"` // UserName - имя игрока.
+	Since    time.Time `json:"since" db:"since"`         // Since - время подписки.
+}
+
+// FeedSprint - структура, описывающая спринт в ленте подписок пользователя.
+type FeedSprint struct {
+	SprintId   int       `json:"sprint_id" db:"sprint_id"`     // SprintId - id спринта.
+	UserId     int       `json:"user_id" db:"user_id"`         // UserId - id игрока, проведшего спринт.
+	UserName   string    `json:"user_name" db:"user_name"`     // UserName - имя игрока, проведшего спринт.
+	RouteId    int       `json:"route_id" db:"route_id"`       // RouteId - id маршрута.
+	Start      string    `json:"start" db:"start"`             // Start - стартовая статья маршрута.
+	Finish     string    `json:"finish" db:"finish"`           // Finish - конечная статья маршрута.
+	Success    bool      `json:"success" db:"success"`         // Success - успешность спринта.
+	LengthTime int64     `json:"length_time" db:"length_time"` // LengthTime - длительность спринта в ms.
+	Steps      int       `json:"steps" db:"steps"`             // Steps - количество шагов в спринте.
+	StartTime  time.Time `json:"start_time" db:"start_time"`   // StartTime - время старта спринта.
+}
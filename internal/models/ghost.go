@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Виды соперников-призраков.
+const (
+	GhostPersonalBest = "pb"     // GhostPersonalBest - лучший спринт самого пользователя на маршруте.
+	GhostRecord       = "record" // GhostRecord - рекорд маршрута.
+	GhostUser         = "user"   // GhostUser - лучший спринт выбранного игрока на маршруте.
+)
+
+// Ghost - структура, описывающая соперника-призрака: чужой или свой спринт, с которым соревнуется игрок.
+type Ghost struct {
+	Kind       string         `json:"kind"`        // Kind - вид призрака.
+	SprintId   int            `json:"sprint_id"`   // SprintId - id спринта призрака.
+	UserId     int            `json:"user_id"`     // UserId - id игрока, проведшего спринт призрака.
+	UserName   string         `json:"user_name"`   // UserName - имя игрока, проведшего спринт призрака.
+	LengthTime int64          `json:"length_time"` // LengthTime - длительность спринта призрака в ms.
+	Path       pq.StringArray `json:"path"`        // Path - путь призрака.
+	StepTimes  pq.Int64Array  `json:"step_times"`  // StepTimes - время шагов призрака в ms от старта, может быть пустым.
+	BackSteps  pq.BoolArray   `json:"back_steps"`  // BackSteps - флаги шагов призрака, сделанных кнопкой "назад", может быть пустым.
+}
+
+// GhostResult - структура, описывающая итог гонки с призраком.
+type GhostResult struct {
+	SprintId      int       `json:"sprint_id" db:"sprint_id"`             // SprintId - id спринта игрока.
+	GhostSprintId int       `json:"ghost_sprint_id" db:"ghost_sprint_id"` // GhostSprintId - id спринта призрака.
+	Beaten        bool      `json:"beaten" db:"beaten"`                   // Beaten - флаг победы над призраком.
+	Margin        int64     `json:"margin" db:"margin"`                   // Margin - разница длительностей спринтов в ms, положительная при победе.
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`           // CreatedAt - время записи итога.
+}
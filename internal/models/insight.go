@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Виды статистики путей.
+const (
+	InsightHub        = "hub"         // InsightHub - часто посещаемые промежуточные статьи.
+	InsightDeadEnd    = "dead_end"    // InsightDeadEnd - статьи, из которых возвращались назад или на которых сдавались.
+	InsightFirstClick = "first_click" // InsightFirstClick - первые переходы со стартовой статьи.
+)
+
+// PathInsight - структура, описывающая статью в посчитанной статистике путей.
+type PathInsight struct {
+	RouteId    int       `json:"route_id" db:"route_id"`       // RouteId - id маршрута, 0 - статистика по всем маршрутам.
+	Kind       string    `json:"kind" db:"kind"`               // Kind - вид статистики.
+	Article    string    `json:"article" db:"article"`         // Article - статья.
+	Hits       int       `json:"hits" db:"hits"`               // Hits - количество спринтов, в которых встретилась статья.
+	Successes  int       `json:"successes" db:"successes"`     // Successes - количество успешных спринтов из них.
+	Place      int       `json:"place" db:"place"`             // Place - место статьи в статистике.
+	ComputedAt time.Time `json:"computed_at" db:"computed_at"` // ComputedAt - время расчёта статистики.
+}
+
+// PathSprint - структура, описывающая путь спринта для расчёта статистики.
+type PathSprint struct {
+	RouteId   int            `db:"route_id"`   // RouteId - id маршрута.
+	Start     string         `db:"start"`      // Start - стартовая статья маршрута.
+	Finish    string         `db:"finish"`     // Finish - конечная статья маршрута.
+	Path      pq.StringArray `db:"path"`       // Path - пройденный путь.
+	BackSteps pq.BoolArray   `db:"back_steps"` // BackSteps - флаги шагов, сделанных кнопкой "назад".
+	Success   bool           `db:"success"`    // Success - успешность спринта.
+}
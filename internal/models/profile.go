@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// UserStats - структура, описывающая итоговую статистику пользователя.
+type UserStats struct {
+	Sprints       int     `json:"sprints" db:"sprints"`               // Sprints - количество спринтов пользователя.
+	Successful    int     `json:"successful" db:"successful"`         // Successful - количество успешных спринтов пользователя.
+	Wins          int     `json:"wins" db:"wins"`                     // Wins - количество маршрутов, рекорд на которых принадлежит пользователю.
+	RoutesCreated int     `json:"routes_created" db:"routes_created"` // RoutesCreated - количество маршрутов, созданных пользователем.
+	AvgClicks     float64 `json:"avg_clicks" db:"avg_clicks"`         // AvgClicks - среднее количество шагов в успешных спринтах.
+	Followers     int     `json:"followers" db:"followers"`           // Followers - количество подписчиков пользователя.
+	Following     int     `json:"following" db:"following"`           // Following - количество игроков, на которых подписан пользователь.
+}
+
+// PersonalBest - структура, описывающая лучший спринт пользователя на маршруте.
+type PersonalBest struct {
+	RouteId    int    `json:"route_id" db:"route_id"`       // RouteId - id маршрута.
+	Start      string `json:"start" db:"start"`             // Start - стартовая статья маршрута.
+	Finish     string `json:"finish" db:"finish"`           // Finish - конечная статья маршрута.
+	SprintId   int    `json:"sprint_id" db:"sprint_id"`     // SprintId - id лучшего спринта.
+	LengthTime int64  `json:"length_time" db:"length_time"` // LengthTime - длительность лучшего спринта в ms.
+	Steps      int    `json:"steps" db:"steps"`             // Steps - количество шагов в лучшем спринте.
+}
+
+// Activity - структура, описывающая активность пользователя за месяц.
+type Activity struct {
+	Month      time.Time `json:"month" db:"month"`           // Month - начало месяца.
+	Sprints    int       `json:"sprints" db:"sprints"`       // Sprints - количество спринтов за месяц.
+	Successful int       `json:"successful" db:"successful"` // Successful - количество успешных спринтов за месяц.
+}
@@ -11,6 +11,28 @@ type RouteRating struct {
 
 // TourRating - структура, представляющая блок рейтинга соревнования для пользователя.
 type TourRating struct {
+	UserId   int    `json:"user_id"`   // UserId - id пользователя, которого представляет блок.
 	UserName string `json:"user_name"` // UserName - имя пользователя, которого представляет блок.
 	Points   int    `json:"-"`         // Points - количество очков пользователя.
 }
+
+// TourResult - структура, представляющая зафиксированный итог соревнования для пользователя.
+type TourResult struct {
+	TournamentId int    `json:"tour_id" db:"tour_id"`     // TournamentId - id соревнования.
+	UserId       int    `json:"user_id" db:"user_id"`     // UserId - id пользователя.
+	UserName     string `json:"user_name" db:"user_name"` // UserName - имя пользователя на момент окончания соревнования.
+	Place        int    `json:"place" db:"place"`         // Place - итоговое место пользователя.
+	Points       int    `json:"points" db:"points"`       // Points - итоговое количество очков пользователя.
+}
+
+// TourRouteWinner - структура, представляющая победителя соревнования на маршруте.
+type TourRouteWinner struct {
+	TournamentId int    `json:"tour_id" db:"tour_id"`         // TournamentId - id соревнования.
+	RouteId      int    `json:"route_id" db:"route_id"`       // RouteId - id маршрута.
+	Start        string `json:"start" db:"start"`             // Start - ссылка на стартовую статью маршрута.
+	Finish       string `json:"finish" db:"finish"`           // Finish - ссылка на финишную статью маршрута.
+	UserId       int    `json:"user_id" db:"user_id"`         // UserId - id победителя.
+	UserName     string `json:"user_name" db:"user_name"`     // UserName - имя победителя на момент окончания соревнования.
+	SprintId     int    `json:"sprint_id" db:"sprint_id"`     // SprintId - id победного спринта.
+	LengthTime   int64  `json:"length_time" db:"length_time"` // LengthTime - длительность победного спринта в ms.
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Season - структура, описывающая сезон рейтинга.
+type Season struct {
+	Id        int       `json:"id" db:"id"`                 // Id - id сезона.
+	Name      string    `json:"name" db:"name"`             // Name - название сезона.
+	StartTime time.Time `json:"start_time" db:"start_time"` // StartTime - время начала сезона.
+	EndTime   time.Time `json:"end_time" db:"end_time"`     // EndTime - время окончания сезона.
+	Archived  bool      `json:"archived" db:"archived"`     // Archived - флаг, указывающий, что итоги сезона зафиксированы.
+}
+
+// SeasonResult - структура, представляющая зафиксированный итог сезона для пользователя.
+type SeasonResult struct {
+	SeasonId int    `json:"season_id" db:"season_id"` // SeasonId - id сезона.
+	UserId   int    `json:"user_id" db:"user_id"`     // UserId - id пользователя.
+	UserName string `json:"user_name" db:"user_name"` // UserName - имя пользователя на момент окончания сезона.
+	Place    int    `json:"place" db:"place"`         // Place - итоговое место пользователя.
+	Points   int    `json:"points" db:"points"`       // Points - количество рекордов маршрутов пользователя за сезон.
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// PlayerSkill - структура, описывающая рейтинг мастерства игрока.
+type PlayerSkill struct {
+	UserId     int       `json:"user_id" db:"user_id"`         // UserId - id игрока.
+	UserName   string    `json:"user_name" db:"user_name"`     // UserName - имя игрока.
+	Rating     float64   `json:"rating" db:"rating"`           // Rating - рейтинг без учёта неактивности.
+	Games      int       `json:"games" db:"games"`             // Games - количество сыгранных игроком матчей.
+	LastActive time.Time `json:"last_active" db:"last_active"` // LastActive - время последнего матча игрока.
+}
@@ -15,4 +15,6 @@ type Sprint struct {
 	Success    bool           `json:"success" db:"success"`         // Success - успешность спринта.
 	LengthTime int64          `json:"length_time" db:"length_time"` // LengthTime - длительность спринта в ms.
 	StartTime  time.Time      `json:"start_time" db:"start_time"`   // StartTime - время старта спринта.
+	StepTimes  pq.Int64Array  `json:"step_times" db:"step_times"`   // StepTimes - время перехода на каждый шаг пути в ms от старта, пустое у старых спринтов.
+	BackSteps  pq.BoolArray   `json:"back_steps" db:"back_steps"`   // BackSteps - флаги шагов пути, сделанных кнопкой "назад", может быть пустым.
 }
@@ -0,0 +1,17 @@
+package models
+
+// Team - структура, представляющая сущность команды в соревновании.
+type Team struct {
+	Id           int    `json:"id" db:"id"`                 // Id - id команды.
+	TournamentId int    `json:"tour_id" db:"tour_id"`       // TournamentId - id соревнования, в котором участвует команда.
+	Name         string `json:"name" db:"name"`             // Name - название команды.
+	CaptainId    int    `json:"captain_id" db:"captain_id"` // CaptainId - id капитана команды.
+}
+
+// TeamRating - структура, представляющая блок командного рейтинга соревнования.
+type TeamRating struct {
+	TeamId   int    `json:"team_id"`   // TeamId - id команды, которую представляет блок.
+	TeamName string `json:"team_name"` // TeamName - название команды.
+	Members  int    `json:"members"`   // Members - количество участников команды.
+	Points   int    `json:"points"`    // Points - сумма очков участников команды.
+}
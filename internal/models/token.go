@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Виды одноразовых токенов пользователя.
+const (
+	TokenVerify = "verify" // TokenVerify - подтверждение адреса электронной почты.
+	TokenReset  = "reset"  // TokenReset - сброс пароля.
+	TokenEmail  = "email"  // TokenEmail - подтверждение смены адреса электронной почты.
+)
+
+// UserToken - структура, описывающая одноразовый токен пользователя.
+type UserToken struct {
+	TokenHash string    `db:"token_hash"` // TokenHash - sha256 хэш токена.
+	UserId    int       `db:"user_id"`    // UserId - id пользователя.
+	Kind      string    `db:"kind"`       // Kind - вид токена.
+	Email     string    `db:"email"`      // Email - адрес электронной почты, к которому относится токен.
+	ExpiresAt time.Time `db:"expires_at"` // ExpiresAt - время истечения токена.
+}
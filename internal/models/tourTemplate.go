@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// TourTemplate - структура, описывающая сохранённый шаблон соревнования.
+type TourTemplate struct {
+	Id         int        `json:"id" db:"id"`                 // Id - id шаблона.
+	Name       string     `json:"name" db:"name"`             // Name - название шаблона.
+	OwnerId    int        `json:"owner_id" db:"owner_id"`     // OwnerId - id пользователя, сохранившего шаблон.
+	Spec       string     `json:"spec" db:"spec"`             // Spec - настройка соревнования в формате JSON.
+	Recurrence string     `json:"recurrence" db:"recurrence"` // Recurrence - правило повторения, пустое - без повторения.
+	NextStart  *time.Time `json:"next_start" db:"next_start"` // NextStart - время начала следующего соревнования по правилу повторения.
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"` // CreatedAt - время сохранения шаблона.
+}
@@ -7,8 +7,9 @@ type Tournament struct {
 	Id        int       `json:"id" db:"id"`                 // Id - id соревнования.
 	StartTime time.Time `json:"start_time" db:"start_time"` // StartTime - время начала соревнования.
 	EndTime   time.Time `json:"end_time" db:"end_time"`     // EndTime - время конца соревнования.
-	Pswd      string    `json:"pswd" db:"pswd"`             // Pswd - Код-пароль соревнования.
+	Pswd      string    `json:"-" db:"pswd"`                // Pswd - sha256 хэш кода-пароля соревнования, пустой, если код отозван.
 	Private   bool      `json:"private" db:"private"`       // Private - флаг, указывающий на закрытость соревнования.
+	MaxUsers  int       `json:"max_users" db:"max_users"`   // MaxUsers - максимальное количество участников, 0 - без ограничения.
 }
 
 // TURelation - структура, представляющая отношение между соревнованием и пользователем.
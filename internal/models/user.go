@@ -6,4 +6,7 @@ type User struct {
 	Name     string `json:"name" db:"name"`         // Name - никнейм пользователя.
 	Email    string `json:"email" db:"email"`       // Email - адрес электронной почты пользователя.
 	Password string `json:"password" db:"password"` // Password - зашифрованный пароль пользователя.
+	Verified bool   `json:"-" db:"verified"`        // Verified - флаг, указывающий на подтверждение адреса электронной почты.
+	Session  int    `json:"-" db:"session_version"` // Session - версия сессий пользователя, её увеличение завершает все выданные сессии.
+	Hidden   bool   `json:"-" db:"profile_hidden"`  // Hidden - флаг, скрывающий профиль пользователя от других игроков.
 }
@@ -0,0 +1,345 @@
+// Package oidc - пакет для входа через внешних провайдеров OAuth2 / OpenID Connect.
+package oidc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Identity - структура, описывающая пользователя внешнего провайдера.
+type Identity struct {
+	Subject       string // Subject - постоянный id пользователя у провайдера.
+	Email         string // Email - адрес электронной почты пользователя.
+	EmailVerified bool   // EmailVerified - флаг, указывающий, что провайдер подтвердил почту.
+	Name          string // Name - имя пользователя.
+}
+
+// Provider - интерфейс провайдера входа.
+type Provider interface {
+	Name() string                                                                           // Name - идентификатор провайдера в ссылках.
+	Title() string                                                                          // Title - название провайдера для пользователя.
+	AuthURL(ctx context.Context, redirect, state, nonce, verifier string) (string, error)   // AuthURL - ссылка на страницу входа у провайдера.
+	Exchange(ctx context.Context, redirect, code, nonce, verifier string) (Identity, error) // Exchange - обмен кода авторизации на данные пользователя.
+}
+
+// discovery - структура, хранящая метаданные OIDC провайдера.
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+}
+
+// Generic - провайдер, работающий с любым OIDC сервером, поддерживающим discovery.
+type Generic struct {
+	name, title  string
+	issuer       string
+	clientId     string
+	clientSecret string
+	client       *http.Client
+
+	mu   sync.Mutex
+	meta *discovery
+}
+
+// NewGeneric - функция, создающая провайдер для OIDC сервера issuer.
+func NewGeneric(name, title, issuer, clientId, clientSecret string) *Generic {
+	return &Generic{
+		name:         name,
+		title:        title,
+		issuer:       strings.TrimSuffix(issuer, "/"),
+		clientId:     clientId,
+		clientSecret: clientSecret,
+		client:       &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name implements Provider.
+func (g *Generic) Name() string {
+	return g.name
+}
+
+// Title implements Provider.
+func (g *Generic) Title() string {
+	return g.title
+}
+
+// AuthURL implements Provider.
+func (g *Generic) AuthURL(ctx context.Context, redirect, state, nonce, verifier string) (string, error) {
+	meta, err := g.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	challenge := sha256.Sum256([]byte(verifier))
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {g.clientId},
+		"redirect_uri":          {redirect},
+		"scope":                 {"openid email profile"},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+
+	sep := "?"
+	if strings.Contains(meta.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return meta.AuthorizationEndpoint + sep + q.Encode(), nil
+}
+
+// Exchange implements Provider.
+//
+// ID токен получается напрямую от token endpoint по TLS, поэтому, как допускает OpenID Connect Core 3.1.3.7,
+// проверяются только его утверждения, без проверки подписи.
+func (g *Generic) Exchange(ctx context.Context, redirect, code, nonce, verifier string) (Identity, error) {
+	meta, err := g.discover(ctx)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirect},
+		"client_id":     {g.clientId},
+		"client_secret": {g.clientSecret},
+		"code_verifier": {verifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Identity{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var tokens struct {
+		AccessToken string `json:"access_token"`
+		IdToken     string `json:"id_token"`
+	}
+	if err := g.doJSON(req, &tokens); err != nil {
+		return Identity{}, errors.Join(errors.New("error while exchanging the authorization code"), err)
+	}
+	if tokens.IdToken == "" {
+		return Identity{}, errors.New("the provider did not return an id token")
+	}
+
+	claims, err := g.checkIdToken(tokens.IdToken, meta.Issuer, nonce)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	if meta.UserinfoEndpoint != "" && tokens.AccessToken != "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, meta.UserinfoEndpoint, nil)
+		if err != nil {
+			return Identity{}, err
+		}
+		req.Header.Set("Authorization", "Bearer "+tokens.AccessToken)
+		req.Header.Set("Accept", "application/json")
+
+		var info idClaims
+		if err := g.doJSON(req, &info); err != nil {
+			return Identity{}, errors.Join(errors.New("error while getting user info"), err)
+		}
+		if info.Subject != claims.Subject {
+			return Identity{}, errors.New("user info does not belong to the id token's subject")
+		}
+		claims.merge(info)
+	}
+
+	return Identity{
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: bool(claims.EmailVerified),
+		Name:          claims.Name,
+	}, nil
+}
+
+// idClaims - структура, хранящая нужные утверждения ID токена и user info.
+type idClaims struct {
+	Issuer        string   `json:"iss"`
+	Subject       string   `json:"sub"`
+	Audience      audience `json:"aud"`
+	Expiry        int64    `json:"exp"`
+	Nonce         string   `json:"nonce"`
+	Email         string   `json:"email"`
+	EmailVerified flexBool `json:"email_verified"`
+	Name          string   `json:"name"`
+}
+
+// merge - функция, дополняющая утверждения ID токена данными user info.
+func (c *idClaims) merge(info idClaims) {
+	if info.Email != "" {
+		c.Email = info.Email
+		c.EmailVerified = info.EmailVerified
+	}
+	if info.Name != "" {
+		c.Name = info.Name
+	}
+}
+
+// checkIdToken - функция, разбирающая ID токен и проверяющая его утверждения.
+func (g *Generic) checkIdToken(token, issuer, nonce string) (idClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return idClaims{}, errors.New("malformed id token")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return idClaims{}, errors.Join(errors.New("malformed id token"), err)
+	}
+
+	var claims idClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return idClaims{}, errors.Join(errors.New("malformed id token"), err)
+	}
+
+	switch {
+	case claims.Issuer != issuer:
+		return idClaims{}, fmt.Errorf("id token is issued by %q instead of %q", claims.Issuer, issuer)
+	case !claims.Audience.contains(g.clientId):
+		return idClaims{}, errors.New("id token is issued for another client")
+	case claims.Expiry < time.Now().Unix():
+		return idClaims{}, errors.New("id token is expired")
+	case claims.Nonce != nonce:
+		return idClaims{}, errors.New("id token nonce does not match")
+	case claims.Subject == "":
+		return idClaims{}, errors.New("id token has no subject")
+	}
+
+	return claims, nil
+}
+
+// discover - функция, получающая и кэширующая метаданные провайдера.
+func (g *Generic) discover(ctx context.Context) (*discovery, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.meta != nil {
+		return g.meta, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var meta discovery
+	if err := g.doJSON(req, &meta); err != nil {
+		return nil, errors.Join(errors.New("error while discovering the provider"), err)
+	}
+	if strings.TrimSuffix(meta.Issuer, "/") != g.issuer {
+		return nil, fmt.Errorf("provider reports issuer %q instead of %q", meta.Issuer, g.issuer)
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" {
+		return nil, errors.New("provider metadata has no authorization or token endpoint")
+	}
+
+	g.meta = &meta
+	return g.meta, nil
+}
+
+// doJSON - функция, выполняющая запрос и разбирающая JSON ответ.
+func (g *Generic) doJSON(req *http.Request, out any) error {
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s responded with %d: %s", req.URL.Host, resp.StatusCode, body)
+	}
+
+	return json.Unmarshal(body, out)
+}
+
+// audience - поле aud, которое может быть строкой или массивом строк.
+type audience []string
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*a = audience{one}
+		return nil
+	}
+
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+// contains - функция, проверяющая наличие клиента в aud.
+func (a audience) contains(clientId string) bool {
+	for _, aud := range a {
+		if aud == clientId {
+			return true
+		}
+	}
+	return false
+}
+
+// flexBool - логическое поле, которое некоторые провайдеры передают строкой.
+type flexBool bool
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	switch strings.Trim(string(data), `"`) {
+	case "true":
+		*b = true
+	default:
+		*b = false
+	}
+	return nil
+}
+
+// ProvidersFromEnv - получение провайдеров из переменных окружения.
+//
+// OIDC_PROVIDERS содержит имена провайдеров через запятую, для каждого имени NAME читаются
+// OIDC_NAME_ISSUER, OIDC_NAME_CLIENT_ID, OIDC_NAME_CLIENT_SECRET и необязательная OIDC_NAME_TITLE.
+func ProvidersFromEnv() []Provider {
+	var res []Provider
+
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		issuer := os.Getenv(prefix + "ISSUER")
+		clientId := os.Getenv(prefix + "CLIENT_ID")
+		if issuer == "" || clientId == "" {
+			continue
+		}
+		title := os.Getenv(prefix + "TITLE")
+		if title == "" {
+			title = name
+		}
+
+		res = append(res, NewGeneric(strings.ToLower(name), title, issuer, clientId, os.Getenv(prefix+"CLIENT_SECRET")))
+	}
+
+	return res
+}
@@ -0,0 +1,221 @@
+package oidc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testClient   = "wikisurf"
+	testSecret   = "secret"
+	testRedirect = "https://wikisurf.example/auth/oidc/mock/callback"
+	testCode     = "auth-code"
+	testAccess   = "access-token"
+)
+
+// mockIssuer - OIDC сервер для тестов, выдающий неподписанные ID токены.
+type mockIssuer struct {
+	srv *httptest.Server
+
+	issuer    string         // issuer - issuer в метаданных, по умолчанию адрес сервера.
+	claims    map[string]any // claims - утверждения, заменяющие утверждения ID токена по умолчанию.
+	info      map[string]any // info - ответ user info.
+	challenge string         // challenge - code_challenge, полученный при входе.
+	nonce     string         // nonce - nonce, полученный при входе.
+}
+
+// newMockIssuer - функция, запускающая тестовый OIDC сервер.
+func newMockIssuer(t *testing.T) *mockIssuer {
+	t.Helper()
+
+	m := &mockIssuer{claims: map[string]any{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		issuer := m.srv.URL
+		if m.issuer != "" {
+			issuer = m.issuer
+		}
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 issuer,
+			"authorization_endpoint": m.srv.URL + "/authorize",
+			"token_endpoint":         m.srv.URL + "/token",
+			"userinfo_endpoint":      m.srv.URL + "/userinfo",
+		})
+	})
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("client_id") != testClient || q.Get("code_challenge_method") != "S256" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		m.challenge, m.nonce = q.Get("code_challenge"), q.Get("nonce")
+		back := url.Values{"code": {testCode}, "state": {q.Get("state")}}
+		http.Redirect(w, r, q.Get("redirect_uri")+"?"+back.Encode(), http.StatusFound)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if r.PostForm.Get("code") != testCode || r.PostForm.Get("client_secret") != testSecret ||
+			base64.RawURLEncoding.EncodeToString(verifier[:]) != m.challenge {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"access_token": testAccess, "id_token": m.idToken()})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+testAccess {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(m.info)
+	})
+
+	m.srv = httptest.NewServer(mux)
+	t.Cleanup(m.srv.Close)
+	m.info = map[string]any{"sub": "user-1", "email": "surfer@example.com", "email_verified": "true", "name": "Surfer"}
+
+	return m
+}
+
+// idToken - функция, собирающая ID токен из утверждений по умолчанию и заменённых утверждений.
+func (m *mockIssuer) idToken() string {
+	claims := map[string]any{
+		"iss":   m.srv.URL,
+		"sub":   "user-1",
+		"aud":   testClient,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"nonce": m.nonce,
+		"email": "old@example.com",
+	}
+	for k, v := range m.claims {
+		claims[k] = v
+	}
+
+	payload, _ := json.Marshal(claims)
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString([]byte(`{"alg":"none"}`)) + "." + enc.EncodeToString(payload) + "."
+}
+
+// login - функция, проходящая вход у тестового сервера и возвращающая код авторизации.
+func login(t *testing.T, g *Generic, state, nonce, verifier string) string {
+	t.Helper()
+
+	authURL, err := g.AuthURL(context.Background(), testRedirect, state, nonce, verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	back, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := back.Query().Get("state"); got != state {
+		t.Fatalf("state: got %q, want %q", got, state)
+	}
+	return back.Query().Get("code")
+}
+
+func TestExchange(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(m *mockIssuer)
+		nonce  string // nonce - nonce из сессии при обмене кода, по умолчанию тот же, что при входе.
+		err    string
+	}{
+		{name: "valid"},
+		{name: "audience list", change: func(m *mockIssuer) { m.claims["aud"] = []string{"other", testClient} }},
+		{name: "wrong issuer", change: func(m *mockIssuer) { m.claims["iss"] = "https://evil.example" }, err: "is issued by"},
+		{name: "wrong audience", change: func(m *mockIssuer) { m.claims["aud"] = "other" }, err: "another client"},
+		{name: "expired", change: func(m *mockIssuer) { m.claims["exp"] = time.Now().Add(-time.Minute).Unix() }, err: "expired"},
+		{name: "nonce mismatch", nonce: "other-nonce", err: "nonce does not match"},
+		{name: "no subject", change: func(m *mockIssuer) { m.claims["sub"] = "" }, err: "no subject"},
+		{name: "user info of another subject", change: func(m *mockIssuer) { m.info["sub"] = "user-2" }, err: "does not belong"},
+		{name: "discovery issuer mismatch", change: func(m *mockIssuer) { m.issuer = "https://evil.example" }, err: "provider reports issuer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMockIssuer(t)
+			if tt.change != nil {
+				tt.change(m)
+			}
+			g := NewGeneric("mock", "Mock", m.srv.URL+"/", testClient, testSecret)
+
+			if m.issuer != "" {
+				_, err := g.AuthURL(context.Background(), testRedirect, "state", "nonce", "verifier")
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("got error %v, want %q", err, tt.err)
+				}
+				return
+			}
+
+			code := login(t, g, "state-1", "nonce-1", "verifier-1")
+			nonce := "nonce-1"
+			if tt.nonce != "" {
+				nonce = tt.nonce
+			}
+
+			id, err := g.Exchange(context.Background(), testRedirect, code, nonce, "verifier-1")
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("got error %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := Identity{Subject: "user-1", Email: "surfer@example.com", EmailVerified: true, Name: "Surfer"}
+			if id != want {
+				t.Fatalf("got %+v, want %+v", id, want)
+			}
+		})
+	}
+}
+
+func TestExchangeWrongVerifier(t *testing.T) {
+	m := newMockIssuer(t)
+	g := NewGeneric("mock", "Mock", m.srv.URL, testClient, testSecret)
+
+	code := login(t, g, "state-1", "nonce-1", "verifier-1")
+	if _, err := g.Exchange(context.Background(), testRedirect, code, "nonce-1", "verifier-2"); err == nil {
+		t.Fatal("the code must not be exchanged with another PKCE verifier")
+	}
+}
+
+func TestProvidersFromEnv(t *testing.T) {
+	t.Setenv("OIDC_PROVIDERS", "corp, broken,,Lab")
+	t.Setenv("OIDC_CORP_ISSUER", "https://sso.corp.example")
+	t.Setenv("OIDC_CORP_CLIENT_ID", "wikisurf")
+	t.Setenv("OIDC_CORP_TITLE", "Corp SSO")
+	t.Setenv("OIDC_BROKEN_ISSUER", "https://broken.example")
+	t.Setenv("OIDC_LAB_ISSUER", "https://lab.example")
+	t.Setenv("OIDC_LAB_CLIENT_ID", "wikisurf")
+
+	providers := ProvidersFromEnv()
+	want := [][2]string{{"corp", "Corp SSO"}, {"lab", "Lab"}}
+	if len(providers) != len(want) {
+		t.Fatalf("got %d providers, want %d", len(providers), len(want))
+	}
+	for i, p := range providers {
+		if p.Name() != want[i][0] || p.Title() != want[i][1] {
+			t.Errorf("provider %d: got (%q, %q), want (%q, %q)", i, p.Name(), p.Title(), want[i][0], want[i][1])
+		}
+	}
+}
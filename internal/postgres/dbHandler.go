@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"database/sql"
+	"time"
 
 	"github.com/famusovsky/WikiSurfBack/internal/models"
 	"github.com/jmoiron/sqlx"
@@ -9,39 +10,161 @@ import (
 
 // DbHandler - интерфейс, описывающий взаимодействие с БД WikiSurf.
 type DbHandler interface {
-	AddUser(user models.User) (int, error)                            // AddUser - добавление нового пользователя в БД.
-	AddRoute(route models.Route) (int, error)                         // AddRoute - добавление нового маршрута в БД.
-	AddSprint(sprint models.Sprint) (int, error)                      // AddSprint - добавление нового спринта в БД.
-	AddTournament(tour models.Tournament, userId int) (int, error)    // AddTournament - добавление нового соревнования в БД.
-	AddRouteToTour(tr models.TRRelation, userId int) error            // AddRouteToTour - добавление маршрута в соревнование.
-	RemoveRouteFromTour(tr models.TRRelation, userId int) error       // AddRouteToTour - удаление маршрута из соревнования.
-	AddUserToTour(tourId, userId int) error                           // AddUserToTour - добавление участника в соревнование.
-	RemoveUserFromTour(tourId, userId int) error                      // RemoveUserFromTour - удаление участника из соревнования.
-	AddCreatorToTour(tu models.TURelation, userId int) error          // AddCreatorToTour - добавление создателя в соревнование.
-	RemoveCreatorFromTour(tu models.TURelation, userId int) error     // RemoveCreatorFromTour - удаление создателя из соревнования.
-	GetUser(email string) (models.User, error)                        // GetUser - получение пользователя по email-у
-	GetUserById(id int) (models.User, error)                          // GetUserById - получение пользователя по id
-	GetRoute(id int) (models.Route, error)                            // GetRoute - получение маршрута по id.
-	GetPopularRoutes() ([]models.Route, error)                        // GetRoutes - получение популярных маршрутов.
-	GetRouteByCreds(start, finish string) (models.Route, error)       // GetRouteByCreds - получение маршрута по start, finish.
-	GetSprint(id int) (models.Sprint, error)                          // GetSprint - получение спринта по id.
-	GetTournament(id int) (models.Tournament, error)                  // GetTournament - получение соревнования по id.
-	GetTournamentRoutes(id int) ([]models.Route, error)               // GetTournamentRoutes - получение маршрутов соревнования.
-	GetTournamentCreators(id int) ([]models.User, error)              // GetTournamentRoutes - получение маршрутов соревнования.
-	GetUserHistory(id int) ([]models.Sprint, error)                   // GetUserHistory - получение истории спринтов пользователя.
-	GetUserRouteHistory(userId, routeId int) ([]models.Sprint, error) // GetUserRouteHistory - получение истории спринтов пользователя по маршруту.
-	GetRouteRatings(routeId int) ([]models.RouteRating, error)        // GetRouteRatings - получение рейтинга по маршруту.
-	GetOpenTournaments() ([]models.Tournament, error)                 // GetOpenTournaments - получение списка соревнований, открытых для вступления.
-	GetUserTournaments(user int) ([]models.Tournament, error)         // GetUserTournaments - получение списка соревнований, в которых пользователь участвует.
-	GetCreatorTournaments(user int) ([]models.Tournament, error)      // GetCreatorTournaments - получение списка соревнований, в которых пользователь выступает создателем.
-	GetTournamentRatings(tour int) ([]models.TourRating, error)       // GetTournamentRatings - получение рейтинга по соревнованию .
-	GetRatings() ([]models.TourRating, error)                         // GetRatings - получение общего рейтинга.
-	CheckTournamentPassword(pswd string) (int, error)                 // CheckTournamentPassword - проверка на соответствие кода-пароля соревнования.
-	CheckTournamentCreator(tourId, userId int) (bool, error)          // CheckTournamentCreator - проверка на соответствие Id пользователя с Id создателей соревнования.
-	CheckTournamentParticipator(tourId, userId int) (bool, error)     // CheckTournamentParticipator - проверка на соответствие Id пользователя с Id участников соревнования.
-	UpdateTournament(tour models.Tournament, user int) error          // UpdateTournament - обновление основных данных о соревновании.
-	DeleteTournament(tourId, userId int) error                        // DeleteTournament - удаление данных о соревновании.
-	UpdateUser(user models.User) error                                // UpdateUser - обновление основных данных о пользователе.
+	AddUser(user models.User) (int, error)                                                                                                           // AddUser - добавление нового пользователя в БД.
+	AddRoute(route models.Route) (int, error)                                                                                                        // AddRoute - добавление нового маршрута в БД.
+	AddSprint(sprint models.Sprint) (int, error)                                                                                                     // AddSprint - добавление нового спринта в БД.
+	AddTournament(tour models.Tournament, userId int) (int, error)                                                                                   // AddTournament - добавление нового соревнования в БД.
+	AddRouteToTour(tr models.TRRelation, userId int) error                                                                                           // AddRouteToTour - добавление маршрута в соревнование.
+	RemoveRouteFromTour(tr models.TRRelation, userId int) error                                                                                      // AddRouteToTour - удаление маршрута из соревнования.
+	AddUserToTour(tourId, userId int) error                                                                                                          // AddUserToTour - добавление участника в соревнование.
+	RemoveUserFromTour(tourId, userId int) error                                                                                                     // RemoveUserFromTour - удаление участника из соревнования.
+	AddCreatorToTour(tu models.TURelation, userId int) error                                                                                         // AddCreatorToTour - добавление создателя в соревнование.
+	RemoveCreatorFromTour(tu models.TURelation, userId int) error                                                                                    // RemoveCreatorFromTour - удаление создателя из соревнования.
+	GetUser(email string) (models.User, error)                                                                                                       // GetUser - получение пользователя по email-у
+	GetUserById(id int) (models.User, error)                                                                                                         // GetUserById - получение пользователя по id
+	GetRoute(id int) (models.Route, error)                                                                                                           // GetRoute - получение маршрута по id.
+	GetPopularRoutes() ([]models.Route, error)                                                                                                       // GetRoutes - получение популярных маршрутов.
+	GetRouteByCreds(start, finish string) (models.Route, error)                                                                                      // GetRouteByCreds - получение маршрута по start, finish.
+	GetSprint(id int) (models.Sprint, error)                                                                                                         // GetSprint - получение спринта по id.
+	GetTournament(id int) (models.Tournament, error)                                                                                                 // GetTournament - получение соревнования по id.
+	GetTournamentRoutes(id int) ([]models.Route, error)                                                                                              // GetTournamentRoutes - получение маршрутов соревнования.
+	GetTournamentCreators(id int) ([]models.User, error)                                                                                             // GetTournamentRoutes - получение маршрутов соревнования.
+	GetUserHistory(id int) ([]models.Sprint, error)                                                                                                  // GetUserHistory - получение истории спринтов пользователя.
+	GetUserRouteHistory(userId, routeId int) ([]models.Sprint, error)                                                                                // GetUserRouteHistory - получение истории спринтов пользователя по маршруту.
+	GetRouteRatings(routeId int) ([]models.RouteRating, error)                                                                                       // GetRouteRatings - получение рейтинга по маршруту.
+	GetOpenTournaments() ([]models.Tournament, error)                                                                                                // GetOpenTournaments - получение списка соревнований, открытых для вступления.
+	GetUserTournaments(user int) ([]models.Tournament, error)                                                                                        // GetUserTournaments - получение списка соревнований, в которых пользователь участвует.
+	GetCreatorTournaments(user int) ([]models.Tournament, error)                                                                                     // GetCreatorTournaments - получение списка соревнований, в которых пользователь выступает создателем.
+	GetTournamentRatings(tour int) ([]models.TourRating, error)                                                                                      // GetTournamentRatings - получение рейтинга по соревнованию .
+	GetRatings() ([]models.TourRating, error)                                                                                                        // GetRatings - получение общего рейтинга.
+	CheckTournamentPassword(pswd string) (int, error)                                                                                                // CheckTournamentPassword - получение соревнования по хэшу кода-пароля.
+	CheckTournamentCreator(tourId, userId int) (bool, error)                                                                                         // CheckTournamentCreator - проверка на соответствие Id пользователя с Id создателей соревнования.
+	CheckTournamentParticipator(tourId, userId int) (bool, error)                                                                                    // CheckTournamentParticipator - проверка на соответствие Id пользователя с Id участников соревнования.
+	UpdateTournament(tour models.Tournament, user int) error                                                                                         // UpdateTournament - обновление основных данных о соревновании.
+	DeleteTournament(tourId, userId int) error                                                                                                       // DeleteTournament - удаление данных о соревновании.
+	UpdateUser(user models.User) error                                                                                                               // UpdateUser - обновление основных данных о пользователе.
+	ArchiveTournament(tourId int) error                                                                                                              // ArchiveTournament - фиксация итоговых результатов завершившегося соревнования.
+	CheckTournamentArchived(tourId int) (bool, error)                                                                                                // CheckTournamentArchived - проверка на то, что итоги соревнования зафиксированы.
+	GetTournamentsToArchive() ([]models.Tournament, error)                                                                                           // GetTournamentsToArchive - получение завершившихся соревнований, итоги которых ещё не зафиксированы.
+	GetArchivedTournaments(user int) ([]models.Tournament, error)                                                                                    // GetArchivedTournaments - получение доступных пользователю прошедших соревнований.
+	GetTournamentWinners(tourId int) ([]models.TourRouteWinner, error)                                                                               // GetTournamentWinners - получение победителей соревнования на маршрутах.
+	GetUserPlacements(user int) ([]models.TourResult, error)                                                                                         // GetUserPlacements - получение итоговых мест пользователя в соревнованиях.
+	AddTeam(team models.Team) (int, error)                                                                                                           // AddTeam - добавление новой команды в соревнование вместе с её капитаном.
+	GetTeam(id int) (models.Team, error)                                                                                                             // GetTeam - получение команды по id.
+	GetTournamentTeams(tourId int) ([]models.Team, error)                                                                                            // GetTournamentTeams - получение команд соревнования.
+	GetTeamMembers(teamId int) ([]models.User, error)                                                                                                // GetTeamMembers - получение участников команды.
+	GetUserTeam(tourId, userId int) (models.Team, error)                                                                                             // GetUserTeam - получение команды пользователя в соревновании.
+	GetUserTeamInvites(tourId, userId int) ([]models.Team, error)                                                                                    // GetUserTeamInvites - получение команд соревнования, в которые приглашён пользователь.
+	InviteToTeam(teamId, userId, captainId int) error                                                                                                // InviteToTeam - приглашение пользователя в команду её капитаном.
+	JoinTeam(teamId, userId int) error                                                                                                               // JoinTeam - вступление приглашённого пользователя в команду с проверками участия в соревновании.
+	LeaveTeam(teamId, userId int) error                                                                                                              // LeaveTeam - выход пользователя из команды.
+	GetTournamentTeamRatings(tourId int) ([]models.TeamRating, error)                                                                                // GetTournamentTeamRatings - получение командного рейтинга по соревнованию.
+	GetTournamentParticipants(tourId int) ([]models.User, error)                                                                                     // GetTournamentParticipants - получение участников соревнования, в том числе через команды.
+	GetUserBestSprintInWindow(userId, routeId int, from, to time.Time) (models.Sprint, error)                                                        // GetUserBestSprintInWindow - получение лучшего успешного спринта пользователя на маршруте за промежуток времени.
+	AddBracket(b models.Bracket, matches []models.BracketMatch, userId int) error                                                                    // AddBracket - добавление сетки соревнования на выбывание.
+	GetBracket(tourId int) (models.Bracket, error)                                                                                                   // GetBracket - получение сетки соревнования.
+	GetBracketMatches(tourId int) ([]models.BracketMatch, error)                                                                                     // GetBracketMatches - получение матчей сетки соревнования.
+	ResolveBracketMatch(tourId, num, winner int) error                                                                                               // ResolveBracketMatch - фиксация победителя матча сетки с продвижением участников.
+	DeleteBracket(tourId, userId int) error                                                                                                          // DeleteBracket - удаление сетки соревнования.
+	GetUsersByName(name string) ([]models.User, error)                                                                                               // GetUsersByName - получение пользователей по имени.
+	InviteUserToTour(tu models.TURelation, inviterId int) error                                                                                      // InviteUserToTour - приглашение пользователя в соревнование его создателем.
+	GetUserTourInvites(userId int) ([]models.Tournament, error)                                                                                      // GetUserTourInvites - получение незавершённых соревнований, в которые приглашён пользователь.
+	CheckTourInvite(tourId, userId int) (bool, error)                                                                                                // CheckTourInvite - проверка наличия приглашения пользователя в соревнование.
+	AcceptTourInvite(tourId, userId int) error                                                                                                       // AcceptTourInvite - принятие приглашения в соревнование.
+	DeclineTourInvite(tourId, userId int) error                                                                                                      // DeclineTourInvite - отклонение приглашения в соревнование.
+	AddTourInviteLink(tourId int, tokenHash string, expiresAt time.Time, userId int) error                                                           // AddTourInviteLink - добавление одноразовой ссылки-приглашения в соревнование.
+	UseTourInviteLink(tokenHash string, userId int) (int, error)                                                                                     // UseTourInviteLink - вступление в соревнование по одноразовой ссылке-приглашению.
+	AddTourJoinRequest(tourId, userId int) error                                                                                                     // AddTourJoinRequest - добавление заявки на участие в соревновании.
+	CheckTourJoinRequest(tourId, userId int) (bool, error)                                                                                           // CheckTourJoinRequest - проверка наличия заявки на участие в соревновании.
+	GetTourJoinRequests(tourId int) ([]models.User, error)                                                                                           // GetTourJoinRequests - получение пользователей, подавших заявку на участие в соревновании.
+	ApproveTourJoinRequest(tu models.TURelation, creatorId int) error                                                                                // ApproveTourJoinRequest - одобрение заявки на участие в соревновании.
+	RejectTourJoinRequest(tu models.TURelation, creatorId int) error                                                                                 // RejectTourJoinRequest - отклонение заявки на участие в соревновании.
+	KickUserFromTour(tu models.TURelation, creatorId int, ban bool) error                                                                            // KickUserFromTour - исключение участника из соревнования с возможной блокировкой.
+	GetTourBans(tourId int) ([]models.User, error)                                                                                                   // GetTourBans - получение заблокированных в соревновании пользователей.
+	UnbanUserInTour(tu models.TURelation, creatorId int) error                                                                                       // UnbanUserInTour - снятие блокировки пользователя в соревновании.
+	AddUserToken(token models.UserToken) error                                                                                                       // AddUserToken - добавление одноразового токена пользователя взамен прежних токенов того же вида.
+	VerifyUserEmail(tokenHash string) error                                                                                                          // VerifyUserEmail - подтверждение почты пользователя по токену.
+	ResetUserPassword(tokenHash, password string) error                                                                                              // ResetUserPassword - сброс пароля пользователя по токену.
+	ConfirmUserEmail(tokenHash string) (models.UserToken, error)                                                                                     // ConfirmUserEmail - смена почты пользователя по токену подтверждения.
+	DeleteExpiredUserTokens() error                                                                                                                  // DeleteExpiredUserTokens - удаление истёкших одноразовых токенов.
+	ChangeUserPassword(userId int, password string) error                                                                                            // ChangeUserPassword - смена пароля пользователя с завершением всех его сессий.
+	DeleteUser(userId int) error                                                                                                                     // DeleteUser - удаление аккаунта пользователя с обезличиванием его данных.
+	GetUserByIdentity(provider, subject string) (models.User, error)                                                                                 // GetUserByIdentity - получение пользователя по привязке к внешнему провайдеру входа.
+	AddUserIdentity(provider, subject string, userId int) error                                                                                      // AddUserIdentity - привязка пользователя к внешнему провайдеру входа с подтверждением его почты.
+	AddUserWithIdentity(user models.User, provider, subject string) (int, error)                                                                     // AddUserWithIdentity - добавление пользователя с подтверждённой почтой и привязкой к внешнему провайдеру.
+	AddApiToken(token models.ApiToken) (int, error)                                                                                                  // AddApiToken - добавление персонального токена доступа к API.
+	GetUserApiTokens(userId int) ([]models.ApiToken, error)                                                                                          // GetUserApiTokens - получение персональных токенов пользователя.
+	UseApiToken(tokenHash string) (models.ApiToken, error)                                                                                           // UseApiToken - получение персонального токена по хэшу с фиксацией его использования.
+	DeleteApiToken(id, userId int) error                                                                                                             // DeleteApiToken - отзыв персонального токена пользователя.
+	GetUserStats(userId int) (models.UserStats, error)                                                                                               // GetUserStats - получение итоговой статистики пользователя.
+	GetUserBests(userId int) ([]models.PersonalBest, error)                                                                                          // GetUserBests - получение лучших спринтов пользователя на маршрутах.
+	GetUserActivity(userId int) ([]models.Activity, error)                                                                                           // GetUserActivity - получение активности пользователя по месяцам.
+	SetUserHidden(userId int, hidden bool) error                                                                                                     // SetUserHidden - скрытие или открытие профиля пользователя.
+	GetAchievementFacts(userId int) (models.AchievementFacts, error)                                                                                 // GetAchievementFacts - получение данных пользователя, по которым выдаются достижения.
+	AddUserAchievements(userId int, ids []string, at time.Time) ([]string, error)                                                                    // AddUserAchievements - выдача достижений пользователю, возвращает впервые выданные.
+	GetUserAchievements(userId int) ([]models.UserAchievement, error)                                                                                // GetUserAchievements - получение достижений пользователя.
+	GetUsersAchievements(userIds []int) ([]models.UserAchievement, error)                                                                            // GetUsersAchievements - получение достижений нескольких пользователей.
+	GetUsersIds() ([]int, error)                                                                                                                     // GetUsersIds - получение id всех пользователей.
+	GetSkillLadder() ([]models.PlayerSkill, error)                                                                                                   // GetSkillLadder - получение рейтинга мастерства всех игроков.
+	GetPlayerSkills(userIds []int) ([]models.PlayerSkill, error)                                                                                     // GetPlayerSkills - получение рейтинга мастерства игроков.
+	SavePlayerSkills(skills []models.PlayerSkill) error                                                                                              // SavePlayerSkills - сохранение рейтинга мастерства игроков.
+	ReplacePlayerSkills(skills []models.PlayerSkill) error                                                                                           // ReplacePlayerSkills - замена рейтинга мастерства всех игроков.
+	GetSuccessfulSprints() ([]models.Sprint, error)                                                                                                  // GetSuccessfulSprints - получение всех успешных спринтов без путей.
+	GetRatingsBetween(start, end time.Time) ([]models.TourRating, error)                                                                             // GetRatingsBetween - получение общего рейтинга по спринтам за период.
+	GetRouteRatingsBetween(routeId int, start, end time.Time) ([]models.RouteRating, error)                                                          // GetRouteRatingsBetween - получение рейтинга по маршруту за период.
+	AddSeason(season models.Season) (int, error)                                                                                                     // AddSeason - добавление сезона рейтинга.
+	GetSeason(id int) (models.Season, error)                                                                                                         // GetSeason - получение сезона по id.
+	GetSeasons() ([]models.Season, error)                                                                                                            // GetSeasons - получение всех сезонов.
+	GetSeasonRatings(seasonId int) ([]models.TourRating, error)                                                                                      // GetSeasonRatings - получение общего рейтинга сезона.
+	GetSeasonsToArchive() ([]models.Season, error)                                                                                                   // GetSeasonsToArchive - получение закончившихся сезонов, итоги которых ещё не зафиксированы.
+	ArchiveSeason(seasonId int) error                                                                                                                // ArchiveSeason - фиксация итогов закончившегося сезона.
+	GetRatingsAmong(usersIds []int) ([]models.TourRating, error)                                                                                     // GetRatingsAmong - получение общего рейтинга среди выбранных пользователей.
+	GetRatingsAmongBetween(usersIds []int, start, end time.Time) ([]models.TourRating, error)                                                        // GetRatingsAmongBetween - получение общего рейтинга среди выбранных пользователей за период.
+	AddFollow(followerId, followeeId int) error                                                                                                      // AddFollow - подписка пользователя на игрока.
+	DeleteFollow(followerId, followeeId int) error                                                                                                   // DeleteFollow - отмена подписки пользователя на игрока.
+	IsFollowing(followerId, followeeId int) (bool, error)                                                                                            // IsFollowing - проверка подписки пользователя на игрока.
+	GetFollowing(userId, viewerId int) ([]models.Follow, error)                                                                                      // GetFollowing - получение игроков, на которых подписан пользователь, скрытые профили видны только ему самому.
+	GetFollowingIds(userId int) ([]int, error)                                                                                                       // GetFollowingIds - получение id игроков, на которых подписан пользователь.
+	GetFollowFeed(userId, limit int) ([]models.FeedSprint, error)                                                                                    // GetFollowFeed - получение последних спринтов игроков, на которых подписан пользователь.
+	GetRouteRecordSprint(routeId int) (models.Sprint, error)                                                                                         // GetRouteRecordSprint - получение рекордного спринта на маршруте.
+	GetUserBestSprint(userId, routeId int) (models.Sprint, error)                                                                                    // GetUserBestSprint - получение лучшего успешного спринта пользователя на маршруте.
+	AddGhostResult(result models.GhostResult) error                                                                                                  // AddGhostResult - запись итога гонки с призраком.
+	GetGhostResult(sprintId int) (models.GhostResult, error)                                                                                         // GetGhostResult - получение итога гонки с призраком по спринту.
+	GetSprintPaths() ([]models.PathSprint, error)                                                                                                    // GetSprintPaths - получение путей всех спринтов для расчёта статистики.
+	GetPathInsights(routeId int) ([]models.PathInsight, error)                                                                                       // GetPathInsights - получение посчитанной статистики путей маршрута, 0 - по всем маршрутам.
+	ReplacePathInsights(insights []models.PathInsight) error                                                                                         // ReplacePathInsights - замена всей посчитанной статистики путей.
+	EachUserSprint(userId int, fn func(models.ExportSprint) error) error                                                                             // EachUserSprint - построчная выгрузка истории спринтов пользователя.
+	EachTourSprint(tourId int, fn func(models.ExportSprint) error) error                                                                             // EachTourSprint - построчная выгрузка всех спринтов соревнования.
+	EachRouteRating(routeId int, start, end *time.Time, fn func(models.ExportRouteRating) error) error                                               // EachRouteRating - построчная выгрузка рейтинга маршрута за период, nil - без ограничения.
+	ImportTournament(tour models.Tournament, routes []models.Route, creators []int, userId int) (int, error)                                         // ImportTournament - атомарное создание соревнования с маршрутами и соавторами.
+	ImportTemplateTournament(templateId int, next time.Time, tour models.Tournament, routes []models.Route, creators []int, userId int) (int, error) // ImportTemplateTournament - атомарное создание соревнования по шаблону вместе со сдвигом времени следующего запуска.
+	AddTourTemplate(tmpl models.TourTemplate) (int, error)                                                                                           // AddTourTemplate - сохранение шаблона соревнования.
+	GetTourTemplate(id int) (models.TourTemplate, error)                                                                                             // GetTourTemplate - получение шаблона соревнования по id.
+	GetUserTourTemplates(userId int) ([]models.TourTemplate, error)                                                                                  // GetUserTourTemplates - получение шаблонов соревнований пользователя.
+	GetDueTourTemplates(before time.Time) ([]models.TourTemplate, error)                                                                             // GetDueTourTemplates - получение повторяющихся шаблонов, по которым пора создать соревнование.
+	SetTemplateNextStart(id int, next time.Time) error                                                                                               // SetTemplateNextStart - обновление времени начала следующего соревнования по шаблону.
+	DisableTemplateRecurrence(id int) error                                                                                                          // DisableTemplateRecurrence - отключение повторения шаблона, после него соревнования создаются только вручную.
+	DeleteTourTemplate(id, userId int) error                                                                                                         // DeleteTourTemplate - удаление шаблона соревнования его владельцем.
+	AddCollection(collection models.Collection) (int, error)                                                                                         // AddCollection - создание подборки маршрутов.
+	GetCollection(id int) (models.Collection, error)                                                                                                 // GetCollection - получение подборки по id.
+	GetUserCollections(userId int) ([]models.Collection, error)                                                                                      // GetUserCollections - получение подборок пользователя.
+	GetPublicCollections() ([]models.Collection, error)                                                                                              // GetPublicCollections - получение открытых подборок.
+	GetCollectionRoutes(collectionId, userId int) ([]models.CollectionRoute, error)                                                                  // GetCollectionRoutes - получение маршрутов подборки с отметкой их прохождения пользователем.
+	AddRouteToCollection(collectionId, routeId int) error                                                                                            // AddRouteToCollection - добавление маршрута в конец подборки.
+	RemoveRouteFromCollection(collectionId, routeId int) error                                                                                       // RemoveRouteFromCollection - удаление маршрута из подборки.
+	MoveCollectionRoute(collectionId, routeId int, up bool) error                                                                                    // MoveCollectionRoute - перемещение маршрута подборки на одну позицию вверх или вниз.
+	ToggleCollectionPrivacy(id, userId int) error                                                                                                    // ToggleCollectionPrivacy - переключение видимости подборки её автором.
+	DeleteCollection(id, userId int) error                                                                                                           // DeleteCollection - удаление подборки её автором.
+	AddCollectionToTour(tourId, collectionId, userId int) (int, error)                                                                               // AddCollectionToTour - добавление в соревнование маршрутов подборки, возвращает количество добавленных.
+	GetRouteComments(routeId int) ([]models.RouteComment, error)                                                                                     // GetRouteComments - получение комментариев к маршруту в порядке написания.
+	GetRouteComment(id int) (models.RouteComment, error)                                                                                             // GetRouteComment - получение комментария по id.
+	AddRouteComment(comment models.RouteComment) (int, error)                                                                                        // AddRouteComment - добавление комментария или ответа к маршруту.
+	HideRouteComment(id int) error                                                                                                                   // HideRouteComment - скрытие комментария.
+	GetRouteVotes(routeId, userId int) (models.RouteVotes, error)                                                                                    // GetRouteVotes - получение средней оценки маршрута и оценки пользователя.
+	SetRouteVote(routeId, userId, stars int) error                                                                                                   // SetRouteVote - оценка маршрута пользователем.
+	AddRouteReport(report models.RouteReport, hideAfter int) (bool, error)                                                                           // AddRouteReport - добавление жалобы, возвращает, скрыт ли комментарий после hideAfter жалоб.
+	GetOpenReports() ([]models.RouteReport, error)                                                                                                   // GetOpenReports - получение нерассмотренных жалоб.
+	ResolveRouteReport(id int, resolution string, hide bool) error                                                                                   // ResolveRouteReport - рассмотрение жалобы, при hide комментарий скрывается.
 }
 
 // Get - функция, возвращающая объект, реализующий интерфейс DbHandler.
@@ -1,22 +1,38 @@
 package postgres
 
 import (
+	"database/sql"
 	"errors"
+	"slices"
 	"sort"
 	"time"
 
+	"github.com/famusovsky/WikiSurfBack/internal/bracket"
 	"github.com/famusovsky/WikiSurfBack/internal/models"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type dbProcessor struct {
 	db *sqlx.DB
 }
 
+// uniqueViolation - код ошибки PostgreSQL при нарушении уникальности.
+const uniqueViolation pq.ErrorCode = "23505"
+
 var (
-	errBeginTx    = errors.New("error while starting transaction")
-	errCommitTx   = errors.New("error while committing transaction")
-	errNotCreator = errors.New("user is not the tournament's creator")
+	errBeginTx        = errors.New("error while starting transaction")
+	errCommitTx       = errors.New("error while committing transaction")
+	errNotCreator     = errors.New("user is not the tournament's creator")
+	errNotCaptain     = errors.New("user is not the team's captain")
+	errNotInvited     = errors.New("user is not invited to the team")
+	errBanned         = errors.New("user is banned from the tournament")
+	errTourFull       = errors.New("the tournament has reached its participant limit")
+	errNotParticipant = errors.New("the tournament is private: join it before joining its team")
+	errNoInvite       = errors.New("user is not invited to the tournament")
+	errBadLink        = errors.New("the invite link is invalid, expired or already used")
+	errBadToken       = errors.New("the link is invalid, expired or already used")
+	errReported       = errors.New("you have already reported it")
 )
 
 // AddUser implements DbHandler.
@@ -74,10 +90,19 @@ func (d *dbProcessor) AddSprint(sprint models.Sprint) (int, error) {
 	}
 	defer tx.Rollback()
 
+	// старые клиенты не присылают время шагов, а nil массив записался бы как NULL.
+	if sprint.StepTimes == nil {
+		sprint.StepTimes = pq.Int64Array{}
+	}
+	if sprint.BackSteps == nil {
+		sprint.BackSteps = pq.BoolArray{}
+	}
+
 	var id int
 	// path, _ := json.Marshal(sprint.Path)
 	if err = tx.QueryRow(addSprint, sprint.StartTime, sprint.LengthTime, sprint.Success,
-		sprint.RouteId, sprint.UserId /*sprint.TournamentId,*/, sprint.Path).Scan(&id); err != nil {
+		sprint.RouteId, sprint.UserId /*sprint.TournamentId,*/, sprint.Path,
+		sprint.StepTimes, sprint.BackSteps).Scan(&id); err != nil {
 		return 0, errors.Join(wrapErr, err)
 	}
 
@@ -100,7 +125,7 @@ func (d *dbProcessor) AddTournament(tour models.Tournament, userId int) (int, er
 
 	var id int
 
-	if err := tx.QueryRow(addTour, tour.StartTime, tour.EndTime, tour.Pswd, tour.Private).Scan(&id); err != nil {
+	if err := tx.QueryRow(addTour, tour.StartTime, tour.EndTime, tour.Pswd, tour.Private, tour.MaxUsers).Scan(&id); err != nil {
 		return 0, errors.Join(wrapErr, err)
 	}
 
@@ -115,6 +140,97 @@ func (d *dbProcessor) AddTournament(tour models.Tournament, userId int) (int, er
 	return id, nil
 }
 
+// ImportTournament implements DbHandler.
+func (d *dbProcessor) ImportTournament(tour models.Tournament, routes []models.Route, creators []int, userId int) (int, error) {
+	wrapErr := errors.New("error while importing tournament to the database")
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return 0, errors.Join(wrapErr, errBeginTx, err)
+	}
+	defer tx.Rollback()
+
+	id, err := importTournamentTx(tx, tour, routes, creators, userId)
+	if err != nil {
+		return 0, errors.Join(wrapErr, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, errors.Join(wrapErr, errCommitTx, err)
+	}
+
+	return id, nil
+}
+
+// ImportTemplateTournament implements DbHandler.
+func (d *dbProcessor) ImportTemplateTournament(templateId int, next time.Time, tour models.Tournament, routes []models.Route, creators []int, userId int) (int, error) {
+	wrapErr := errors.New("error while importing tournament from the template to the database")
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return 0, errors.Join(wrapErr, errBeginTx, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(updateTemplateNextStart, templateId, next); err != nil {
+		return 0, errors.Join(wrapErr, err)
+	}
+
+	id, err := importTournamentTx(tx, tour, routes, creators, userId)
+	if err != nil {
+		return 0, errors.Join(wrapErr, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, errors.Join(wrapErr, errCommitTx, err)
+	}
+
+	return id, nil
+}
+
+// importTournamentTx - функция, создающая в транзакции соревнование с маршрутами и соавторами.
+//
+// Возвращает: id созданного соревнования.
+func importTournamentTx(tx *sql.Tx, tour models.Tournament, routes []models.Route, creators []int, userId int) (int, error) {
+	var id int
+	if err := tx.QueryRow(addTour, tour.StartTime, tour.EndTime, tour.Pswd, tour.Private, tour.MaxUsers).Scan(&id); err != nil {
+		return 0, err
+	}
+
+	added := map[int]bool{}
+	for _, creator := range append([]int{userId}, creators...) {
+		if added[creator] {
+			continue
+		}
+		added[creator] = true
+		if _, err := tx.Exec(addCreatorToTour, id, creator); err != nil {
+			return 0, err
+		}
+	}
+
+	added = map[int]bool{}
+	for _, route := range routes {
+		var routeId int
+		err := tx.QueryRow(getRouteIdByCreds, route.Start, route.Finish).Scan(&routeId)
+		if errors.Is(err, sql.ErrNoRows) {
+			err = tx.QueryRow(addRoute, route.Start, route.Finish, userId).Scan(&routeId)
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		if added[routeId] {
+			continue
+		}
+		added[routeId] = true
+		if _, err := tx.Exec(addRouteToTour, id, routeId); err != nil {
+			return 0, err
+		}
+	}
+
+	return id, nil
+}
+
 // AddRouteToTour implements DbHandler.
 func (d *dbProcessor) AddRouteToTour(tr models.TRRelation, userId int) error {
 	wrapErr := errors.New("error while adding route to the tournament in the database")
@@ -177,13 +293,13 @@ func (d *dbProcessor) AddCreatorToTour(tu models.TURelation, userId int) error {
 func (d *dbProcessor) AddUserToTour(tourId, userId int) error {
 	wrapErr := errors.New("error while adding user to the tournament in the database")
 
-	tx, err := d.db.Begin()
+	tx, err := d.db.Beginx()
 	if err != nil {
 		return errors.Join(wrapErr, errBeginTx, err)
 	}
 	defer tx.Rollback()
 
-	if _, err := tx.Exec(addUserToTour, tourId, userId); err != nil {
+	if err := addUserToTourTx(tx, tourId, userId); err != nil {
 		return errors.Join(wrapErr, err)
 	}
 
@@ -329,27 +445,73 @@ func (d *dbProcessor) GetRouteRatings(routeId int) ([]models.RouteRating, error)
 	return ratings, nil
 }
 
+// GetRouteRatingsBetween implements DbHandler.
+func (d *dbProcessor) GetRouteRatingsBetween(routeId int, start, end time.Time) ([]models.RouteRating, error) {
+	var ratings []models.RouteRating
+
+	if err := d.db.Select(&ratings, getRouteTourBest, routeId, start, end); err != nil {
+		return []models.RouteRating{}, errors.Join(errors.New("error while getting route ratings for the period from the database"), err)
+	}
+
+	return ratings, nil
+}
+
 // GetTournamentRatings implements DbHandler.
 func (d *dbProcessor) GetTournamentRatings(tourId int) ([]models.TourRating, error) {
 	wrapErr := errors.New("error while getting tournament ratings from the database")
 
-	var routes []models.Route
-
-	if err := d.db.Select(&routes, getTournamentRoutes, tourId); err != nil {
+	archived, err := d.CheckTournamentArchived(tourId)
+	if err != nil {
 		return []models.TourRating{}, errors.Join(wrapErr, err)
 	}
 
+	if archived {
+		var results []models.TourResult
+		if err := d.db.Select(&results, getTournamentResults, tourId); err != nil {
+			return []models.TourRating{}, errors.Join(wrapErr, err)
+		}
+
+		ratings := make([]models.TourRating, len(results))
+		for i, r := range results {
+			ratings[i] = models.TourRating{
+				UserId:   r.UserId,
+				UserName: r.UserName,
+				Points:   r.Points,
+			}
+		}
+
+		return ratings, nil
+	}
+
 	tour, err := d.GetTournament(tourId)
 	if err != nil {
 		return []models.TourRating{}, errors.Join(wrapErr, err)
 	}
 
+	ratings, _, err := d.getTournamentStandings(tour)
+	if err != nil {
+		return []models.TourRating{}, errors.Join(wrapErr, err)
+	}
+
+	return ratings, nil
+}
+
+// getTournamentStandings - функция, вычисляющая по спринтам текущий рейтинг соревнования и победителей на его маршрутах.
+func (d *dbProcessor) getTournamentStandings(tour models.Tournament) ([]models.TourRating, []models.TourRouteWinner, error) {
+	var routes []models.Route
+
+	if err := d.db.Select(&routes, getTournamentRoutes, tour.Id); err != nil {
+		return nil, nil, err
+	}
+
 	users := map[int]int{}
+	names := map[int]string{}
+	winners := make([]models.TourRouteWinner, 0, len(routes))
 	for i := 0; i < len(routes); i++ {
 		var rr []models.RouteRating
 
 		if err := d.db.Select(&rr, getRouteTourBest, routes[i].Id, tour.StartTime, tour.EndTime); err != nil {
-			return []models.TourRating{}, errors.Join(wrapErr, err)
+			return nil, nil, err
 		}
 
 		if len(rr) == 0 {
@@ -357,18 +519,23 @@ func (d *dbProcessor) GetTournamentRatings(tourId int) ([]models.TourRating, err
 		}
 
 		sort.Slice(rr, func(i, j int) bool {
+			if rr[i].SprintLengthTime == rr[j].SprintLengthTime {
+				return rr[i].SprintId < rr[j].SprintId
+			}
 			return rr[i].SprintLengthTime < rr[j].SprintLengthTime
 		})
 
-		min, id := rr[0].SprintLengthTime, rr[0].UserId
-		for j := 1; j < len(rr); j++ {
-			if rr[j].SprintLengthTime < min {
-				min = rr[j].SprintLengthTime
-				id = rr[j].UserId
-			}
-		}
-
-		users[id]++
+		best := rr[0]
+		users[best.UserId]++
+		winners = append(winners, models.TourRouteWinner{
+			TournamentId: tour.Id,
+			RouteId:      routes[i].Id,
+			Start:        routes[i].Start,
+			Finish:       routes[i].Finish,
+			UserId:       best.UserId,
+			SprintId:     best.SprintId,
+			LengthTime:   best.SprintLengthTime,
+		})
 	}
 
 	ratings := make([]models.TourRating, 0, len(users))
@@ -378,21 +545,199 @@ func (d *dbProcessor) GetTournamentRatings(tourId int) ([]models.TourRating, err
 		if err := d.db.Get(&name, "SELECT name FROM users WHERE id = $1", id); err != nil {
 			name = "Unknown Name - (try reload the window)"
 		}
+		names[id] = name
 		ratings = append(ratings, models.TourRating{
+			UserId:   id,
 			UserName: name,
 			Points:   points,
 		})
 	}
 
+	for i := range winners {
+		winners[i].UserName = names[winners[i].UserId]
+	}
+
 	sort.Slice(ratings, func(i, j int) bool {
+		if ratings[i].Points == ratings[j].Points {
+			return ratings[i].UserName < ratings[j].UserName
+		}
 		return ratings[i].Points > ratings[j].Points
 	})
 
-	return ratings, nil
+	return ratings, winners, nil
+}
+
+// ArchiveTournament implements DbHandler.
+func (d *dbProcessor) ArchiveTournament(tourId int) error {
+	wrapErr := errors.New("error while archiving the tournament in the database")
+
+	tour, err := d.GetTournament(tourId)
+	if err != nil {
+		return errors.Join(wrapErr, err)
+	}
+
+	ratings, winners, err := d.getTournamentStandings(tour)
+	if err != nil {
+		return errors.Join(wrapErr, err)
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return errors.Join(wrapErr, errBeginTx, err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(addTourArchive, tourId, time.Now())
+	if err != nil {
+		return errors.Join(wrapErr, err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		// Соревнование уже было архивировано.
+		return nil
+	}
+
+	place := 0
+	for i, r := range ratings {
+		if i == 0 || r.Points != ratings[i-1].Points {
+			place = i + 1
+		}
+		if _, err := tx.Exec(addTourResult, tourId, r.UserId, r.UserName, place, r.Points); err != nil {
+			return errors.Join(wrapErr, err)
+		}
+	}
+
+	for _, w := range winners {
+		if _, err := tx.Exec(addTourWinner, tourId, w.RouteId, w.UserId, w.UserName, w.SprintId, w.LengthTime); err != nil {
+			return errors.Join(wrapErr, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Join(wrapErr, errCommitTx, err)
+	}
+
+	return nil
+}
+
+// CheckTournamentArchived implements DbHandler.
+func (d *dbProcessor) CheckTournamentArchived(tourId int) (bool, error) {
+	var cnt int
+
+	if err := d.db.Get(&cnt, checkTournamentArchived, tourId); err != nil {
+		return false, errors.Join(errors.New("error while checking tournament's archivation in the database"), err)
+	}
+
+	return cnt > 0, nil
+}
+
+// GetTournamentsToArchive implements DbHandler.
+func (d *dbProcessor) GetTournamentsToArchive() ([]models.Tournament, error) {
+	var res []models.Tournament
+
+	if err := d.db.Select(&res, getTournamentsToArchive, time.Now()); err != nil {
+		return []models.Tournament{}, errors.Join(errors.New("error while getting finished tournaments from the database"), err)
+	}
+
+	return res, nil
+}
+
+// GetArchivedTournaments implements DbHandler.
+func (d *dbProcessor) GetArchivedTournaments(user int) ([]models.Tournament, error) {
+	var res []models.Tournament
+
+	if err := d.db.Select(&res, getArchivedTournaments, user); err != nil {
+		return []models.Tournament{}, errors.Join(errors.New("error while getting archived tournaments from the database"), err)
+	}
+
+	return res, nil
+}
+
+// GetTournamentWinners implements DbHandler.
+func (d *dbProcessor) GetTournamentWinners(tourId int) ([]models.TourRouteWinner, error) {
+	wrapErr := errors.New("error while getting tournament winners from the database")
+
+	archived, err := d.CheckTournamentArchived(tourId)
+	if err != nil {
+		return []models.TourRouteWinner{}, errors.Join(wrapErr, err)
+	}
+
+	if !archived {
+		tour, err := d.GetTournament(tourId)
+		if err != nil {
+			return []models.TourRouteWinner{}, errors.Join(wrapErr, err)
+		}
+
+		_, winners, err := d.getTournamentStandings(tour)
+		if err != nil {
+			return []models.TourRouteWinner{}, errors.Join(wrapErr, err)
+		}
+
+		return winners, nil
+	}
+
+	var winners []models.TourRouteWinner
+	if err := d.db.Select(&winners, getTournamentWinners, tourId); err != nil {
+		return []models.TourRouteWinner{}, errors.Join(wrapErr, err)
+	}
+
+	return winners, nil
+}
+
+// GetUserPlacements implements DbHandler.
+func (d *dbProcessor) GetUserPlacements(user int) ([]models.TourResult, error) {
+	var res []models.TourResult
+
+	if err := d.db.Select(&res, getUserPlacements, user); err != nil {
+		return []models.TourResult{}, errors.Join(errors.New("error while getting user's tournament placements from the database"), err)
+	}
+
+	return res, nil
 }
 
 // GetTournamentRatings implements DbHandler.
 func (d *dbProcessor) GetRatings() ([]models.TourRating, error) {
+	return d.getRecordRatings(func(routeId int) ([]models.RouteRating, error) {
+		return d.GetRouteRatings(routeId)
+	})
+}
+
+// GetRatingsBetween implements DbHandler.
+func (d *dbProcessor) GetRatingsBetween(start, end time.Time) ([]models.TourRating, error) {
+	return d.getRecordRatings(func(routeId int) ([]models.RouteRating, error) {
+		return d.GetRouteRatingsBetween(routeId, start, end)
+	})
+}
+
+// GetRatingsAmong implements DbHandler.
+func (d *dbProcessor) GetRatingsAmong(usersIds []int) ([]models.TourRating, error) {
+	return d.getRecordRatings(amongUsers(usersIds, d.GetRouteRatings))
+}
+
+// GetRatingsAmongBetween implements DbHandler.
+func (d *dbProcessor) GetRatingsAmongBetween(usersIds []int, start, end time.Time) ([]models.TourRating, error) {
+	return d.getRecordRatings(amongUsers(usersIds, func(routeId int) ([]models.RouteRating, error) {
+		return d.GetRouteRatingsBetween(routeId, start, end)
+	}))
+}
+
+// amongUsers - функция, оставляющая в лучших результатах на маршруте только результаты выбранных пользователей.
+func amongUsers(usersIds []int, getBests func(routeId int) ([]models.RouteRating, error)) func(routeId int) ([]models.RouteRating, error) {
+	return func(routeId int) ([]models.RouteRating, error) {
+		rr, err := getBests(routeId)
+		if err != nil {
+			return rr, err
+		}
+
+		return slices.DeleteFunc(rr, func(r models.RouteRating) bool {
+			return !slices.Contains(usersIds, r.UserId)
+		}), nil
+	}
+}
+
+// getRecordRatings - функция, считающая рейтинг по количеству рекордов маршрутов.
+//
+// Принимает: функцию, возвращающую лучшие результаты пользователей на маршруте.
+func (d *dbProcessor) getRecordRatings(getBests func(routeId int) ([]models.RouteRating, error)) ([]models.TourRating, error) {
 	wrapErr := errors.New("error while getting ratings from the database")
 
 	var routes []int
@@ -402,9 +747,8 @@ func (d *dbProcessor) GetRatings() ([]models.TourRating, error) {
 
 	users := map[int]int{}
 	for i := 0; i < len(routes); i++ {
-		var rr []models.RouteRating
-
-		if err := d.db.Select(&rr, getRouteBest, routes[i]); err != nil {
+		rr, err := getBests(routes[i])
+		if err != nil {
 			return []models.TourRating{}, errors.Join(wrapErr, err)
 		}
 		if len(rr) == 0 {
@@ -432,6 +776,7 @@ func (d *dbProcessor) GetRatings() ([]models.TourRating, error) {
 			name = "Unknown Name - (try reload the window)"
 		}
 		ratings = append(ratings, models.TourRating{
+			UserId:   id,
 			UserName: name,
 			Points:   points,
 		})
@@ -606,7 +951,7 @@ func (d *dbProcessor) UpdateTournament(tour models.Tournament, user int) error {
 	}
 	defer tx.Rollback()
 
-	if _, err = tx.Exec(updateTournament, tour.Id, tour.StartTime, tour.EndTime, tour.Pswd, tour.Private); err != nil {
+	if _, err = tx.Exec(updateTournament, tour.Id, tour.StartTime, tour.EndTime, tour.Pswd, tour.Private, tour.MaxUsers); err != nil {
 		return errors.Join(wrapErr, err)
 	}
 
@@ -635,6 +980,42 @@ func (d *dbProcessor) DeleteTournament(tourId, userId int) error {
 	}
 	defer tx.Rollback()
 
+	if _, err = tx.Exec(deleteTourFromWinners, tourId); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+	if _, err = tx.Exec(deleteTourFromResults, tourId); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+	if _, err = tx.Exec(deleteTourFromArchives, tourId); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+	if _, err = tx.Exec(deleteBracketMatches, tourId); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+	if _, err = tx.Exec(deleteBracket, tourId); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+	if _, err = tx.Exec(deleteTourFromTourInvites, tourId); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+	if _, err = tx.Exec(deleteTourFromInviteLinks, tourId); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+	if _, err = tx.Exec(deleteTourFromJoinRequests, tourId); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+	if _, err = tx.Exec(deleteTourFromBans, tourId); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+	if _, err = tx.Exec(deleteTourFromInvites, tourId); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+	if _, err = tx.Exec(deleteTourFromTeamUsers, tourId); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+	if _, err = tx.Exec(deleteTourFromTeams, tourId); err != nil {
+		return errors.Join(wrapErr, err)
+	}
 	if _, err = tx.Exec(deleteTourFromRoutes, tourId); err != nil {
 		return errors.Join(wrapErr, err)
 	}
@@ -675,3 +1056,1986 @@ func (d *dbProcessor) UpdateUser(user models.User) error {
 
 	return nil
 }
+
+// AddTeam implements DbHandler.
+func (d *dbProcessor) AddTeam(team models.Team) (int, error) {
+	wrapErr := errors.New("error while inserting team to the database")
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return 0, errors.Join(wrapErr, errBeginTx, err)
+	}
+	defer tx.Rollback()
+
+	var id int
+
+	if err := tx.QueryRow(addTeam, team.TournamentId, team.Name, team.CaptainId).Scan(&id); err != nil {
+		return 0, errors.Join(wrapErr, err)
+	}
+
+	if _, err := tx.Exec(addUserToTeam, id, team.TournamentId, team.CaptainId); err != nil {
+		return 0, errors.Join(wrapErr, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, errors.Join(wrapErr, errCommitTx, err)
+	}
+
+	return id, nil
+}
+
+// GetTeam implements DbHandler.
+func (d *dbProcessor) GetTeam(id int) (models.Team, error) {
+	var team models.Team
+
+	if err := d.db.Get(&team, getTeam, id); err != nil {
+		return models.Team{}, errors.Join(errors.New("error while getting team from the database"), err)
+	}
+
+	return team, nil
+}
+
+// GetTournamentTeams implements DbHandler.
+func (d *dbProcessor) GetTournamentTeams(tourId int) ([]models.Team, error) {
+	var teams []models.Team
+
+	if err := d.db.Select(&teams, getTournamentTeams, tourId); err != nil {
+		return []models.Team{}, errors.Join(errors.New("error while getting tournament teams from the database"), err)
+	}
+
+	return teams, nil
+}
+
+// GetTeamMembers implements DbHandler.
+func (d *dbProcessor) GetTeamMembers(teamId int) ([]models.User, error) {
+	var users []models.User
+
+	if err := d.db.Select(&users, getTeamMembers, teamId); err != nil {
+		return []models.User{}, errors.Join(errors.New("error while getting team members from the database"), err)
+	}
+
+	return users, nil
+}
+
+// GetUserTeam implements DbHandler.
+func (d *dbProcessor) GetUserTeam(tourId, userId int) (models.Team, error) {
+	var team models.Team
+
+	if err := d.db.Get(&team, getUserTeam, tourId, userId); err != nil {
+		return models.Team{}, errors.Join(errors.New("error while getting user's team from the database"), err)
+	}
+
+	return team, nil
+}
+
+// GetUserTeamInvites implements DbHandler.
+func (d *dbProcessor) GetUserTeamInvites(tourId, userId int) ([]models.Team, error) {
+	var teams []models.Team
+
+	if err := d.db.Select(&teams, getUserTeamInvites, tourId, userId); err != nil {
+		return []models.Team{}, errors.Join(errors.New("error while getting user's team invites from the database"), err)
+	}
+
+	return teams, nil
+}
+
+// InviteToTeam implements DbHandler.
+func (d *dbProcessor) InviteToTeam(teamId, userId, captainId int) error {
+	wrapErr := errors.New("error while inviting user to the team in the database")
+
+	team, err := d.GetTeam(teamId)
+	if err != nil {
+		return errors.Join(wrapErr, err)
+	}
+	if team.CaptainId != captainId {
+		return errors.Join(wrapErr, errNotCaptain)
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return errors.Join(wrapErr, errBeginTx, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(addTeamInvite, teamId, userId); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Join(wrapErr, errCommitTx, err)
+	}
+
+	return nil
+}
+
+// JoinTeam implements DbHandler.
+func (d *dbProcessor) JoinTeam(teamId, userId int) error {
+	wrapErr := errors.New("error while adding user to the team in the database")
+
+	team, err := d.GetTeam(teamId)
+	if err != nil {
+		return errors.Join(wrapErr, err)
+	}
+
+	tx, err := d.db.Beginx()
+	if err != nil {
+		return errors.Join(wrapErr, errBeginTx, err)
+	}
+	defer tx.Rollback()
+
+	var tour models.Tournament
+	if err := tx.Get(&tour, getTournamentForUpdate, team.TournamentId); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+
+	var cnt int
+	if err := tx.Get(&cnt, checkTeamInvite, teamId, userId); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+	if cnt == 0 {
+		return errors.Join(wrapErr, errNotInvited)
+	}
+
+	// Участие в команде засчитывается как участие в соревновании,
+	// поэтому новичок проходит те же проверки, что и при вступлении в соревнование.
+	if err := tx.Get(&cnt, checkTournamentParticipator, tour.Id, userId); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+	if cnt == 0 {
+		if tour.Private {
+			return errors.Join(wrapErr, errNotParticipant)
+		}
+		if err := addUserToTourTx(tx, tour.Id, userId); err != nil {
+			return errors.Join(wrapErr, err)
+		}
+	} else {
+		if err := tx.Get(&cnt, checkTourBan, tour.Id, userId); err != nil {
+			return errors.Join(wrapErr, err)
+		}
+		if cnt > 0 {
+			return errors.Join(wrapErr, errBanned)
+		}
+	}
+
+	if _, err := tx.Exec(removeTeamInvite, teamId, userId); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+	if _, err := tx.Exec(addUserToTeam, teamId, team.TournamentId, userId); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Join(wrapErr, errCommitTx, err)
+	}
+
+	return nil
+}
+
+// LeaveTeam implements DbHandler.
+func (d *dbProcessor) LeaveTeam(teamId, userId int) error {
+	wrapErr := errors.New("error while removing user from the team in the database")
+
+	team, err := d.GetTeam(teamId)
+	if err != nil {
+		return errors.Join(wrapErr, err)
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return errors.Join(wrapErr, errBeginTx, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(removeUserFromTeam, teamId, userId); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+
+	if team.CaptainId == userId {
+		var next sql.NullInt64
+		if err := tx.QueryRow(getNextTeamCaptain, teamId).Scan(&next); err != nil {
+			return errors.Join(wrapErr, err)
+		}
+
+		if next.Valid {
+			if _, err := tx.Exec(updateTeamCaptain, teamId, next.Int64); err != nil {
+				return errors.Join(wrapErr, err)
+			}
+		} else {
+			if _, err := tx.Exec(deleteTeamInvites, teamId); err != nil {
+				return errors.Join(wrapErr, err)
+			}
+			if _, err := tx.Exec(deleteTeam, teamId); err != nil {
+				return errors.Join(wrapErr, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Join(wrapErr, errCommitTx, err)
+	}
+
+	return nil
+}
+
+// GetTournamentTeamRatings implements DbHandler.
+func (d *dbProcessor) GetTournamentTeamRatings(tourId int) ([]models.TeamRating, error) {
+	wrapErr := errors.New("error while getting tournament team ratings from the database")
+
+	ratings, err := d.GetTournamentRatings(tourId)
+	if err != nil {
+		return []models.TeamRating{}, errors.Join(wrapErr, err)
+	}
+
+	teams, err := d.GetTournamentTeams(tourId)
+	if err != nil {
+		return []models.TeamRating{}, errors.Join(wrapErr, err)
+	}
+
+	var relations []struct {
+		TeamId int `db:"team_id"`
+		UserId int `db:"user_id"`
+	}
+	if err := d.db.Select(&relations, getTournamentTeamUsers, tourId); err != nil {
+		return []models.TeamRating{}, errors.Join(wrapErr, err)
+	}
+
+	points := make(map[int]int, len(ratings))
+	for _, r := range ratings {
+		points[r.UserId] = r.Points
+	}
+
+	teamRatings := make([]models.TeamRating, len(teams))
+	index := make(map[int]int, len(teams))
+	for i, t := range teams {
+		teamRatings[i] = models.TeamRating{
+			TeamId:   t.Id,
+			TeamName: t.Name,
+		}
+		index[t.Id] = i
+	}
+
+	for _, rel := range relations {
+		i, ok := index[rel.TeamId]
+		if !ok {
+			continue
+		}
+		teamRatings[i].Members++
+		teamRatings[i].Points += points[rel.UserId]
+	}
+
+	sort.SliceStable(teamRatings, func(i, j int) bool {
+		return teamRatings[i].Points > teamRatings[j].Points
+	})
+
+	return teamRatings, nil
+}
+
+// GetTournamentParticipants implements DbHandler.
+func (d *dbProcessor) GetTournamentParticipants(tourId int) ([]models.User, error) {
+	var users []models.User
+
+	if err := d.db.Select(&users, getTournamentParticipants, tourId); err != nil {
+		return []models.User{}, errors.Join(errors.New("error while getting tournament participants from the database"), err)
+	}
+
+	return users, nil
+}
+
+// GetUserBestSprintInWindow implements DbHandler.
+func (d *dbProcessor) GetUserBestSprintInWindow(userId, routeId int, from, to time.Time) (models.Sprint, error) {
+	var sprint models.Sprint
+
+	if err := d.db.Get(&sprint, getUserBestSprintInWindow, userId, routeId, from, to); err != nil {
+		return models.Sprint{}, errors.Join(errors.New("error while getting user's best sprint from the database"), err)
+	}
+
+	return sprint, nil
+}
+
+// AddBracket implements DbHandler.
+func (d *dbProcessor) AddBracket(b models.Bracket, matches []models.BracketMatch, userId int) error {
+	wrapErr := errors.New("error while inserting bracket to the database")
+
+	ok, err := d.CheckTournamentCreator(b.TournamentId, userId)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.Join(wrapErr, errNotCreator)
+	}
+
+	tx, err := d.db.Beginx()
+	if err != nil {
+		return errors.Join(wrapErr, errBeginTx, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(addBracket, b.TournamentId, b.Kind, b.CreatedAt); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+
+	for _, m := range matches {
+		m.TournamentId = b.TournamentId
+		if _, err := tx.NamedExec(addBracketMatch, m); err != nil {
+			return errors.Join(wrapErr, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Join(wrapErr, errCommitTx, err)
+	}
+
+	return nil
+}
+
+// GetBracket implements DbHandler.
+func (d *dbProcessor) GetBracket(tourId int) (models.Bracket, error) {
+	var b models.Bracket
+
+	if err := d.db.Get(&b, getBracket, tourId); err != nil {
+		return models.Bracket{}, errors.Join(errors.New("error while getting bracket from the database"), err)
+	}
+
+	return b, nil
+}
+
+// GetBracketMatches implements DbHandler.
+func (d *dbProcessor) GetBracketMatches(tourId int) ([]models.BracketMatch, error) {
+	var matches []models.BracketMatch
+
+	if err := d.db.Select(&matches, getBracketMatches, tourId); err != nil {
+		return []models.BracketMatch{}, errors.Join(errors.New("error while getting bracket matches from the database"), err)
+	}
+
+	return matches, nil
+}
+
+// ResolveBracketMatch implements DbHandler.
+func (d *dbProcessor) ResolveBracketMatch(tourId, num, winner int) error {
+	wrapErr := errors.New("error while resolving bracket match in the database")
+
+	tx, err := d.db.Beginx()
+	if err != nil {
+		return errors.Join(wrapErr, errBeginTx, err)
+	}
+	defer tx.Rollback()
+
+	var matches []models.BracketMatch
+	if err := tx.Select(&matches, getBracketMatchesForUpdate, tourId); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+	before := append([]models.BracketMatch(nil), matches...)
+
+	if err := bracket.SetWinner(matches, num, winner); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+	bracket.MarkReady(matches, time.Now())
+
+	for i, m := range matches {
+		if m == before[i] {
+			continue
+		}
+		if _, err := tx.Exec(updateBracketMatch, tourId, m.Num, m.Player1, m.Player2, m.Winner, m.ReadyAt); err != nil {
+			return errors.Join(wrapErr, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Join(wrapErr, errCommitTx, err)
+	}
+
+	return nil
+}
+
+// DeleteBracket implements DbHandler.
+func (d *dbProcessor) DeleteBracket(tourId, userId int) error {
+	wrapErr := errors.New("error while deleting bracket from the database")
+
+	ok, err := d.CheckTournamentCreator(tourId, userId)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.Join(wrapErr, errNotCreator)
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return errors.Join(wrapErr, errBeginTx, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(deleteBracketMatches, tourId); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+	if _, err := tx.Exec(deleteBracket, tourId); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Join(wrapErr, errCommitTx, err)
+	}
+
+	return nil
+}
+
+// addUserToTourTx - функция, добавляющая пользователя в соревнование в рамках транзакции
+// с проверкой блокировки и ограничения на количество участников.
+func addUserToTourTx(tx *sqlx.Tx, tourId, userId int) error {
+	var tour models.Tournament
+	if err := tx.Get(&tour, getTournamentForUpdate, tourId); err != nil {
+		return err
+	}
+
+	var cnt int
+	if err := tx.Get(&cnt, checkTournamentParticipator, tourId, userId); err != nil {
+		return err
+	}
+	if cnt > 0 {
+		return nil
+	}
+
+	if err := tx.Get(&cnt, checkTourBan, tourId, userId); err != nil {
+		return err
+	}
+	if cnt > 0 {
+		return errBanned
+	}
+
+	if tour.MaxUsers > 0 {
+		if err := tx.Get(&cnt, getTournamentUsersCount, tourId); err != nil {
+			return err
+		}
+		if cnt >= tour.MaxUsers {
+			return errTourFull
+		}
+	}
+
+	if _, err := tx.Exec(addUserToTour, tourId, userId); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(removeTourInvite, tourId, userId); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(removeTourJoinRequest, tourId, userId); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetUsersByName implements DbHandler.
+func (d *dbProcessor) GetUsersByName(name string) ([]models.User, error) {
+	var users []models.User
+
+	if err := d.db.Select(&users, getUsersByName, name); err != nil {
+		return []models.User{}, errors.Join(errors.New("error while getting users by name from the database"), err)
+	}
+
+	return users, nil
+}
+
+// InviteUserToTour implements DbHandler.
+func (d *dbProcessor) InviteUserToTour(tu models.TURelation, inviterId int) error {
+	wrapErr := errors.New("error while inviting user to the tournament in the database")
+
+	ok, err := d.CheckTournamentCreator(tu.TournamentId, inviterId)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.Join(wrapErr, errNotCreator)
+	}
+
+	var cnt int
+	if err := d.db.Get(&cnt, checkTourBan, tu.TournamentId, tu.UserId); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+	if cnt > 0 {
+		return errors.Join(wrapErr, errBanned)
+	}
+
+	if _, err := d.db.Exec(addTourInvite, tu.TournamentId, tu.UserId, inviterId); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+
+	return nil
+}
+
+// GetUserTourInvites implements DbHandler.
+func (d *dbProcessor) GetUserTourInvites(userId int) ([]models.Tournament, error) {
+	var tours []models.Tournament
+
+	if err := d.db.Select(&tours, getUserTourInvites, userId, time.Now()); err != nil {
+		return []models.Tournament{}, errors.Join(errors.New("error while getting user's tournament invites from the database"), err)
+	}
+
+	return tours, nil
+}
+
+// CheckTourInvite implements DbHandler.
+func (d *dbProcessor) CheckTourInvite(tourId, userId int) (bool, error) {
+	var cnt int
+
+	if err := d.db.Get(&cnt, checkTourInvite, tourId, userId); err != nil {
+		return false, errors.Join(errors.New("error while checking tournament invite in the database"), err)
+	}
+
+	return cnt > 0, nil
+}
+
+// AcceptTourInvite implements DbHandler.
+func (d *dbProcessor) AcceptTourInvite(tourId, userId int) error {
+	wrapErr := errors.New("error while accepting the tournament invite in the database")
+
+	ok, err := d.CheckTourInvite(tourId, userId)
+	if err != nil {
+		return errors.Join(wrapErr, err)
+	}
+	if !ok {
+		return errors.Join(wrapErr, errNoInvite)
+	}
+
+	return d.AddUserToTour(tourId, userId)
+}
+
+// DeclineTourInvite implements DbHandler.
+func (d *dbProcessor) DeclineTourInvite(tourId, userId int) error {
+	if _, err := d.db.Exec(removeTourInvite, tourId, userId); err != nil {
+		return errors.Join(errors.New("error while declining the tournament invite in the database"), err)
+	}
+
+	return nil
+}
+
+// AddTourInviteLink implements DbHandler.
+func (d *dbProcessor) AddTourInviteLink(tourId int, tokenHash string, expiresAt time.Time, userId int) error {
+	wrapErr := errors.New("error while inserting the tournament invite link to the database")
+
+	ok, err := d.CheckTournamentCreator(tourId, userId)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.Join(wrapErr, errNotCreator)
+	}
+
+	if _, err := d.db.Exec(addTourInviteLink, tokenHash, tourId, expiresAt); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+
+	return nil
+}
+
+// UseTourInviteLink implements DbHandler.
+func (d *dbProcessor) UseTourInviteLink(tokenHash string, userId int) (int, error) {
+	wrapErr := errors.New("error while using the tournament invite link in the database")
+
+	tx, err := d.db.Beginx()
+	if err != nil {
+		return 0, errors.Join(wrapErr, errBeginTx, err)
+	}
+	defer tx.Rollback()
+
+	var tourId int
+	if err := tx.Get(&tourId, getTourByInviteLink, tokenHash, time.Now()); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, errors.Join(wrapErr, errBadLink)
+		}
+		return 0, errors.Join(wrapErr, err)
+	}
+
+	if err := addUserToTourTx(tx, tourId, userId); err != nil {
+		return 0, errors.Join(wrapErr, err)
+	}
+	if _, err := tx.Exec(useTourInviteLink, tokenHash); err != nil {
+		return 0, errors.Join(wrapErr, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, errors.Join(wrapErr, errCommitTx, err)
+	}
+
+	return tourId, nil
+}
+
+// AddTourJoinRequest implements DbHandler.
+func (d *dbProcessor) AddTourJoinRequest(tourId, userId int) error {
+	wrapErr := errors.New("error while inserting the tournament join request to the database")
+
+	var cnt int
+	if err := d.db.Get(&cnt, checkTourBan, tourId, userId); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+	if cnt > 0 {
+		return errors.Join(wrapErr, errBanned)
+	}
+
+	if _, err := d.db.Exec(addTourJoinRequest, tourId, userId); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+
+	return nil
+}
+
+// CheckTourJoinRequest implements DbHandler.
+func (d *dbProcessor) CheckTourJoinRequest(tourId, userId int) (bool, error) {
+	var cnt int
+
+	if err := d.db.Get(&cnt, checkTourJoinRequest, tourId, userId); err != nil {
+		return false, errors.Join(errors.New("error while checking tournament join request in the database"), err)
+	}
+
+	return cnt > 0, nil
+}
+
+// GetTourJoinRequests implements DbHandler.
+func (d *dbProcessor) GetTourJoinRequests(tourId int) ([]models.User, error) {
+	var users []models.User
+
+	if err := d.db.Select(&users, getTourJoinRequests, tourId); err != nil {
+		return []models.User{}, errors.Join(errors.New("error while getting tournament join requests from the database"), err)
+	}
+
+	return users, nil
+}
+
+// ApproveTourJoinRequest implements DbHandler.
+func (d *dbProcessor) ApproveTourJoinRequest(tu models.TURelation, creatorId int) error {
+	wrapErr := errors.New("error while approving the tournament join request in the database")
+
+	ok, err := d.CheckTournamentCreator(tu.TournamentId, creatorId)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.Join(wrapErr, errNotCreator)
+	}
+
+	tx, err := d.db.Beginx()
+	if err != nil {
+		return errors.Join(wrapErr, errBeginTx, err)
+	}
+	defer tx.Rollback()
+
+	if err := addUserToTourTx(tx, tu.TournamentId, tu.UserId); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Join(wrapErr, errCommitTx, err)
+	}
+
+	return nil
+}
+
+// RejectTourJoinRequest implements DbHandler.
+func (d *dbProcessor) RejectTourJoinRequest(tu models.TURelation, creatorId int) error {
+	wrapErr := errors.New("error while rejecting the tournament join request in the database")
+
+	ok, err := d.CheckTournamentCreator(tu.TournamentId, creatorId)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.Join(wrapErr, errNotCreator)
+	}
+
+	if _, err := d.db.Exec(removeTourJoinRequest, tu.TournamentId, tu.UserId); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+
+	return nil
+}
+
+// KickUserFromTour implements DbHandler.
+func (d *dbProcessor) KickUserFromTour(tu models.TURelation, creatorId int, ban bool) error {
+	wrapErr := errors.New("error while removing the participant from the tournament in the database")
+
+	ok, err := d.CheckTournamentCreator(tu.TournamentId, creatorId)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.Join(wrapErr, errNotCreator)
+	}
+
+	team, err := d.GetUserTeam(tu.TournamentId, tu.UserId)
+	if err == nil {
+		if err := d.LeaveTeam(team.Id, tu.UserId); err != nil {
+			return errors.Join(wrapErr, err)
+		}
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return errors.Join(wrapErr, err)
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return errors.Join(wrapErr, errBeginTx, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(removeUserFromTour, tu.TournamentId, tu.UserId); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+	if ban {
+		if _, err := tx.Exec(addTourBan, tu.TournamentId, tu.UserId); err != nil {
+			return errors.Join(wrapErr, err)
+		}
+		if _, err := tx.Exec(removeTourInvite, tu.TournamentId, tu.UserId); err != nil {
+			return errors.Join(wrapErr, err)
+		}
+		if _, err := tx.Exec(removeTourJoinRequest, tu.TournamentId, tu.UserId); err != nil {
+			return errors.Join(wrapErr, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Join(wrapErr, errCommitTx, err)
+	}
+
+	return nil
+}
+
+// GetTourBans implements DbHandler.
+func (d *dbProcessor) GetTourBans(tourId int) ([]models.User, error) {
+	var users []models.User
+
+	if err := d.db.Select(&users, getTourBans, tourId); err != nil {
+		return []models.User{}, errors.Join(errors.New("error while getting tournament bans from the database"), err)
+	}
+
+	return users, nil
+}
+
+// UnbanUserInTour implements DbHandler.
+func (d *dbProcessor) UnbanUserInTour(tu models.TURelation, creatorId int) error {
+	wrapErr := errors.New("error while unbanning the user in the tournament in the database")
+
+	ok, err := d.CheckTournamentCreator(tu.TournamentId, creatorId)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.Join(wrapErr, errNotCreator)
+	}
+
+	if _, err := d.db.Exec(removeTourBan, tu.TournamentId, tu.UserId); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+
+	return nil
+}
+
+// AddUserToken implements DbHandler.
+func (d *dbProcessor) AddUserToken(token models.UserToken) error {
+	wrapErr := errors.New("error while inserting user token to the database")
+
+	tx, err := d.db.Beginx()
+	if err != nil {
+		return errors.Join(wrapErr, errBeginTx, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(deleteUserTokens, token.UserId, token.Kind); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+	if _, err := tx.NamedExec(addUserToken, token); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Join(wrapErr, errCommitTx, err)
+	}
+
+	return nil
+}
+
+// takeUserToken - функция, забирающая действующий одноразовый токен в рамках транзакции.
+func takeUserToken(tx *sqlx.Tx, tokenHash, kind string) (models.UserToken, error) {
+	var token models.UserToken
+	if err := tx.Get(&token, getUserToken, tokenHash, kind, time.Now()); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.UserToken{}, errBadToken
+		}
+		return models.UserToken{}, err
+	}
+
+	if _, err := tx.Exec(deleteUserToken, tokenHash); err != nil {
+		return models.UserToken{}, err
+	}
+
+	return token, nil
+}
+
+// VerifyUserEmail implements DbHandler.
+func (d *dbProcessor) VerifyUserEmail(tokenHash string) error {
+	wrapErr := errors.New("error while verifying user's email in the database")
+
+	tx, err := d.db.Beginx()
+	if err != nil {
+		return errors.Join(wrapErr, errBeginTx, err)
+	}
+	defer tx.Rollback()
+
+	token, err := takeUserToken(tx, tokenHash, models.TokenVerify)
+	if err != nil {
+		return errors.Join(wrapErr, err)
+	}
+	if _, err := tx.Exec(verifyUserEmail, token.UserId, token.Email); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Join(wrapErr, errCommitTx, err)
+	}
+
+	return nil
+}
+
+// ResetUserPassword implements DbHandler.
+func (d *dbProcessor) ResetUserPassword(tokenHash, password string) error {
+	wrapErr := errors.New("error while resetting user's password in the database")
+
+	tx, err := d.db.Beginx()
+	if err != nil {
+		return errors.Join(wrapErr, errBeginTx, err)
+	}
+	defer tx.Rollback()
+
+	token, err := takeUserToken(tx, tokenHash, models.TokenReset)
+	if err != nil {
+		return errors.Join(wrapErr, err)
+	}
+	if _, err := tx.Exec(updateUserPassword, token.UserId, password); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+	if _, err := tx.Exec(deleteUserTokens, token.UserId, models.TokenReset); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Join(wrapErr, errCommitTx, err)
+	}
+
+	return nil
+}
+
+// ConfirmUserEmail implements DbHandler.
+func (d *dbProcessor) ConfirmUserEmail(tokenHash string) (models.UserToken, error) {
+	wrapErr := errors.New("error while changing user's email in the database")
+
+	tx, err := d.db.Beginx()
+	if err != nil {
+		return models.UserToken{}, errors.Join(wrapErr, errBeginTx, err)
+	}
+	defer tx.Rollback()
+
+	token, err := takeUserToken(tx, tokenHash, models.TokenEmail)
+	if err != nil {
+		return models.UserToken{}, errors.Join(wrapErr, err)
+	}
+	if _, err := tx.Exec(updateUserEmail, token.UserId, token.Email); err != nil {
+		return models.UserToken{}, errors.Join(wrapErr, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.UserToken{}, errors.Join(wrapErr, errCommitTx, err)
+	}
+
+	return token, nil
+}
+
+// DeleteExpiredUserTokens implements DbHandler.
+func (d *dbProcessor) DeleteExpiredUserTokens() error {
+	if _, err := d.db.Exec(deleteExpiredUserTokens, time.Now()); err != nil {
+		return errors.Join(errors.New("error while deleting expired user tokens from the database"), err)
+	}
+
+	return nil
+}
+
+// ChangeUserPassword implements DbHandler.
+func (d *dbProcessor) ChangeUserPassword(userId int, password string) error {
+	if _, err := d.db.Exec(updateUserPassword, userId, password); err != nil {
+		return errors.Join(errors.New("error while changing user's password in the database"), err)
+	}
+
+	return nil
+}
+
+// DeleteUser implements DbHandler.
+func (d *dbProcessor) DeleteUser(userId int) error {
+	wrapErr := errors.New("error while deleting the user from the database")
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return errors.Join(wrapErr, errBeginTx, err)
+	}
+	defer tx.Rollback()
+
+	for _, q := range []string{
+		deleteUserFromTokens,
+		deleteUserFromIdentities,
+		deleteUserFromApiTokens,
+		deleteUserFromTourInvites,
+		deleteUserFromJoinRequests,
+		deleteUserFromTeamInvites,
+		deleteUserFromFollows,
+		deleteUserFromTemplates,
+		deleteUserCollectionRoutes,
+		deleteUserFromCollections,
+		deleteUserFromRouteVotes,
+		hideUserRouteComments,
+		anonymizeUserTourResults,
+		anonymizeUserTourWinners,
+		anonymizeUserSeasonResults,
+		anonymizeUser,
+	} {
+		if _, err := tx.Exec(q, userId); err != nil {
+			return errors.Join(wrapErr, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Join(wrapErr, errCommitTx, err)
+	}
+
+	return nil
+}
+
+// GetUserByIdentity implements DbHandler.
+func (d *dbProcessor) GetUserByIdentity(provider, subject string) (models.User, error) {
+	var user models.User
+
+	if err := d.db.Get(&user, getUserByIdentity, provider, subject); err != nil {
+		return models.User{}, errors.Join(errors.New("error while getting user by identity from the database"), err)
+	}
+
+	return user, nil
+}
+
+// AddUserIdentity implements DbHandler.
+func (d *dbProcessor) AddUserIdentity(provider, subject string, userId int) error {
+	wrapErr := errors.New("error while linking user identity in the database")
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return errors.Join(wrapErr, errBeginTx, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(addUserIdentity, provider, subject, userId); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+	if _, err := tx.Exec(markUserVerified, userId); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Join(wrapErr, errCommitTx, err)
+	}
+
+	return nil
+}
+
+// AddUserWithIdentity implements DbHandler.
+func (d *dbProcessor) AddUserWithIdentity(user models.User, provider, subject string) (int, error) {
+	wrapErr := errors.New("error while inserting user with identity to the database")
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return 0, errors.Join(wrapErr, errBeginTx, err)
+	}
+	defer tx.Rollback()
+
+	var id int
+	if err := tx.QueryRow(addVerifiedUser, user.Name, user.Email, user.Password).Scan(&id); err != nil {
+		return 0, errors.Join(wrapErr, err)
+	}
+	if _, err := tx.Exec(addUserIdentity, provider, subject, id); err != nil {
+		return 0, errors.Join(wrapErr, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, errors.Join(wrapErr, errCommitTx, err)
+	}
+
+	return id, nil
+}
+
+// AddApiToken implements DbHandler.
+func (d *dbProcessor) AddApiToken(token models.ApiToken) (int, error) {
+	var id int
+
+	if err := d.db.QueryRow(addApiToken, token.UserId, token.Name, token.TokenHash, token.Scopes, token.CreatedAt).Scan(&id); err != nil {
+		return 0, errors.Join(errors.New("error while inserting api token to the database"), err)
+	}
+
+	return id, nil
+}
+
+// GetUserApiTokens implements DbHandler.
+func (d *dbProcessor) GetUserApiTokens(userId int) ([]models.ApiToken, error) {
+	var tokens []models.ApiToken
+
+	if err := d.db.Select(&tokens, getUserApiTokens, userId); err != nil {
+		return []models.ApiToken{}, errors.Join(errors.New("error while getting user's api tokens from the database"), err)
+	}
+
+	return tokens, nil
+}
+
+// UseApiToken implements DbHandler.
+func (d *dbProcessor) UseApiToken(tokenHash string) (models.ApiToken, error) {
+	var token models.ApiToken
+
+	if err := d.db.Get(&token, useApiToken, tokenHash, time.Now()); err != nil {
+		return models.ApiToken{}, errors.Join(errors.New("error while using api token in the database"), err)
+	}
+
+	return token, nil
+}
+
+// DeleteApiToken implements DbHandler.
+func (d *dbProcessor) DeleteApiToken(id, userId int) error {
+	if _, err := d.db.Exec(deleteApiToken, id, userId); err != nil {
+		return errors.Join(errors.New("error while deleting api token from the database"), err)
+	}
+
+	return nil
+}
+
+// GetUserStats implements DbHandler.
+func (d *dbProcessor) GetUserStats(userId int) (models.UserStats, error) {
+	var stats models.UserStats
+
+	if err := d.db.Get(&stats, getUserStats, userId); err != nil {
+		return models.UserStats{}, errors.Join(errors.New("error while getting user's stats from the database"), err)
+	}
+
+	return stats, nil
+}
+
+// GetUserBests implements DbHandler.
+func (d *dbProcessor) GetUserBests(userId int) ([]models.PersonalBest, error) {
+	var bests []models.PersonalBest
+
+	if err := d.db.Select(&bests, getUserBests, userId); err != nil {
+		return []models.PersonalBest{}, errors.Join(errors.New("error while getting user's personal bests from the database"), err)
+	}
+
+	return bests, nil
+}
+
+// GetUserActivity implements DbHandler.
+func (d *dbProcessor) GetUserActivity(userId int) ([]models.Activity, error) {
+	var activity []models.Activity
+
+	if err := d.db.Select(&activity, getUserActivity, userId); err != nil {
+		return []models.Activity{}, errors.Join(errors.New("error while getting user's activity from the database"), err)
+	}
+
+	return activity, nil
+}
+
+// SetUserHidden implements DbHandler.
+func (d *dbProcessor) SetUserHidden(userId int, hidden bool) error {
+	if _, err := d.db.Exec(updateUserHidden, userId, hidden); err != nil {
+		return errors.Join(errors.New("error while updating user's profile privacy in the database"), err)
+	}
+
+	return nil
+}
+
+// GetAchievementFacts implements DbHandler.
+func (d *dbProcessor) GetAchievementFacts(userId int) (models.AchievementFacts, error) {
+	var facts models.AchievementFacts
+
+	if err := d.db.Get(&facts, getAchievementFacts, userId); err != nil {
+		return models.AchievementFacts{}, errors.Join(errors.New("error while getting user's achievement facts from the database"), err)
+	}
+
+	return facts, nil
+}
+
+// AddUserAchievements implements DbHandler.
+func (d *dbProcessor) AddUserAchievements(userId int, ids []string, at time.Time) ([]string, error) {
+	wrapErr := errors.New("error while adding user's achievements to the database")
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return nil, errors.Join(wrapErr, errBeginTx, err)
+	}
+	defer tx.Rollback()
+
+	var added []string
+	for _, id := range ids {
+		res, err := tx.Exec(addUserAchievement, userId, id, at)
+		if err != nil {
+			return nil, errors.Join(wrapErr, err)
+		}
+		if n, err := res.RowsAffected(); err == nil && n > 0 {
+			added = append(added, id)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Join(wrapErr, errCommitTx, err)
+	}
+
+	return added, nil
+}
+
+// GetUserAchievements implements DbHandler.
+func (d *dbProcessor) GetUserAchievements(userId int) ([]models.UserAchievement, error) {
+	var res []models.UserAchievement
+
+	if err := d.db.Select(&res, getUserAchievements, userId); err != nil {
+		return []models.UserAchievement{}, errors.Join(errors.New("error while getting user's achievements from the database"), err)
+	}
+
+	return res, nil
+}
+
+// GetUsersAchievements implements DbHandler.
+func (d *dbProcessor) GetUsersAchievements(userIds []int) ([]models.UserAchievement, error) {
+	var res []models.UserAchievement
+
+	if err := d.db.Select(&res, getUsersAchievements, pq.Array(userIds)); err != nil {
+		return []models.UserAchievement{}, errors.Join(errors.New("error while getting users' achievements from the database"), err)
+	}
+
+	return res, nil
+}
+
+// GetUsersIds implements DbHandler.
+func (d *dbProcessor) GetUsersIds() ([]int, error) {
+	var ids []int
+
+	if err := d.db.Select(&ids, getUsersIds); err != nil {
+		return []int{}, errors.Join(errors.New("error while getting users' ids from the database"), err)
+	}
+
+	return ids, nil
+}
+
+// GetSkillLadder implements DbHandler.
+func (d *dbProcessor) GetSkillLadder() ([]models.PlayerSkill, error) {
+	var res []models.PlayerSkill
+
+	if err := d.db.Select(&res, getSkillLadder); err != nil {
+		return []models.PlayerSkill{}, errors.Join(errors.New("error while getting the skill ladder from the database"), err)
+	}
+
+	return res, nil
+}
+
+// GetPlayerSkills implements DbHandler.
+func (d *dbProcessor) GetPlayerSkills(userIds []int) ([]models.PlayerSkill, error) {
+	var res []models.PlayerSkill
+
+	if err := d.db.Select(&res, getPlayerSkills, pq.Array(userIds)); err != nil {
+		return []models.PlayerSkill{}, errors.Join(errors.New("error while getting players' skill from the database"), err)
+	}
+
+	return res, nil
+}
+
+// SavePlayerSkills implements DbHandler.
+func (d *dbProcessor) SavePlayerSkills(skills []models.PlayerSkill) error {
+	wrapErr := errors.New("error while saving players' skill to the database")
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return errors.Join(wrapErr, errBeginTx, err)
+	}
+	defer tx.Rollback()
+
+	if err := savePlayerSkillsTx(tx, skills); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Join(wrapErr, errCommitTx, err)
+	}
+
+	return nil
+}
+
+// ReplacePlayerSkills implements DbHandler.
+func (d *dbProcessor) ReplacePlayerSkills(skills []models.PlayerSkill) error {
+	wrapErr := errors.New("error while replacing players' skill in the database")
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return errors.Join(wrapErr, errBeginTx, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(deletePlayerSkills); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+	if err := savePlayerSkillsTx(tx, skills); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Join(wrapErr, errCommitTx, err)
+	}
+
+	return nil
+}
+
+// savePlayerSkillsTx - функция, сохраняющая рейтинги мастерства игроков в транзакции.
+func savePlayerSkillsTx(tx *sql.Tx, skills []models.PlayerSkill) error {
+	for _, s := range skills {
+		if _, err := tx.Exec(upsertPlayerSkill, s.UserId, s.Rating, s.Games, s.LastActive); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetSuccessfulSprints implements DbHandler.
+func (d *dbProcessor) GetSuccessfulSprints() ([]models.Sprint, error) {
+	var res []models.Sprint
+
+	if err := d.db.Select(&res, getSuccessfulSprints); err != nil {
+		return []models.Sprint{}, errors.Join(errors.New("error while getting successful sprints from the database"), err)
+	}
+
+	return res, nil
+}
+
+// AddSeason implements DbHandler.
+func (d *dbProcessor) AddSeason(season models.Season) (int, error) {
+	var id int
+
+	if err := d.db.QueryRow(addSeason, season.Name, season.StartTime, season.EndTime).Scan(&id); err != nil {
+		return 0, errors.Join(errors.New("error while inserting season to the database"), err)
+	}
+
+	return id, nil
+}
+
+// GetSeason implements DbHandler.
+func (d *dbProcessor) GetSeason(id int) (models.Season, error) {
+	var season models.Season
+
+	if err := d.db.Get(&season, getSeason, id); err != nil {
+		return models.Season{}, errors.Join(errors.New("error while getting season from the database"), err)
+	}
+
+	return season, nil
+}
+
+// GetSeasons implements DbHandler.
+func (d *dbProcessor) GetSeasons() ([]models.Season, error) {
+	var seasons []models.Season
+
+	if err := d.db.Select(&seasons, getSeasons); err != nil {
+		return []models.Season{}, errors.Join(errors.New("error while getting seasons from the database"), err)
+	}
+
+	return seasons, nil
+}
+
+// GetSeasonRatings implements DbHandler.
+func (d *dbProcessor) GetSeasonRatings(seasonId int) ([]models.TourRating, error) {
+	wrapErr := errors.New("error while getting season ratings from the database")
+
+	season, err := d.GetSeason(seasonId)
+	if err != nil {
+		return []models.TourRating{}, errors.Join(wrapErr, err)
+	}
+
+	if !season.Archived {
+		return d.GetRatingsBetween(season.StartTime, season.EndTime)
+	}
+
+	var results []models.SeasonResult
+	if err := d.db.Select(&results, getSeasonResults, seasonId); err != nil {
+		return []models.TourRating{}, errors.Join(wrapErr, err)
+	}
+
+	ratings := make([]models.TourRating, len(results))
+	for i, r := range results {
+		ratings[i] = models.TourRating{
+			UserId:   r.UserId,
+			UserName: r.UserName,
+			Points:   r.Points,
+		}
+	}
+
+	return ratings, nil
+}
+
+// GetSeasonsToArchive implements DbHandler.
+func (d *dbProcessor) GetSeasonsToArchive() ([]models.Season, error) {
+	var seasons []models.Season
+
+	if err := d.db.Select(&seasons, getSeasonsToArchive, time.Now()); err != nil {
+		return []models.Season{}, errors.Join(errors.New("error while getting seasons to archive from the database"), err)
+	}
+
+	return seasons, nil
+}
+
+// ArchiveSeason implements DbHandler.
+func (d *dbProcessor) ArchiveSeason(seasonId int) error {
+	wrapErr := errors.New("error while archiving the season in the database")
+
+	season, err := d.GetSeason(seasonId)
+	if err != nil {
+		return errors.Join(wrapErr, err)
+	}
+
+	ratings, err := d.GetRatingsBetween(season.StartTime, season.EndTime)
+	if err != nil {
+		return errors.Join(wrapErr, err)
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return errors.Join(wrapErr, errBeginTx, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(deleteSeasonResults, seasonId); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+
+	place := 0
+	for i, r := range ratings {
+		if i == 0 || r.Points != ratings[i-1].Points {
+			place = i + 1
+		}
+		if _, err := tx.Exec(addSeasonResult, seasonId, r.UserId, r.UserName, place, r.Points); err != nil {
+			return errors.Join(wrapErr, err)
+		}
+	}
+
+	if _, err := tx.Exec(archiveSeason, seasonId); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Join(wrapErr, errCommitTx, err)
+	}
+
+	return nil
+}
+
+// AddFollow implements DbHandler.
+func (d *dbProcessor) AddFollow(followerId, followeeId int) error {
+	if _, err := d.db.Exec(addFollow, followerId, followeeId, time.Now()); err != nil {
+		return errors.Join(errors.New("error while inserting follow to the database"), err)
+	}
+
+	return nil
+}
+
+// DeleteFollow implements DbHandler.
+func (d *dbProcessor) DeleteFollow(followerId, followeeId int) error {
+	if _, err := d.db.Exec(deleteFollow, followerId, followeeId); err != nil {
+		return errors.Join(errors.New("error while deleting follow from the database"), err)
+	}
+
+	return nil
+}
+
+// IsFollowing implements DbHandler.
+func (d *dbProcessor) IsFollowing(followerId, followeeId int) (bool, error) {
+	var following bool
+
+	if err := d.db.Get(&following, isFollowing, followerId, followeeId); err != nil {
+		return false, errors.Join(errors.New("error while checking follow in the database"), err)
+	}
+
+	return following, nil
+}
+
+// GetFollowing implements DbHandler.
+func (d *dbProcessor) GetFollowing(userId, viewerId int) ([]models.Follow, error) {
+	var follows []models.Follow
+
+	if err := d.db.Select(&follows, getFollowing, userId, viewerId); err != nil {
+		return []models.Follow{}, errors.Join(errors.New("error while getting follows from the database"), err)
+	}
+
+	return follows, nil
+}
+
+// GetFollowingIds implements DbHandler.
+func (d *dbProcessor) GetFollowingIds(userId int) ([]int, error) {
+	var ids []int
+
+	if err := d.db.Select(&ids, getFollowingIds, userId); err != nil {
+		return []int{}, errors.Join(errors.New("error while getting follows from the database"), err)
+	}
+
+	return ids, nil
+}
+
+// GetFollowFeed implements DbHandler.
+func (d *dbProcessor) GetFollowFeed(userId, limit int) ([]models.FeedSprint, error) {
+	var feed []models.FeedSprint
+
+	if err := d.db.Select(&feed, getFollowFeed, userId, limit); err != nil {
+		return []models.FeedSprint{}, errors.Join(errors.New("error while getting follow feed from the database"), err)
+	}
+
+	return feed, nil
+}
+
+// GetRouteRecordSprint implements DbHandler.
+func (d *dbProcessor) GetRouteRecordSprint(routeId int) (models.Sprint, error) {
+	var sprint models.Sprint
+
+	if err := d.db.Get(&sprint, getRouteRecordSprint, routeId); err != nil {
+		return models.Sprint{}, errors.Join(errors.New("error while getting route record from the database"), err)
+	}
+
+	return sprint, nil
+}
+
+// GetUserBestSprint implements DbHandler.
+func (d *dbProcessor) GetUserBestSprint(userId, routeId int) (models.Sprint, error) {
+	var sprint models.Sprint
+
+	if err := d.db.Get(&sprint, getUserBestSprint, userId, routeId); err != nil {
+		return models.Sprint{}, errors.Join(errors.New("error while getting user best sprint from the database"), err)
+	}
+
+	return sprint, nil
+}
+
+// AddGhostResult implements DbHandler.
+func (d *dbProcessor) AddGhostResult(result models.GhostResult) error {
+	if _, err := d.db.Exec(addGhostResult, result.SprintId, result.GhostSprintId,
+		result.Beaten, result.Margin, result.CreatedAt); err != nil {
+		return errors.Join(errors.New("error while inserting ghost result to the database"), err)
+	}
+
+	return nil
+}
+
+// GetGhostResult implements DbHandler.
+func (d *dbProcessor) GetGhostResult(sprintId int) (models.GhostResult, error) {
+	var result models.GhostResult
+
+	if err := d.db.Get(&result, getGhostResult, sprintId); err != nil {
+		return models.GhostResult{}, errors.Join(errors.New("error while getting ghost result from the database"), err)
+	}
+
+	return result, nil
+}
+
+// GetSprintPaths implements DbHandler.
+func (d *dbProcessor) GetSprintPaths() ([]models.PathSprint, error) {
+	var sprints []models.PathSprint
+
+	if err := d.db.Select(&sprints, getSprintPaths); err != nil {
+		return []models.PathSprint{}, errors.Join(errors.New("error while getting sprint paths from the database"), err)
+	}
+
+	return sprints, nil
+}
+
+// GetPathInsights implements DbHandler.
+func (d *dbProcessor) GetPathInsights(routeId int) ([]models.PathInsight, error) {
+	var insights []models.PathInsight
+
+	if err := d.db.Select(&insights, getPathInsights, routeId); err != nil {
+		return []models.PathInsight{}, errors.Join(errors.New("error while getting path insights from the database"), err)
+	}
+
+	return insights, nil
+}
+
+// ReplacePathInsights implements DbHandler.
+func (d *dbProcessor) ReplacePathInsights(insights []models.PathInsight) error {
+	wrapErr := errors.New("error while replacing path insights in the database")
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return errors.Join(wrapErr, errBeginTx, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(deletePathInsights); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+
+	stmt, err := tx.Prepare(addPathInsight)
+	if err != nil {
+		return errors.Join(wrapErr, err)
+	}
+	defer stmt.Close()
+
+	for _, i := range insights {
+		if _, err := stmt.Exec(i.RouteId, i.Kind, i.Article, i.Hits, i.Successes, i.Place, i.ComputedAt); err != nil {
+			return errors.Join(wrapErr, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Join(wrapErr, errCommitTx, err)
+	}
+
+	return nil
+}
+
+// EachUserSprint implements DbHandler.
+func (d *dbProcessor) EachUserSprint(userId int, fn func(models.ExportSprint) error) error {
+	if err := eachExportRow(d.db, fn, exportUserSprints, userId); err != nil {
+		return errors.Join(errors.New("error while exporting user sprints from the database"), err)
+	}
+
+	return nil
+}
+
+// EachTourSprint implements DbHandler.
+func (d *dbProcessor) EachTourSprint(tourId int, fn func(models.ExportSprint) error) error {
+	if err := eachExportRow(d.db, fn, exportTourSprints, tourId); err != nil {
+		return errors.Join(errors.New("error while exporting tournament sprints from the database"), err)
+	}
+
+	return nil
+}
+
+// EachRouteRating implements DbHandler.
+func (d *dbProcessor) EachRouteRating(routeId int, start, end *time.Time, fn func(models.ExportRouteRating) error) error {
+	if err := eachExportRow(d.db, fn, exportRouteRating, routeId, start, end); err != nil {
+		return errors.Join(errors.New("error while exporting route rating from the database"), err)
+	}
+
+	return nil
+}
+
+// eachExportRow - функция, построчно читающая строки выгрузки, не загружая их все в память.
+func eachExportRow[T any](db *sqlx.DB, fn func(T) error, query string, args ...any) error {
+	rows, err := db.Queryx(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row T
+		if err := rows.StructScan(&row); err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// AddTourTemplate implements DbHandler.
+func (d *dbProcessor) AddTourTemplate(tmpl models.TourTemplate) (int, error) {
+	var id int
+
+	if err := d.db.QueryRow(addTourTemplate, tmpl.Name, tmpl.OwnerId, tmpl.Spec,
+		tmpl.Recurrence, tmpl.NextStart, tmpl.CreatedAt).Scan(&id); err != nil {
+		return 0, errors.Join(errors.New("error while inserting tournament template to the database"), err)
+	}
+
+	return id, nil
+}
+
+// GetTourTemplate implements DbHandler.
+func (d *dbProcessor) GetTourTemplate(id int) (models.TourTemplate, error) {
+	var tmpl models.TourTemplate
+
+	if err := d.db.Get(&tmpl, getTourTemplate, id); err != nil {
+		return models.TourTemplate{}, errors.Join(errors.New("error while getting tournament template from the database"), err)
+	}
+
+	return tmpl, nil
+}
+
+// GetUserTourTemplates implements DbHandler.
+func (d *dbProcessor) GetUserTourTemplates(userId int) ([]models.TourTemplate, error) {
+	var templates []models.TourTemplate
+
+	if err := d.db.Select(&templates, getUserTourTemplates, userId); err != nil {
+		return []models.TourTemplate{}, errors.Join(errors.New("error while getting tournament templates from the database"), err)
+	}
+
+	return templates, nil
+}
+
+// GetDueTourTemplates implements DbHandler.
+func (d *dbProcessor) GetDueTourTemplates(before time.Time) ([]models.TourTemplate, error) {
+	var templates []models.TourTemplate
+
+	if err := d.db.Select(&templates, getDueTourTemplates, before); err != nil {
+		return []models.TourTemplate{}, errors.Join(errors.New("error while getting due tournament templates from the database"), err)
+	}
+
+	return templates, nil
+}
+
+// SetTemplateNextStart implements DbHandler.
+func (d *dbProcessor) SetTemplateNextStart(id int, next time.Time) error {
+	if _, err := d.db.Exec(updateTemplateNextStart, id, next); err != nil {
+		return errors.Join(errors.New("error while updating tournament template in the database"), err)
+	}
+
+	return nil
+}
+
+// DisableTemplateRecurrence implements DbHandler.
+func (d *dbProcessor) DisableTemplateRecurrence(id int) error {
+	if _, err := d.db.Exec(disableTemplateRecurrence, id); err != nil {
+		return errors.Join(errors.New("error while disabling tournament template recurrence in the database"), err)
+	}
+
+	return nil
+}
+
+// DeleteTourTemplate implements DbHandler.
+func (d *dbProcessor) DeleteTourTemplate(id, userId int) error {
+	if _, err := d.db.Exec(deleteTourTemplate, id, userId); err != nil {
+		return errors.Join(errors.New("error while deleting tournament template from the database"), err)
+	}
+
+	return nil
+}
+
+// AddCollection implements DbHandler.
+func (d *dbProcessor) AddCollection(collection models.Collection) (int, error) {
+	var id int
+
+	if err := d.db.QueryRow(addCollection, collection.Name, collection.Description,
+		collection.OwnerId, collection.Private, collection.CreatedAt).Scan(&id); err != nil {
+		return 0, errors.Join(errors.New("error while inserting collection to the database"), err)
+	}
+
+	return id, nil
+}
+
+// GetCollection implements DbHandler.
+func (d *dbProcessor) GetCollection(id int) (models.Collection, error) {
+	var collection models.Collection
+
+	if err := d.db.Get(&collection, getCollection, id); err != nil {
+		return models.Collection{}, errors.Join(errors.New("error while getting collection from the database"), err)
+	}
+
+	return collection, nil
+}
+
+// GetUserCollections implements DbHandler.
+func (d *dbProcessor) GetUserCollections(userId int) ([]models.Collection, error) {
+	var collections []models.Collection
+
+	if err := d.db.Select(&collections, getUserCollections, userId); err != nil {
+		return []models.Collection{}, errors.Join(errors.New("error while getting user collections from the database"), err)
+	}
+
+	return collections, nil
+}
+
+// GetPublicCollections implements DbHandler.
+func (d *dbProcessor) GetPublicCollections() ([]models.Collection, error) {
+	var collections []models.Collection
+
+	if err := d.db.Select(&collections, getPublicCollections); err != nil {
+		return []models.Collection{}, errors.Join(errors.New("error while getting public collections from the database"), err)
+	}
+
+	return collections, nil
+}
+
+// GetCollectionRoutes implements DbHandler.
+func (d *dbProcessor) GetCollectionRoutes(collectionId, userId int) ([]models.CollectionRoute, error) {
+	var routes []models.CollectionRoute
+
+	if err := d.db.Select(&routes, getCollectionRoutes, collectionId, userId); err != nil {
+		return []models.CollectionRoute{}, errors.Join(errors.New("error while getting collection routes from the database"), err)
+	}
+
+	return routes, nil
+}
+
+// AddRouteToCollection implements DbHandler.
+func (d *dbProcessor) AddRouteToCollection(collectionId, routeId int) error {
+	if _, err := d.db.Exec(addRouteToCollection, collectionId, routeId); err != nil {
+		return errors.Join(errors.New("error while adding route to the collection in the database"), err)
+	}
+
+	return nil
+}
+
+// RemoveRouteFromCollection implements DbHandler.
+func (d *dbProcessor) RemoveRouteFromCollection(collectionId, routeId int) error {
+	if _, err := d.db.Exec(deleteRouteFromCollection, collectionId, routeId); err != nil {
+		return errors.Join(errors.New("error while removing route from the collection in the database"), err)
+	}
+
+	return nil
+}
+
+// MoveCollectionRoute implements DbHandler.
+func (d *dbProcessor) MoveCollectionRoute(collectionId, routeId int, up bool) error {
+	wrapErr := errors.New("error while moving route in the collection in the database")
+
+	tx, err := d.db.Beginx()
+	if err != nil {
+		return errors.Join(wrapErr, errBeginTx, err)
+	}
+	defer tx.Rollback()
+
+	var position int
+	if err := tx.Get(&position, getCollectionRoutePosition, collectionId, routeId); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+
+	q := getNextCollectionRoute
+	if up {
+		q = getPrevCollectionRoute
+	}
+	neighbour := struct {
+		RouteId  int `db:"route_id"`
+		Position int `db:"position"`
+	}{}
+	if err := tx.Get(&neighbour, q, collectionId, position); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return errors.Join(wrapErr, err)
+	}
+
+	if _, err := tx.Exec(updateCollectionRoutePosition, collectionId, routeId, neighbour.Position); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+	if _, err := tx.Exec(updateCollectionRoutePosition, collectionId, neighbour.RouteId, position); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Join(wrapErr, errCommitTx, err)
+	}
+
+	return nil
+}
+
+// ToggleCollectionPrivacy implements DbHandler.
+func (d *dbProcessor) ToggleCollectionPrivacy(id, userId int) error {
+	if _, err := d.db.Exec(toggleCollectionPrivacy, id, userId); err != nil {
+		return errors.Join(errors.New("error while toggling collection privacy in the database"), err)
+	}
+
+	return nil
+}
+
+// DeleteCollection implements DbHandler.
+func (d *dbProcessor) DeleteCollection(id, userId int) error {
+	wrapErr := errors.New("error while deleting collection from the database")
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return errors.Join(wrapErr, errBeginTx, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(deleteCollectionRoutes, id); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+	res, err := tx.Exec(deleteCollection, id, userId)
+	if err != nil {
+		return errors.Join(wrapErr, err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return errors.Join(wrapErr, errors.New("only the collection owner can delete it"), err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Join(wrapErr, errCommitTx, err)
+	}
+
+	return nil
+}
+
+// AddCollectionToTour implements DbHandler.
+func (d *dbProcessor) AddCollectionToTour(tourId, collectionId, userId int) (int, error) {
+	wrapErr := errors.New("error while adding collection routes to the tournament in the database")
+
+	ok, err := d.CheckTournamentCreator(tourId, userId)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, errors.Join(wrapErr, errNotCreator)
+	}
+
+	res, err := d.db.Exec(addCollectionToTour, tourId, collectionId)
+	if err != nil {
+		return 0, errors.Join(wrapErr, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, errors.Join(wrapErr, err)
+	}
+
+	return int(n), nil
+}
+
+// GetRouteComments implements DbHandler.
+func (d *dbProcessor) GetRouteComments(routeId int) ([]models.RouteComment, error) {
+	var comments []models.RouteComment
+
+	if err := d.db.Select(&comments, getRouteComments, routeId); err != nil {
+		return []models.RouteComment{}, errors.Join(errors.New("error while getting route comments from the database"), err)
+	}
+
+	return comments, nil
+}
+
+// GetRouteComment implements DbHandler.
+func (d *dbProcessor) GetRouteComment(id int) (models.RouteComment, error) {
+	var comment models.RouteComment
+
+	if err := d.db.Get(&comment, getRouteComment, id); err != nil {
+		return models.RouteComment{}, errors.Join(errors.New("error while getting route comment from the database"), err)
+	}
+
+	return comment, nil
+}
+
+// AddRouteComment implements DbHandler.
+func (d *dbProcessor) AddRouteComment(comment models.RouteComment) (int, error) {
+	var id int
+
+	if err := d.db.QueryRow(addRouteComment, comment.RouteId, comment.UserId, comment.ParentId,
+		comment.Body, comment.Spoiler, comment.CreatedAt).Scan(&id); err != nil {
+		return 0, errors.Join(errors.New("error while inserting route comment to the database"), err)
+	}
+
+	return id, nil
+}
+
+// HideRouteComment implements DbHandler.
+func (d *dbProcessor) HideRouteComment(id int) error {
+	if _, err := d.db.Exec(hideRouteComment, id); err != nil {
+		return errors.Join(errors.New("error while hiding route comment in the database"), err)
+	}
+
+	return nil
+}
+
+// GetRouteVotes implements DbHandler.
+func (d *dbProcessor) GetRouteVotes(routeId, userId int) (models.RouteVotes, error) {
+	var votes models.RouteVotes
+
+	if err := d.db.Get(&votes, getRouteVotes, routeId, userId); err != nil {
+		return models.RouteVotes{}, errors.Join(errors.New("error while getting route votes from the database"), err)
+	}
+
+	return votes, nil
+}
+
+// SetRouteVote implements DbHandler.
+func (d *dbProcessor) SetRouteVote(routeId, userId, stars int) error {
+	if _, err := d.db.Exec(setRouteVote, routeId, userId, stars); err != nil {
+		return errors.Join(errors.New("error while saving route vote to the database"), err)
+	}
+
+	return nil
+}
+
+// AddRouteReport implements DbHandler.
+func (d *dbProcessor) AddRouteReport(report models.RouteReport, hideAfter int) (bool, error) {
+	wrapErr := errors.New("error while adding route report to the database")
+
+	tx, err := d.db.Beginx()
+	if err != nil {
+		return false, errors.Join(wrapErr, errBeginTx, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(addRouteReport, report.RouteId, report.CommentId, report.UserId, report.Reason, report.CreatedAt); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return false, errors.Join(wrapErr, errReported)
+		}
+		return false, errors.Join(wrapErr, err)
+	}
+
+	hidden := false
+	if report.CommentId != nil {
+		var count int
+		if err := tx.Get(&count, countOpenCommentReports, *report.CommentId); err != nil {
+			return false, errors.Join(wrapErr, err)
+		}
+		if count >= hideAfter {
+			if _, err := tx.Exec(hideRouteComment, *report.CommentId); err != nil {
+				return false, errors.Join(wrapErr, err)
+			}
+			hidden = true
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, errors.Join(wrapErr, errCommitTx, err)
+	}
+
+	return hidden, nil
+}
+
+// GetOpenReports implements DbHandler.
+func (d *dbProcessor) GetOpenReports() ([]models.RouteReport, error) {
+	var reports []models.RouteReport
+
+	if err := d.db.Select(&reports, getOpenReports); err != nil {
+		return []models.RouteReport{}, errors.Join(errors.New("error while getting open reports from the database"), err)
+	}
+
+	return reports, nil
+}
+
+// ResolveRouteReport implements DbHandler.
+func (d *dbProcessor) ResolveRouteReport(id int, resolution string, hide bool) error {
+	wrapErr := errors.New("error while resolving route report in the database")
+
+	tx, err := d.db.Beginx()
+	if err != nil {
+		return errors.Join(wrapErr, errBeginTx, err)
+	}
+	defer tx.Rollback()
+
+	var report models.RouteReport
+	if err := tx.Get(&report, getRouteReport, id); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+	if report.ResolvedAt != nil {
+		return errors.Join(wrapErr, errors.New("the report is already resolved"))
+	}
+
+	now := time.Now()
+	if hide {
+		if report.CommentId == nil {
+			return errors.Join(wrapErr, errors.New("only comments can be hidden"))
+		}
+		if _, err := tx.Exec(hideRouteComment, *report.CommentId); err != nil {
+			return errors.Join(wrapErr, err)
+		}
+		if _, err := tx.Exec(resolveCommentReports, *report.CommentId, now, resolution); err != nil {
+			return errors.Join(wrapErr, err)
+		}
+	} else if _, err := tx.Exec(resolveRouteReport, id, now, resolution); err != nil {
+		return errors.Join(wrapErr, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Join(wrapErr, errCommitTx, err)
+	}
+
+	return nil
+}
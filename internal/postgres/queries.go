@@ -7,7 +7,10 @@ const (
     id SERIAL PRIMARY KEY,
     name TEXT NOT NULL,
     email TEXT NOT NULL UNIQUE,
-    password TEXT NOT NULL
+    password TEXT NOT NULL,
+    verified BOOLEAN NOT NULL DEFAULT false,
+    session_version INTEGER NOT NULL DEFAULT 0,
+    profile_hidden BOOLEAN NOT NULL DEFAULT false
 );`
 	// SQL запрос для создания таблицы маршрутов.
 	createRoutes = `CREATE TABLE IF NOT EXISTS routes (
@@ -27,6 +30,8 @@ const (
     route_id INTEGER NOT NULL,
     user_id INTEGER NOT NULL,
     path TEXT ARRAY NOT NULL,
+    step_times BIGINT ARRAY NOT NULL DEFAULT '{}',
+    back_steps BOOLEAN ARRAY NOT NULL DEFAULT '{}',
     FOREIGN KEY (route_id) REFERENCES routes(id),
     FOREIGN KEY (user_id) REFERENCES users(id)
 );`
@@ -36,7 +41,8 @@ const (
     start_time TIMESTAMP NOT NULL,
     end_time TIMESTAMP NOT NULL,
     pswd TEXT NOT NULL,
-    private BOOLEAN NOT NULL
+    private BOOLEAN NOT NULL,
+    max_users INTEGER NOT NULL DEFAULT 0
 );`
 	// SQL запрос для создания таблицы отношений соревнований и пользователей.
 	createTURelations = `CREATE TABLE IF NOT EXISTS tournament_users (
@@ -62,6 +68,309 @@ const (
     FOREIGN KEY (route_id) REFERENCES routes(id),
     PRIMARY KEY (tour_id, route_id)
 );`
+	// SQL запрос для создания таблицы архивированных соревнований.
+	createTourArchives = `CREATE TABLE IF NOT EXISTS tournament_archives (
+    tour_id INTEGER PRIMARY KEY,
+    archived_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (tour_id) REFERENCES tournaments(id)
+);`
+	// SQL запрос для создания таблицы итоговых результатов соревнований.
+	createTourResults = `CREATE TABLE IF NOT EXISTS tournament_results (
+    tour_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    user_name TEXT NOT NULL,
+    place INTEGER NOT NULL,
+    points INTEGER NOT NULL,
+    FOREIGN KEY (tour_id) REFERENCES tournaments(id),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    PRIMARY KEY (tour_id, user_id)
+);`
+	// SQL запрос для создания таблицы победителей соревнований на маршрутах.
+	createTourWinners = `CREATE TABLE IF NOT EXISTS tournament_route_winners (
+    tour_id INTEGER NOT NULL,
+    route_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    user_name TEXT NOT NULL,
+    sprint_id INTEGER NOT NULL,
+    length_time INTEGER NOT NULL,
+    FOREIGN KEY (tour_id) REFERENCES tournaments(id),
+    FOREIGN KEY (route_id) REFERENCES routes(id),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    PRIMARY KEY (tour_id, route_id)
+);`
+	// SQL запрос для создания таблицы команд соревнований.
+	createTeams = `CREATE TABLE IF NOT EXISTS tournament_teams (
+    id SERIAL PRIMARY KEY,
+    tour_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    captain_id INTEGER NOT NULL,
+    CONSTRAINT tour_team_name UNIQUE (tour_id, name),
+    FOREIGN KEY (tour_id) REFERENCES tournaments(id),
+    FOREIGN KEY (captain_id) REFERENCES users(id)
+);`
+	// SQL запрос для создания таблицы участников команд.
+	createTeamUsers = `CREATE TABLE IF NOT EXISTS team_users (
+    team_id INTEGER NOT NULL,
+    tour_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    FOREIGN KEY (team_id) REFERENCES tournament_teams(id),
+    FOREIGN KEY (tour_id) REFERENCES tournaments(id),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    PRIMARY KEY (tour_id, user_id)
+);`
+	// SQL запрос для создания таблицы приглашений в команды.
+	createTeamInvites = `CREATE TABLE IF NOT EXISTS team_invites (
+    team_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    FOREIGN KEY (team_id) REFERENCES tournament_teams(id),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    PRIMARY KEY (team_id, user_id)
+);`
+	// SQL запрос для создания таблицы сеток соревнований на выбывание.
+	createBrackets = `CREATE TABLE IF NOT EXISTS tournament_brackets (
+    tour_id INTEGER PRIMARY KEY,
+    kind TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (tour_id) REFERENCES tournaments(id)
+);`
+	// SQL запрос для создания таблицы матчей сеток соревнований.
+	createBracketMatches = `CREATE TABLE IF NOT EXISTS bracket_matches (
+    tour_id INTEGER NOT NULL,
+    num INTEGER NOT NULL,
+    side TEXT NOT NULL,
+    round INTEGER NOT NULL,
+    player1 INTEGER NOT NULL,
+    player2 INTEGER NOT NULL,
+    winner INTEGER NOT NULL,
+    route_id INTEGER NOT NULL,
+    next_win INTEGER NOT NULL,
+    next_win_slot INTEGER NOT NULL,
+    next_lose INTEGER NOT NULL,
+    next_lose_slot INTEGER NOT NULL,
+    ready_at TIMESTAMP,
+    FOREIGN KEY (tour_id) REFERENCES tournament_brackets(tour_id),
+    FOREIGN KEY (route_id) REFERENCES routes(id),
+    PRIMARY KEY (tour_id, num)
+);`
+	// SQL запрос для создания таблицы приглашений пользователей в соревнования.
+	createTourInvites = `CREATE TABLE IF NOT EXISTS tournament_invites (
+    tour_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    inviter_id INTEGER NOT NULL,
+    FOREIGN KEY (tour_id) REFERENCES tournaments(id),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    FOREIGN KEY (inviter_id) REFERENCES users(id),
+    PRIMARY KEY (tour_id, user_id)
+);`
+	// SQL запрос для создания таблицы одноразовых ссылок-приглашений в соревнования.
+	createTourInviteLinks = `CREATE TABLE IF NOT EXISTS tournament_invite_links (
+    token_hash TEXT PRIMARY KEY,
+    tour_id INTEGER NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used BOOLEAN NOT NULL,
+    FOREIGN KEY (tour_id) REFERENCES tournaments(id)
+);`
+	// SQL запрос для создания таблицы заявок на участие в соревнованиях.
+	createTourJoinRequests = `CREATE TABLE IF NOT EXISTS tournament_join_requests (
+    tour_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    FOREIGN KEY (tour_id) REFERENCES tournaments(id),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    PRIMARY KEY (tour_id, user_id)
+);`
+	// SQL запрос для создания таблицы одноразовых токенов пользователей.
+	createUserTokens = `CREATE TABLE IF NOT EXISTS user_tokens (
+    token_hash TEXT PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    kind TEXT NOT NULL,
+    email TEXT NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);`
+	// SQL запрос для создания таблицы привязок пользователей к внешним провайдерам входа.
+	createUserIdentities = `CREATE TABLE IF NOT EXISTS user_identities (
+    provider TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    user_id INTEGER NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    PRIMARY KEY (provider, subject)
+);`
+	// SQL запрос для создания таблицы персональных токенов доступа к API.
+	createApiTokens = `CREATE TABLE IF NOT EXISTS api_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    scopes TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    last_used_at TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);`
+	// SQL запрос для создания таблицы достижений пользователей.
+	createUserAchievements = `CREATE TABLE IF NOT EXISTS user_achievements (
+    user_id INTEGER NOT NULL,
+    achievement TEXT NOT NULL,
+    awarded_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    PRIMARY KEY (user_id, achievement)
+);`
+	// SQL запрос для создания таблицы рейтинга мастерства игроков.
+	createPlayerSkill = `CREATE TABLE IF NOT EXISTS player_skill (
+    user_id INTEGER PRIMARY KEY,
+    rating DOUBLE PRECISION NOT NULL,
+    games INTEGER NOT NULL,
+    last_active TIMESTAMP NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);`
+	// SQL запрос для создания таблицы сезонов рейтинга.
+	createSeasons = `CREATE TABLE IF NOT EXISTS seasons (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    start_time TIMESTAMP NOT NULL,
+    end_time TIMESTAMP NOT NULL,
+    archived BOOLEAN NOT NULL DEFAULT false
+);`
+	// SQL запрос для создания таблицы итогов сезонов.
+	createSeasonResults = `CREATE TABLE IF NOT EXISTS season_results (
+    season_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    user_name TEXT NOT NULL,
+    place INTEGER NOT NULL,
+    points INTEGER NOT NULL,
+    FOREIGN KEY (season_id) REFERENCES seasons(id),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    PRIMARY KEY (season_id, user_id)
+);`
+	// SQL запрос для создания таблицы подписок пользователей друг на друга.
+	createFollows = `CREATE TABLE IF NOT EXISTS follows (
+    follower_id INTEGER NOT NULL,
+    followee_id INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (follower_id) REFERENCES users(id),
+    FOREIGN KEY (followee_id) REFERENCES users(id),
+    PRIMARY KEY (follower_id, followee_id),
+    CHECK (follower_id <> followee_id)
+);`
+	// SQL запрос для создания таблицы итогов гонок с призраками.
+	createGhostResults = `CREATE TABLE IF NOT EXISTS ghost_results (
+    sprint_id INTEGER PRIMARY KEY,
+    ghost_sprint_id INTEGER NOT NULL,
+    beaten BOOLEAN NOT NULL,
+    margin BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (sprint_id) REFERENCES sprints(id),
+    FOREIGN KEY (ghost_sprint_id) REFERENCES sprints(id)
+);`
+	// SQL запрос для создания таблицы посчитанной статистики путей, route_id = 0 - статистика по всем маршрутам.
+	createPathInsights = `CREATE TABLE IF NOT EXISTS path_insights (
+    route_id INTEGER NOT NULL,
+    kind TEXT NOT NULL,
+    article TEXT NOT NULL,
+    hits INTEGER NOT NULL,
+    successes INTEGER NOT NULL,
+    place INTEGER NOT NULL,
+    computed_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (route_id, kind, article)
+);`
+	// SQL запрос для создания таблицы шаблонов соревнований.
+	createTourTemplates = `CREATE TABLE IF NOT EXISTS tournament_templates (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    owner_id INTEGER NOT NULL,
+    spec TEXT NOT NULL,
+    recurrence TEXT NOT NULL DEFAULT '',
+    next_start TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (owner_id) REFERENCES users(id)
+);`
+	// SQL запрос для создания таблицы подборок маршрутов.
+	createCollections = `CREATE TABLE IF NOT EXISTS collections (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    owner_id INTEGER NOT NULL,
+    private BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (owner_id) REFERENCES users(id)
+);`
+	// SQL запрос для создания таблицы маршрутов подборок.
+	createCollectionRoutes = `CREATE TABLE IF NOT EXISTS collection_routes (
+    collection_id INTEGER NOT NULL,
+    route_id INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    FOREIGN KEY (collection_id) REFERENCES collections(id),
+    FOREIGN KEY (route_id) REFERENCES routes(id),
+    PRIMARY KEY (collection_id, route_id)
+);`
+	// SQL запрос для создания таблицы комментариев к маршрутам.
+	createRouteComments = `CREATE TABLE IF NOT EXISTS route_comments (
+    id SERIAL PRIMARY KEY,
+    route_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    parent_id INTEGER,
+    body TEXT NOT NULL,
+    spoiler BOOLEAN NOT NULL DEFAULT false,
+    hidden BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (route_id) REFERENCES routes(id),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    FOREIGN KEY (parent_id) REFERENCES route_comments(id)
+);`
+	// SQL запрос для создания таблицы оценок маршрутов.
+	createRouteVotes = `CREATE TABLE IF NOT EXISTS route_votes (
+    route_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    stars SMALLINT NOT NULL CHECK (stars BETWEEN 1 AND 5),
+    FOREIGN KEY (route_id) REFERENCES routes(id),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    PRIMARY KEY (route_id, user_id)
+);`
+	// SQL запрос для создания таблицы жалоб на маршруты и комментарии.
+	createRouteReports = `CREATE TABLE IF NOT EXISTS route_reports (
+    id SERIAL PRIMARY KEY,
+    route_id INTEGER NOT NULL,
+    comment_id INTEGER,
+    user_id INTEGER NOT NULL,
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    resolved_at TIMESTAMP,
+    resolution TEXT NOT NULL DEFAULT '',
+    FOREIGN KEY (route_id) REFERENCES routes(id),
+    FOREIGN KEY (comment_id) REFERENCES route_comments(id),
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);`
+	// SQL запрос для создания таблицы заблокированных участников соревнований.
+	createTourBans = `CREATE TABLE IF NOT EXISTS tournament_bans (
+    tour_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    FOREIGN KEY (tour_id) REFERENCES tournaments(id),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    PRIMARY KEY (tour_id, user_id)
+);`
+)
+
+// SQL запросы для приведения существующих таблиц к актуальной схеме.
+const (
+	// SQL запрос для добавления в таблицу соревнований ограничения на количество участников.
+	alterToursMaxUsers = `ALTER TABLE tournaments ADD COLUMN IF NOT EXISTS max_users INTEGER NOT NULL DEFAULT 0;`
+	// SQL запрос для добавления в таблицу пользователей флага подтверждения почты.
+	alterUsersVerified = `ALTER TABLE users ADD COLUMN IF NOT EXISTS verified BOOLEAN NOT NULL DEFAULT false;`
+	// SQL запрос для добавления в таблицу пользователей версии сессий.
+	alterUsersSession = `ALTER TABLE users ADD COLUMN IF NOT EXISTS session_version INTEGER NOT NULL DEFAULT 0;`
+	// SQL запрос для добавления в таблицу пользователей флага скрытого профиля.
+	alterUsersHidden = `ALTER TABLE users ADD COLUMN IF NOT EXISTS profile_hidden BOOLEAN NOT NULL DEFAULT false;`
+	// SQL запросы для добавления в таблицу спринтов времени шагов и флагов возврата назад.
+	alterSprintsStepTimes = `ALTER TABLE sprints ADD COLUMN IF NOT EXISTS step_times BIGINT ARRAY NOT NULL DEFAULT '{}';`
+	alterSprintsBackSteps = `ALTER TABLE sprints ADD COLUMN IF NOT EXISTS back_steps BOOLEAN ARRAY NOT NULL DEFAULT '{}';`
+	// SQL запрос для добавления в таблицу матчей сетки времени, когда определились оба участника.
+	alterBracketMatchesReadyAt = `ALTER TABLE bracket_matches ADD COLUMN IF NOT EXISTS ready_at TIMESTAMP;`
+	// SQL запрос для хэширования кодов-паролей соревнований, хранившихся в открытом виде.
+	hashTourPasswords = `UPDATE tournaments SET pswd = encode(sha256(convert_to(pswd, 'UTF8')), 'hex') WHERE length(pswd) = 32;`
+	// SQL запрос для создания индекса по хэшам кодов-паролей соревнований.
+	createTourPasswordIndex = `CREATE UNIQUE INDEX IF NOT EXISTS tournaments_pswd_idx ON tournaments (pswd) WHERE pswd <> '';`
+	// SQL запрос для создания индекса, допускающего одну нерассмотренную жалобу пользователя на маршрут или комментарий.
+	createOpenReportIndex = `CREATE UNIQUE INDEX IF NOT EXISTS route_reports_open_idx
+    ON route_reports (route_id, COALESCE(comment_id, 0), user_id) WHERE resolved_at IS NULL;`
 )
 
 // SQL запросы для удаления таблиц.
@@ -80,6 +389,62 @@ const (
 	dropTCRelations = `DROP TABLE IF EXISTS tournament_creators;`
 	// SQL запрос для удаления таблицы отношений соревнований и маршрутов.
 	dropTRRelations = `DROP TABLE IF EXISTS tournament_routes;`
+	// SQL запрос для удаления таблицы архивированных соревнований.
+	dropTourArchives = `DROP TABLE IF EXISTS tournament_archives;`
+	// SQL запрос для удаления таблицы итоговых результатов соревнований.
+	dropTourResults = `DROP TABLE IF EXISTS tournament_results;`
+	// SQL запрос для удаления таблицы победителей соревнований на маршрутах.
+	dropTourWinners = `DROP TABLE IF EXISTS tournament_route_winners;`
+	// SQL запрос для удаления таблицы команд соревнований.
+	dropTeams = `DROP TABLE IF EXISTS tournament_teams;`
+	// SQL запрос для удаления таблицы участников команд.
+	dropTeamUsers = `DROP TABLE IF EXISTS team_users;`
+	// SQL запрос для удаления таблицы приглашений в команды.
+	dropTeamInvites = `DROP TABLE IF EXISTS team_invites;`
+	// SQL запрос для удаления таблицы сеток соревнований на выбывание.
+	dropBrackets = `DROP TABLE IF EXISTS tournament_brackets;`
+	// SQL запрос для удаления таблицы матчей сеток соревнований.
+	dropBracketMatches = `DROP TABLE IF EXISTS bracket_matches;`
+	// SQL запрос для удаления таблицы приглашений пользователей в соревнования.
+	dropTourInvites = `DROP TABLE IF EXISTS tournament_invites;`
+	// SQL запрос для удаления таблицы одноразовых ссылок-приглашений в соревнования.
+	dropTourInviteLinks = `DROP TABLE IF EXISTS tournament_invite_links;`
+	// SQL запрос для удаления таблицы заявок на участие в соревнованиях.
+	dropTourJoinRequests = `DROP TABLE IF EXISTS tournament_join_requests;`
+	// SQL запрос для удаления таблицы привязок пользователей к внешним провайдерам входа.
+	dropUserIdentities = `DROP TABLE IF EXISTS user_identities;`
+	// SQL запрос для удаления таблицы жалоб на маршруты и комментарии.
+	dropRouteReports = `DROP TABLE IF EXISTS route_reports;`
+	// SQL запрос для удаления таблицы оценок маршрутов.
+	dropRouteVotes = `DROP TABLE IF EXISTS route_votes;`
+	// SQL запрос для удаления таблицы комментариев к маршрутам.
+	dropRouteComments = `DROP TABLE IF EXISTS route_comments;`
+	// SQL запрос для удаления таблицы маршрутов подборок.
+	dropCollectionRoutes = `DROP TABLE IF EXISTS collection_routes;`
+	// SQL запрос для удаления таблицы подборок маршрутов.
+	dropCollections = `DROP TABLE IF EXISTS collections;`
+	// SQL запрос для удаления таблицы шаблонов соревнований.
+	dropTourTemplates = `DROP TABLE IF EXISTS tournament_templates;`
+	// SQL запрос для удаления таблицы статистики путей.
+	dropPathInsights = `DROP TABLE IF EXISTS path_insights;`
+	// SQL запрос для удаления таблицы итогов гонок с призраками.
+	dropGhostResults = `DROP TABLE IF EXISTS ghost_results;`
+	// SQL запрос для удаления таблицы подписок.
+	dropFollows = `DROP TABLE IF EXISTS follows;`
+	// SQL запрос для удаления таблицы итогов сезонов.
+	dropSeasonResults = `DROP TABLE IF EXISTS season_results;`
+	// SQL запрос для удаления таблицы сезонов рейтинга.
+	dropSeasons = `DROP TABLE IF EXISTS seasons;`
+	// SQL запрос для удаления таблицы рейтинга мастерства игроков.
+	dropPlayerSkill = `DROP TABLE IF EXISTS player_skill;`
+	// SQL запрос для удаления таблицы достижений пользователей.
+	dropUserAchievements = `DROP TABLE IF EXISTS user_achievements;`
+	// SQL запрос для удаления таблицы персональных токенов доступа к API.
+	dropApiTokens = `DROP TABLE IF EXISTS api_tokens;`
+	// SQL запрос для удаления таблицы одноразовых токенов пользователей.
+	dropUserTokens = `DROP TABLE IF EXISTS user_tokens;`
+	// SQL запрос для удаления таблицы заблокированных участников соревнований.
+	dropTourBans = `DROP TABLE IF EXISTS tournament_bans;`
 )
 
 // SQL запросы для получения данных.
@@ -134,10 +499,261 @@ const (
 	getRoute = `SELECT * FROM routes WHERE id = $1;`
 	// SQL запрос для получения данных о маршруте по start, finish.
 	getRouteByCreds = `SELECT * FROM routes WHERE start = $1 AND finish = $2;`
+	// SQL запрос для получения id маршрута по start, finish.
+	getRouteIdByCreds = `SELECT id FROM routes WHERE start = $1 AND finish = $2;`
 	// SQL запрос для получения данных о спринте по id.
 	getSprint = `SELECT * FROM sprints WHERE id = $1;`
 	// SQL запрос для получения данных о соревновании по id.
 	getTournament = `SELECT * FROM tournaments WHERE id = $1;`
+	// SQL запрос для получения завершившихся, но не архивированных соревнований.
+	getTournamentsToArchive = `SELECT * FROM tournaments WHERE end_time < $1 AND id NOT IN (
+        SELECT tour_id FROM tournament_archives
+    );`
+	// SQL запрос для получения архивированных соревнований.
+	getArchivedTournaments = `SELECT t.* FROM tournaments t JOIN tournament_archives ta ON t.id = ta.tour_id
+    WHERE t.private = false OR t.id IN (
+        SELECT tour_id FROM tournament_users WHERE user_id = $1
+        UNION SELECT tour_id FROM tournament_creators WHERE user_id = $1
+    ) ORDER BY t.end_time DESC;`
+	// SQL запрос для получения итоговых результатов соревнования по tournament.Id.
+	getTournamentResults = `SELECT * FROM tournament_results WHERE tour_id = $1 ORDER BY place, user_name;`
+	// SQL запрос для получения победителей соревнования на маршрутах по tournament.Id.
+	getTournamentWinners = `SELECT w.*, r.start, r.finish FROM tournament_route_winners w JOIN routes r ON w.route_id = r.id
+    WHERE w.tour_id = $1 ORDER BY w.route_id;`
+	// SQL запрос для получения итоговых мест пользователя в соревнованиях по user.Id.
+	getUserPlacements = `SELECT tr.* FROM tournament_results tr JOIN tournaments t ON tr.tour_id = t.id
+    WHERE tr.user_id = $1 ORDER BY t.end_time DESC;`
+	// SQL запрос для получения итоговой статистики пользователя по user.Id.
+	getUserStats = `SELECT
+    COUNT(*) AS sprints,
+    COUNT(*) FILTER (WHERE success) AS successful,
+    COALESCE(AVG(array_length(path, 1)) FILTER (WHERE success), 0) AS avg_clicks,
+    (SELECT COUNT(*) FROM (
+        SELECT DISTINCT ON (route_id) route_id, user_id FROM sprints
+        WHERE success = true ORDER BY route_id, length_time, start_time
+    ) AS records WHERE records.user_id = $1) AS wins,
+    (SELECT COUNT(*) FROM routes WHERE creator_id = $1) AS routes_created,
+    (SELECT COUNT(*) FROM follows WHERE followee_id = $1) AS followers,
+    (SELECT COUNT(*) FROM follows WHERE follower_id = $1) AS following
+    FROM sprints WHERE user_id = $1;`
+	// SQL запрос для получения лучших спринтов пользователя на каждом маршруте по user.Id.
+	getUserBests = `SELECT * FROM (
+        SELECT DISTINCT ON (s.route_id) s.route_id, r.start, r.finish, s.id AS sprint_id, s.length_time,
+        COALESCE(array_length(s.path, 1), 0) AS steps
+        FROM sprints s JOIN routes r ON r.id = s.route_id
+        WHERE s.user_id = $1 AND s.success = true
+        ORDER BY s.route_id, s.length_time, s.start_time
+    ) AS bests ORDER BY length_time;`
+	// SQL запрос для получения активности пользователя по месяцам по user.Id.
+	getUserActivity = `SELECT date_trunc('month', start_time) AS month,
+    COUNT(*) AS sprints, COUNT(*) FILTER (WHERE success) AS successful
+    FROM sprints WHERE user_id = $1 GROUP BY month ORDER BY month;`
+	// SQL запрос для получения данных пользователя, по которым выдаются достижения, по user.Id.
+	getAchievementFacts = `SELECT
+    COUNT(*) AS sprints,
+    COUNT(*) FILTER (WHERE success) AS successful,
+    COUNT(DISTINCT route_id) FILTER (WHERE success) AS routes,
+    COALESCE(MIN(length_time) FILTER (WHERE success), 0) AS fastest,
+    (SELECT COUNT(*) FROM (
+        SELECT DISTINCT ON (route_id) route_id, user_id FROM sprints
+        WHERE success = true ORDER BY route_id, length_time, start_time
+    ) AS records WHERE records.user_id = $1) AS records,
+    (SELECT COUNT(*) FROM routes WHERE creator_id = $1) AS routes_created,
+    (SELECT COUNT(DISTINCT s.route_id) FROM sprints s JOIN (
+        SELECT route_id, MIN(array_length(path, 1)) AS steps FROM sprints
+        WHERE success = true GROUP BY route_id HAVING COUNT(DISTINCT user_id) >= 3
+    ) AS optimal ON optimal.route_id = s.route_id AND array_length(s.path, 1) = optimal.steps
+    WHERE s.user_id = $1 AND s.success = true) AS optimal,
+    (SELECT COUNT(*) FROM tournament_results WHERE user_id = $1 AND place = 1) AS tour_wins,
+    (SELECT COUNT(*) FROM tournament_results WHERE user_id = $1) AS tournaments
+    FROM sprints WHERE user_id = $1;`
+	// SQL запрос для получения достижений пользователя по user.Id.
+	getUserAchievements = `SELECT * FROM user_achievements WHERE user_id = $1 ORDER BY awarded_at;`
+	// SQL запрос для получения достижений нескольких пользователей по массиву user.Id.
+	getUsersAchievements = `SELECT * FROM user_achievements WHERE user_id = ANY($1) ORDER BY awarded_at;`
+	// SQL запрос для получения id всех пользователей.
+	getUsersIds = `SELECT id FROM users ORDER BY id;`
+	// SQL запрос для получения рейтинга мастерства всех игроков.
+	getSkillLadder = `SELECT ps.user_id, u.name AS user_name, ps.rating, ps.games, ps.last_active
+    FROM player_skill ps JOIN users u ON u.id = ps.user_id;`
+	// SQL запрос для получения рейтинга мастерства игроков по массиву user.Id.
+	getPlayerSkills = `SELECT ps.user_id, u.name AS user_name, ps.rating, ps.games, ps.last_active
+    FROM player_skill ps JOIN users u ON u.id = ps.user_id WHERE ps.user_id = ANY($1);`
+	// SQL запрос для получения всех успешных спринтов без путей в порядке их прохождения.
+	getSuccessfulSprints = `SELECT id, user_id, route_id, length_time, start_time, success
+    FROM sprints WHERE success = true ORDER BY start_time, id;`
+	// SQL запрос для получения сезона по id.
+	getSeason = `SELECT * FROM seasons WHERE id = $1;`
+	// SQL запрос для получения всех сезонов.
+	getSeasons = `SELECT * FROM seasons ORDER BY start_time DESC;`
+	// SQL запрос для получения закончившихся сезонов, итоги которых ещё не зафиксированы, по текущему времени.
+	getSeasonsToArchive = `SELECT * FROM seasons WHERE archived = false AND end_time < $1;`
+	// SQL запрос для получения итогов сезона по season.Id.
+	getSeasonResults = `SELECT * FROM season_results WHERE season_id = $1 ORDER BY place, user_name;`
+	// SQL запрос для получения игроков, на которых подписан пользователь, по follower_id, viewer_id, скрытые профили видит только сам подписчик.
+	getFollowing = `SELECT u.id AS user_id, u.name AS user_name, f.created_at AS since
+    FROM follows f JOIN users u ON u.id = f.followee_id
+    WHERE f.follower_id = $1 AND (u.profile_hidden = false OR f.follower_id = $2) ORDER BY u.name;`
+	// SQL запрос для получения id игроков, на которых подписан пользователь, по follower_id.
+	getFollowingIds = `SELECT followee_id FROM follows WHERE follower_id = $1;`
+	// SQL запрос для проверки подписки по follower_id, followee_id.
+	isFollowing = `SELECT EXISTS (SELECT 1 FROM follows WHERE follower_id = $1 AND followee_id = $2);`
+	// SQL запрос для получения последних спринтов игроков, на которых подписан пользователь, по follower_id, limit.
+	getFollowFeed = `SELECT s.id AS sprint_id, s.user_id, u.name AS user_name, s.route_id, r.start, r.finish,
+    s.success, s.length_time, COALESCE(array_length(s.path, 1), 0) AS steps, s.start_time
+    FROM follows f
+    JOIN sprints s ON s.user_id = f.followee_id
+    JOIN users u ON u.id = s.user_id
+    JOIN routes r ON r.id = s.route_id
+    WHERE f.follower_id = $1 AND u.profile_hidden = false
+    ORDER BY s.start_time DESC LIMIT $2;`
+	// SQL запрос для получения команды по id.
+	getTeam = `SELECT * FROM tournament_teams WHERE id = $1;`
+	// SQL запрос для получения команд соревнования по tournament.Id.
+	getTournamentTeams = `SELECT * FROM tournament_teams WHERE tour_id = $1 ORDER BY name;`
+	// SQL запрос для получения участников команды по team.Id.
+	getTeamMembers = `SELECT * FROM users WHERE id IN (
+        SELECT user_id FROM team_users WHERE team_id = $1
+    );`
+	// SQL запрос для получения отношений участников и команд соревнования по tournament.Id.
+	getTournamentTeamUsers = `SELECT team_id, user_id FROM team_users WHERE tour_id = $1;`
+	// SQL запрос для получения команды пользователя в соревновании по tour_id, user_id.
+	getUserTeam = `SELECT * FROM tournament_teams WHERE id = (
+        SELECT team_id FROM team_users WHERE tour_id = $1 AND user_id = $2
+    );`
+	// SQL запрос для получения команд соревнования, в которые приглашён пользователь, по tour_id, user_id.
+	getUserTeamInvites = `SELECT * FROM tournament_teams WHERE tour_id = $1 AND id IN (
+        SELECT team_id FROM team_invites WHERE user_id = $2
+    );`
+	// SQL запрос для получения следующего капитана команды по team_id.
+	getNextTeamCaptain = `SELECT MIN(user_id) FROM team_users WHERE team_id = $1;`
+	// SQL запрос для получения участников соревнования, в том числе через команды, по tournament.Id.
+	getTournamentParticipants = `SELECT * FROM users WHERE id IN (
+        SELECT user_id FROM tournament_users WHERE tour_id = $1
+        UNION SELECT user_id FROM team_users WHERE tour_id = $1
+    ) ORDER BY id;`
+	// SQL запрос для получения сетки соревнования по tournament.Id.
+	getBracket = `SELECT * FROM tournament_brackets WHERE tour_id = $1;`
+	// SQL запрос для получения матчей сетки соревнования по tournament.Id.
+	getBracketMatches = `SELECT * FROM bracket_matches WHERE tour_id = $1 ORDER BY num;`
+	// SQL запрос для получения матчей сетки соревнования с блокировкой по tournament.Id.
+	getBracketMatchesForUpdate = `SELECT * FROM bracket_matches WHERE tour_id = $1 ORDER BY num FOR UPDATE;`
+	// SQL запрос для получения лучшего успешного спринта пользователя на маршруте за промежуток времени по user_id, route_id, start time, end time.
+	getUserBestSprintInWindow = `SELECT * FROM sprints
+    WHERE user_id = $1 AND route_id = $2 AND success = true AND start_time > $3 AND start_time < $4
+    ORDER BY length_time, id LIMIT 1;`
+	// SQL запрос для получения рекордного спринта на маршруте по route_id.
+	getRouteRecordSprint = `SELECT * FROM sprints WHERE route_id = $1 AND success = true ORDER BY length_time, id LIMIT 1;`
+	// SQL запрос для получения лучшего успешного спринта пользователя на маршруте по user_id, route_id.
+	getUserBestSprint = `SELECT * FROM sprints WHERE user_id = $1 AND route_id = $2 AND success = true ORDER BY length_time, id LIMIT 1;`
+	// SQL запрос для получения итога гонки с призраком по sprint_id.
+	getGhostResult = `SELECT * FROM ghost_results WHERE sprint_id = $1;`
+	// SQL запрос для получения путей всех спринтов для расчёта статистики.
+	getSprintPaths = `SELECT s.route_id, r.start, r.finish, s.path, s.back_steps, s.success
+    FROM sprints s JOIN routes r ON r.id = s.route_id;`
+	// SQL запрос для получения статистики путей маршрута по route_id.
+	getPathInsights = `SELECT * FROM path_insights WHERE route_id = $1 ORDER BY kind, place;`
+	// SQL запрос для выгрузки истории спринтов пользователя по user_id.
+	exportUserSprints = `SELECT s.id AS sprint_id, s.user_id, u.name AS user_name, s.route_id, r.start, r.finish,
+    s.start_time, s.length_time, COALESCE(array_length(s.path, 1), 0) AS steps, s.success
+    FROM sprints s JOIN users u ON u.id = s.user_id JOIN routes r ON r.id = s.route_id
+    WHERE s.user_id = $1 ORDER BY s.start_time, s.id;`
+	// SQL запрос для выгрузки всех спринтов участников соревнования на его маршрутах во время соревнования по tournament.Id.
+	exportTourSprints = `SELECT s.id AS sprint_id, s.user_id, u.name AS user_name, s.route_id, r.start, r.finish,
+    s.start_time, s.length_time, COALESCE(array_length(s.path, 1), 0) AS steps, s.success
+    FROM sprints s JOIN users u ON u.id = s.user_id JOIN routes r ON r.id = s.route_id
+    JOIN tournaments t ON t.id = $1
+    WHERE s.route_id IN (SELECT route_id FROM tournament_routes WHERE tour_id = $1)
+    AND s.user_id IN (
+        SELECT user_id FROM tournament_users WHERE tour_id = $1
+        UNION SELECT user_id FROM team_users WHERE tour_id = $1
+    )
+    AND s.start_time > t.start_time AND s.start_time < t.end_time
+    ORDER BY s.start_time, s.id;`
+	// SQL запрос для выгрузки рейтинга маршрута с именами пользователей по route_id, start, end (NULL - без ограничения).
+	exportRouteRating = `SELECT b.user_id, u.name AS user_name, b.sprint_id, b.length_time, b.steps FROM (
+        SELECT DISTINCT ON (s.user_id) s.user_id, s.id AS sprint_id, s.length_time, COALESCE(array_length(s.path, 1), 0) AS steps
+        FROM sprints s WHERE s.route_id = $1 AND s.success = true
+        AND ($2::timestamp IS NULL OR s.start_time > $2) AND ($3::timestamp IS NULL OR s.start_time < $3)
+        ORDER BY s.user_id, s.length_time, s.id
+    ) AS b JOIN users u ON u.id = b.user_id
+    ORDER BY b.length_time, b.sprint_id;`
+	// SQL запрос для получения шаблона соревнования по id.
+	getTourTemplate = `SELECT * FROM tournament_templates WHERE id = $1;`
+	// SQL запрос для получения шаблонов соревнований пользователя по owner_id.
+	getUserTourTemplates = `SELECT * FROM tournament_templates WHERE owner_id = $1 ORDER BY name, id;`
+	// SQL запрос для получения повторяющихся шаблонов, по которым пора создать соревнование, по времени.
+	getDueTourTemplates = `SELECT * FROM tournament_templates WHERE recurrence <> '' AND next_start <= $1 ORDER BY next_start;`
+	// SQL запрос для получения подборки по id.
+	getCollection = `SELECT * FROM collections WHERE id = $1;`
+	// SQL запрос для получения подборок пользователя по owner_id.
+	getUserCollections = `SELECT * FROM collections WHERE owner_id = $1 ORDER BY name, id;`
+	// SQL запрос для получения открытых подборок.
+	getPublicCollections = `SELECT * FROM collections WHERE private = false ORDER BY created_at DESC;`
+	// SQL запрос для получения маршрутов подборки с отметкой прохождения по collection_id, user_id.
+	getCollectionRoutes = `SELECT r.*, cr.position,
+    EXISTS (SELECT 1 FROM sprints s WHERE s.route_id = r.id AND s.user_id = $2 AND s.success = true) AS completed
+    FROM collection_routes cr JOIN routes r ON r.id = cr.route_id
+    WHERE cr.collection_id = $1 ORDER BY cr.position;`
+	// SQL запрос для получения соседнего маршрута подборки сверху по collection_id, position.
+	getPrevCollectionRoute = `SELECT route_id, position FROM collection_routes
+    WHERE collection_id = $1 AND position < $2 ORDER BY position DESC LIMIT 1;`
+	// SQL запрос для получения соседнего маршрута подборки снизу по collection_id, position.
+	getNextCollectionRoute = `SELECT route_id, position FROM collection_routes
+    WHERE collection_id = $1 AND position > $2 ORDER BY position LIMIT 1;`
+	// SQL запрос для получения позиции маршрута в подборке по collection_id, route_id.
+	getCollectionRoutePosition = `SELECT position FROM collection_routes WHERE collection_id = $1 AND route_id = $2;`
+	// SQL запрос для получения комментариев к маршруту по route_id.
+	getRouteComments = `SELECT c.id, c.route_id, c.user_id, u.name AS user_name, c.parent_id,
+    c.body, c.spoiler, c.hidden, c.created_at
+    FROM route_comments c JOIN users u ON u.id = c.user_id
+    WHERE c.route_id = $1 ORDER BY c.created_at, c.id;`
+	// SQL запрос для получения комментария по id.
+	getRouteComment = `SELECT c.id, c.route_id, c.user_id, u.name AS user_name, c.parent_id,
+    c.body, c.spoiler, c.hidden, c.created_at
+    FROM route_comments c JOIN users u ON u.id = c.user_id WHERE c.id = $1;`
+	// SQL запрос для получения оценок маршрута по route_id, user_id.
+	getRouteVotes = `SELECT COALESCE(AVG(stars), 0) AS average, COUNT(*) AS count,
+    COALESCE(MAX(stars) FILTER (WHERE user_id = $2), 0) AS own
+    FROM route_votes WHERE route_id = $1;`
+	// SQL запрос для подсчёта нерассмотренных жалоб на комментарий по comment_id.
+	countOpenCommentReports = `SELECT COUNT(*) FROM route_reports WHERE comment_id = $1 AND resolved_at IS NULL;`
+	// SQL запрос для получения нерассмотренных жалоб.
+	getOpenReports = `SELECT * FROM route_reports WHERE resolved_at IS NULL ORDER BY created_at;`
+	// SQL запрос для получения жалобы по id.
+	getRouteReport = `SELECT * FROM route_reports WHERE id = $1;`
+	// SQL запрос для получения пользователей по user.Name.
+	getUsersByName = `SELECT * FROM users WHERE name = $1;`
+	// SQL запрос для получения количества участников соревнования, в том числе через команды, по tournament.Id.
+	getTournamentUsersCount = `SELECT COUNT(*) FROM (
+        SELECT user_id FROM tournament_users WHERE tour_id = $1
+        UNION SELECT user_id FROM team_users WHERE tour_id = $1
+    ) AS participants;`
+	// SQL запрос для получения соревнования с блокировкой по id.
+	getTournamentForUpdate = `SELECT * FROM tournaments WHERE id = $1 FOR UPDATE;`
+	// SQL запрос для получения пользователей, подавших заявку на участие в соревновании, по tournament.Id.
+	getTourJoinRequests = `SELECT * FROM users WHERE id IN (
+        SELECT user_id FROM tournament_join_requests WHERE tour_id = $1
+    ) ORDER BY name;`
+	// SQL запрос для получения заблокированных в соревновании пользователей по tournament.Id.
+	getTourBans = `SELECT * FROM users WHERE id IN (
+        SELECT user_id FROM tournament_bans WHERE tour_id = $1
+    ) ORDER BY name;`
+	// SQL запрос для получения соревнований, в которые приглашён пользователь, по user.Id.
+	getUserTourInvites = `SELECT * FROM tournaments WHERE id IN (
+        SELECT tour_id FROM tournament_invites WHERE user_id = $1
+    ) AND end_time > $2;`
+	// SQL запрос для получения персональных токенов пользователя по user_id.
+	getUserApiTokens = `SELECT * FROM api_tokens WHERE user_id = $1 ORDER BY created_at DESC;`
+	// SQL запрос для получения пользователя по привязке к внешнему провайдеру по provider, subject.
+	getUserByIdentity = `SELECT * FROM users WHERE id = (
+        SELECT user_id FROM user_identities WHERE provider = $1 AND subject = $2
+    );`
+	// SQL запрос для получения действующего одноразового токена с его блокировкой по token_hash, kind.
+	getUserToken = `SELECT * FROM user_tokens WHERE token_hash = $1 AND kind = $2 AND expires_at > $3 FOR UPDATE;`
+	// SQL запрос для получения соревнования по действующей ссылке-приглашению с её блокировкой по token_hash.
+	getTourByInviteLink = `SELECT tour_id FROM tournament_invite_links
+    WHERE token_hash = $1 AND used = false AND expires_at > $2 FOR UPDATE;`
 )
 
 // SQL запросы для добавления данных.
@@ -146,17 +762,93 @@ const (
 	addUser = `INSERT INTO users (name, email, password) VALUES ($1, $2, $3) RETURNING id;`
 	// SQL запрос для добавления маршрута по start, finish, creator_id.
 	addRoute = `INSERT INTO routes (start, finish, creator_id) VALUES ($1, $2, $3) RETURNING id;`
-	// SQL запрос для добавления спринта по start_time, length_time, success, route_id, user_id, path.
-	addSprint = `INSERT INTO sprints (start_time, length_time, success, route_id, user_id, path)
-    VALUES ($1, $2, $3, $4, $5, $6) RETURNING id;`
-	// SQL запрос для добавления соревнования по start_time, end_time, pswd, private.
-	addTour = `INSERT INTO tournaments (start_time, end_time, pswd, private) VALUES ($1, $2, $3, $4) RETURNING id;`
+	// SQL запрос для добавления спринта по start_time, length_time, success, route_id, user_id, path, step_times, back_steps.
+	addSprint = `INSERT INTO sprints (start_time, length_time, success, route_id, user_id, path, step_times, back_steps)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id;`
+	// SQL запрос для добавления соревнования по start_time, end_time, pswd, private, max_users.
+	addTour = `INSERT INTO tournaments (start_time, end_time, pswd, private, max_users) VALUES ($1, $2, $3, $4, $5) RETURNING id;`
 	// SQL запрос для добавления маршрута в соревнование по tour_id, route_id.
 	addRouteToTour = `INSERT INTO tournament_routes (tour_id, route_id) VALUES ($1, $2);`
 	// SQL запрос для добавления пользователя в соревнование по tour_id, user_id.
 	addUserToTour = `INSERT INTO tournament_users (tour_id, user_id) VALUES ($1, $2);`
 	// SQL запрос для добавления создателя в соревнование по tour_id, user_id.
 	addCreatorToTour = `INSERT INTO tournament_creators (tour_id, user_id) VALUES ($1, $2);`
+	// SQL запрос для архивации соревнования по tour_id, archived_at.
+	addTourArchive = `INSERT INTO tournament_archives (tour_id, archived_at) VALUES ($1, $2) ON CONFLICT DO NOTHING;`
+	// SQL запрос для добавления итогового результата соревнования по tour_id, user_id, user_name, place, points.
+	addTourResult = `INSERT INTO tournament_results (tour_id, user_id, user_name, place, points) VALUES ($1, $2, $3, $4, $5);`
+	// SQL запрос для добавления победителя соревнования на маршруте по tour_id, route_id, user_id, user_name, sprint_id, length_time.
+	addTourWinner = `INSERT INTO tournament_route_winners (tour_id, route_id, user_id, user_name, sprint_id, length_time)
+    VALUES ($1, $2, $3, $4, $5, $6);`
+	// SQL запрос для добавления команды по tour_id, name, captain_id.
+	addTeam = `INSERT INTO tournament_teams (tour_id, name, captain_id) VALUES ($1, $2, $3) RETURNING id;`
+	// SQL запрос для добавления пользователя в команду по team_id, tour_id, user_id.
+	addUserToTeam = `INSERT INTO team_users (team_id, tour_id, user_id) VALUES ($1, $2, $3);`
+	// SQL запрос для добавления приглашения в команду по team_id, user_id.
+	addTeamInvite = `INSERT INTO team_invites (team_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING;`
+	// SQL запрос для добавления сетки соревнования по tour_id, kind, created_at.
+	addBracket = `INSERT INTO tournament_brackets (tour_id, kind, created_at) VALUES ($1, $2, $3);`
+	// SQL запрос для добавления матча сетки по всем его полям.
+	addBracketMatch = `INSERT INTO bracket_matches
+    (tour_id, num, side, round, player1, player2, winner, route_id, next_win, next_win_slot, next_lose, next_lose_slot, ready_at)
+    VALUES (:tour_id, :num, :side, :round, :player1, :player2, :winner, :route_id, :next_win, :next_win_slot, :next_lose, :next_lose_slot, :ready_at);`
+	// SQL запрос для добавления приглашения пользователя в соревнование по tour_id, user_id, inviter_id.
+	addTourInvite = `INSERT INTO tournament_invites (tour_id, user_id, inviter_id) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING;`
+	// SQL запрос для добавления ссылки-приглашения по token_hash, tour_id, expires_at.
+	addTourInviteLink = `INSERT INTO tournament_invite_links (token_hash, tour_id, expires_at, used) VALUES ($1, $2, $3, false);`
+	// SQL запрос для добавления заявки на участие в соревновании по tour_id, user_id.
+	addTourJoinRequest = `INSERT INTO tournament_join_requests (tour_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING;`
+	// SQL запрос для добавления одноразового токена пользователя по token_hash, user_id, kind, email, expires_at.
+	addUserToken = `INSERT INTO user_tokens (token_hash, user_id, kind, email, expires_at) VALUES (:token_hash, :user_id, :kind, :email, :expires_at);`
+	// SQL запрос для сохранения рейтинга мастерства игрока по user_id, rating, games, last_active.
+	upsertPlayerSkill = `INSERT INTO player_skill (user_id, rating, games, last_active) VALUES ($1, $2, $3, $4)
+    ON CONFLICT (user_id) DO UPDATE SET rating = EXCLUDED.rating, games = EXCLUDED.games, last_active = EXCLUDED.last_active;`
+	// SQL запрос для добавления сезона по name, start_time, end_time.
+	addSeason = `INSERT INTO seasons (name, start_time, end_time) VALUES ($1, $2, $3) RETURNING id;`
+	// SQL запрос для добавления итога сезона по season_id, user_id, user_name, place, points.
+	addSeasonResult = `INSERT INTO season_results (season_id, user_id, user_name, place, points) VALUES ($1, $2, $3, $4, $5);`
+	// SQL запрос для пометки итогов сезона зафиксированными по id.
+	archiveSeason = `UPDATE seasons SET archived = true WHERE id = $1;`
+	// SQL запрос для добавления итога гонки с призраком по sprint_id, ghost_sprint_id, beaten, margin, created_at.
+	addGhostResult = `INSERT INTO ghost_results (sprint_id, ghost_sprint_id, beaten, margin, created_at) VALUES ($1, $2, $3, $4, $5);`
+	// SQL запрос для добавления статьи в статистику путей по route_id, kind, article, hits, successes, place, computed_at.
+	addPathInsight = `INSERT INTO path_insights (route_id, kind, article, hits, successes, place, computed_at)
+    VALUES ($1, $2, $3, $4, $5, $6, $7);`
+	// SQL запрос для добавления шаблона соревнования по name, owner_id, spec, recurrence, next_start, created_at.
+	addTourTemplate = `INSERT INTO tournament_templates (name, owner_id, spec, recurrence, next_start, created_at)
+    VALUES ($1, $2, $3, $4, $5, $6) RETURNING id;`
+	// SQL запрос для добавления подборки по name, description, owner_id, private, created_at.
+	addCollection = `INSERT INTO collections (name, description, owner_id, private, created_at)
+    VALUES ($1, $2, $3, $4, $5) RETURNING id;`
+	// SQL запрос для добавления маршрута в конец подборки по collection_id, route_id.
+	addRouteToCollection = `INSERT INTO collection_routes (collection_id, route_id, position)
+    SELECT $1, $2, COALESCE(MAX(position), 0) + 1 FROM collection_routes WHERE collection_id = $1
+    ON CONFLICT DO NOTHING;`
+	// SQL запрос для добавления в соревнование ещё не добавленных маршрутов подборки по tour_id, collection_id.
+	addCollectionToTour = `INSERT INTO tournament_routes (tour_id, route_id)
+    SELECT $1, route_id FROM collection_routes WHERE collection_id = $2
+    AND route_id NOT IN (SELECT route_id FROM tournament_routes WHERE tour_id = $1);`
+	// SQL запрос для добавления комментария к маршруту по route_id, user_id, parent_id, body, spoiler, created_at.
+	addRouteComment = `INSERT INTO route_comments (route_id, user_id, parent_id, body, spoiler, created_at)
+    VALUES ($1, $2, $3, $4, $5, $6) RETURNING id;`
+	// SQL запрос для оценки маршрута по route_id, user_id, stars.
+	setRouteVote = `INSERT INTO route_votes (route_id, user_id, stars) VALUES ($1, $2, $3)
+    ON CONFLICT (route_id, user_id) DO UPDATE SET stars = EXCLUDED.stars;`
+	// SQL запрос для добавления жалобы по route_id, comment_id, user_id, reason, created_at.
+	addRouteReport = `INSERT INTO route_reports (route_id, comment_id, user_id, reason, created_at)
+    VALUES ($1, $2, $3, $4, $5) RETURNING id;`
+	// SQL запрос для подписки пользователя на игрока по follower_id, followee_id, created_at.
+	addFollow = `INSERT INTO follows (follower_id, followee_id, created_at) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING;`
+	// SQL запрос для выдачи достижения пользователю по user_id, achievement, awarded_at.
+	addUserAchievement = `INSERT INTO user_achievements (user_id, achievement, awarded_at) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING;`
+	// SQL запрос для добавления персонального токена по user_id, name, token_hash, scopes, created_at.
+	addApiToken = `INSERT INTO api_tokens (user_id, name, token_hash, scopes, created_at) VALUES ($1, $2, $3, $4, $5) RETURNING id;`
+	// SQL запрос для привязки пользователя к внешнему провайдеру по provider, subject, user_id.
+	addUserIdentity = `INSERT INTO user_identities (provider, subject, user_id) VALUES ($1, $2, $3);`
+	// SQL запрос для добавления пользователя с подтверждённой почтой по name, email, password.
+	addVerifiedUser = `INSERT INTO users (name, email, password, verified) VALUES ($1, $2, $3, true) RETURNING id;`
+	// SQL запрос для блокировки пользователя в соревновании по tour_id, user_id.
+	addTourBan = `INSERT INTO tournament_bans (tour_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING;`
 )
 
 // SQL запросы для удаления данных.
@@ -168,26 +860,141 @@ const (
 	// SQL запрос для добавления создателя из соревнования по tour_id, user_id.
 	removeCreatorsFromTour = `DELETE FROM tournament_creators WHERE tour_id = $1 AND user_id = $2;`
 	// SQL запрос для удаления соревнования по id.
-	deleteTournament       = `DELETE FROM tournaments WHERE id = $1;`
-	deleteTourFromCreators = `DELETE FROM tournament_creators WHERE tour_id = $1;`
-	deleteTourFromUsers    = `DELETE FROM tournament_users WHERE tour_id = $1;`
-	deleteTourFromRoutes   = `DELETE FROM tournament_routes WHERE tour_id = $1;`
+	deleteTournament           = `DELETE FROM tournaments WHERE id = $1;`
+	deleteTourFromCreators     = `DELETE FROM tournament_creators WHERE tour_id = $1;`
+	deleteTourFromUsers        = `DELETE FROM tournament_users WHERE tour_id = $1;`
+	deleteTourFromRoutes       = `DELETE FROM tournament_routes WHERE tour_id = $1;`
+	deleteTourFromArchives     = `DELETE FROM tournament_archives WHERE tour_id = $1;`
+	deleteTourFromResults      = `DELETE FROM tournament_results WHERE tour_id = $1;`
+	deleteTourFromWinners      = `DELETE FROM tournament_route_winners WHERE tour_id = $1;`
+	deleteTourFromInvites      = `DELETE FROM team_invites WHERE team_id IN (SELECT id FROM tournament_teams WHERE tour_id = $1);`
+	deleteTourFromTeamUsers    = `DELETE FROM team_users WHERE tour_id = $1;`
+	deleteTourFromTeams        = `DELETE FROM tournament_teams WHERE tour_id = $1;`
+	deleteTourFromTourInvites  = `DELETE FROM tournament_invites WHERE tour_id = $1;`
+	deleteTourFromInviteLinks  = `DELETE FROM tournament_invite_links WHERE tour_id = $1;`
+	deleteTourFromJoinRequests = `DELETE FROM tournament_join_requests WHERE tour_id = $1;`
+	deleteTourFromBans         = `DELETE FROM tournament_bans WHERE tour_id = $1;`
+	// SQL запрос для удаления приглашения в команду по team_id, user_id.
+	removeTeamInvite = `DELETE FROM team_invites WHERE team_id = $1 AND user_id = $2;`
+	// SQL запрос для удаления пользователя из команды по team_id, user_id.
+	removeUserFromTeam = `DELETE FROM team_users WHERE team_id = $1 AND user_id = $2;`
+	// SQL запрос для удаления команды и приглашений в неё по id.
+	deleteTeamInvites = `DELETE FROM team_invites WHERE team_id = $1;`
+	deleteTeam        = `DELETE FROM tournament_teams WHERE id = $1;`
+	// SQL запрос для удаления сетки соревнования и её матчей по tour_id.
+	deleteBracketMatches = `DELETE FROM bracket_matches WHERE tour_id = $1;`
+	deleteBracket        = `DELETE FROM tournament_brackets WHERE tour_id = $1;`
+	// SQL запрос для удаления приглашения пользователя в соревнование по tour_id, user_id.
+	removeTourInvite = `DELETE FROM tournament_invites WHERE tour_id = $1 AND user_id = $2;`
+	// SQL запрос для удаления заявки на участие в соревновании по tour_id, user_id.
+	removeTourJoinRequest = `DELETE FROM tournament_join_requests WHERE tour_id = $1 AND user_id = $2;`
+	// SQL запрос для удаления одноразовых токенов пользователя по user_id, kind.
+	deleteUserTokens = `DELETE FROM user_tokens WHERE user_id = $1 AND kind = $2;`
+	// SQL запросы для удаления персональных данных удаляемого пользователя по user_id.
+	deleteUserFromTokens       = `DELETE FROM user_tokens WHERE user_id = $1;`
+	deleteUserFromIdentities   = `DELETE FROM user_identities WHERE user_id = $1;`
+	deleteUserFromApiTokens    = `DELETE FROM api_tokens WHERE user_id = $1;`
+	deleteUserFromTourInvites  = `DELETE FROM tournament_invites WHERE user_id = $1;`
+	deleteUserFromJoinRequests = `DELETE FROM tournament_join_requests WHERE user_id = $1;`
+	deleteUserFromTeamInvites  = `DELETE FROM team_invites WHERE user_id = $1;`
+	deleteUserFromFollows      = `DELETE FROM follows WHERE follower_id = $1 OR followee_id = $1;`
+	deleteUserFromTemplates    = `DELETE FROM tournament_templates WHERE owner_id = $1;`
+	deleteUserCollectionRoutes = `DELETE FROM collection_routes WHERE collection_id IN (SELECT id FROM collections WHERE owner_id = $1);`
+	deleteUserFromCollections  = `DELETE FROM collections WHERE owner_id = $1;`
+	deleteUserFromRouteVotes   = `DELETE FROM route_votes WHERE user_id = $1;`
+	hideUserRouteComments      = `UPDATE route_comments SET hidden = true WHERE user_id = $1;`
+	anonymizeUserTourResults   = `UPDATE tournament_results SET user_name = 'deleted user' WHERE user_id = $1;`
+	anonymizeUserTourWinners   = `UPDATE tournament_route_winners SET user_name = 'deleted user' WHERE user_id = $1;`
+	anonymizeUserSeasonResults = `UPDATE season_results SET user_name = 'deleted user' WHERE user_id = $1;`
+	// SQL запрос для отмены подписки по follower_id, followee_id.
+	deleteFollow = `DELETE FROM follows WHERE follower_id = $1 AND followee_id = $2;`
+	// SQL запрос для удаления шаблона соревнования по id, owner_id.
+	deleteTourTemplate = `DELETE FROM tournament_templates WHERE id = $1 AND owner_id = $2;`
+	// SQL запрос для удаления маршрутов подборки по collection_id.
+	deleteCollectionRoutes = `DELETE FROM collection_routes WHERE collection_id = $1;`
+	// SQL запрос для удаления подборки по id, owner_id.
+	deleteCollection = `DELETE FROM collections WHERE id = $1 AND owner_id = $2;`
+	// SQL запрос для удаления маршрута из подборки по collection_id, route_id.
+	deleteRouteFromCollection = `DELETE FROM collection_routes WHERE collection_id = $1 AND route_id = $2;`
+	// SQL запрос для удаления итогов сезона по season_id.
+	deleteSeasonResults = `DELETE FROM season_results WHERE season_id = $1;`
+	// SQL запрос для удаления всей статистики путей.
+	deletePathInsights = `DELETE FROM path_insights;`
+	// SQL запрос для удаления рейтинга мастерства всех игроков.
+	deletePlayerSkills = `DELETE FROM player_skill;`
+	// SQL запрос для отзыва персонального токена по id, user_id.
+	deleteApiToken = `DELETE FROM api_tokens WHERE id = $1 AND user_id = $2;`
+	// SQL запрос для удаления одноразового токена по token_hash.
+	deleteUserToken = `DELETE FROM user_tokens WHERE token_hash = $1;`
+	// SQL запрос для удаления истёкших одноразовых токенов.
+	deleteExpiredUserTokens = `DELETE FROM user_tokens WHERE expires_at <= $1;`
+	// SQL запрос для снятия блокировки пользователя в соревновании по tour_id, user_id.
+	removeTourBan = `DELETE FROM tournament_bans WHERE tour_id = $1 AND user_id = $2;`
 )
 
 // SQL запросы для проверки данных.
 const (
-	// SQL запрос для проверки пароля соревнования по id, pswd.
-	checkTournamentPassword = `SELECT id FROM tournaments WHERE pswd = $1;`
+	// SQL запрос для получения соревнования по хэшу кода-пароля pswd.
+	checkTournamentPassword = `SELECT id FROM tournaments WHERE pswd = $1 AND pswd <> '';`
 	// SQL запрос для проверки создателя соревнования по tour_id, user_id.
 	checkTournamentCreator = `SELECT COUNT(*) FROM tournaments t JOIN tournament_creators tc ON t.id = tc.tour_id WHERE tc.user_id = $2 AND tc.tour_id = $1;`
 	// SQL запрос для проверки участника соревнования по tour_id, user_id.
-	checkTournamentParticipator = `SELECT COUNT(*) FROM tournaments t JOIN tournament_users tc ON t.id = tc.tour_id WHERE tc.user_id = $2 AND tc.tour_id = $1;`
+	checkTournamentParticipator = `SELECT COUNT(*) FROM (
+        SELECT user_id FROM tournament_users WHERE tour_id = $1 AND user_id = $2
+        UNION SELECT user_id FROM team_users WHERE tour_id = $1 AND user_id = $2
+    ) AS participators;`
+	// SQL запрос для проверки архивации соревнования по tour_id.
+	checkTournamentArchived = `SELECT COUNT(*) FROM tournament_archives WHERE tour_id = $1;`
+	// SQL запрос для проверки приглашения в команду по team_id, user_id.
+	checkTeamInvite = `SELECT COUNT(*) FROM team_invites WHERE team_id = $1 AND user_id = $2;`
+	// SQL запрос для проверки приглашения в соревнование по tour_id, user_id.
+	checkTourInvite = `SELECT COUNT(*) FROM tournament_invites WHERE tour_id = $1 AND user_id = $2;`
+	// SQL запрос для проверки блокировки пользователя в соревновании по tour_id, user_id.
+	checkTourBan = `SELECT COUNT(*) FROM tournament_bans WHERE tour_id = $1 AND user_id = $2;`
+	// SQL запрос для проверки заявки на участие в соревновании по tour_id, user_id.
+	checkTourJoinRequest = `SELECT COUNT(*) FROM tournament_join_requests WHERE tour_id = $1 AND user_id = $2;`
 )
 
 // SQL запросы для обновления данных.
 const (
-	// SQL запрос для обновления соревнования по id, start_time, end_time, pswd, private.
-	updateTournament = `UPDATE tournaments SET start_time = $2, end_time = $3, pswd = $4, private = $5 WHERE id = $1;`
+	// SQL запрос для обновления соревнования по id, start_time, end_time, pswd, private, max_users.
+	updateTournament = `UPDATE tournaments SET start_time = $2, end_time = $3, pswd = $4, private = $5, max_users = $6 WHERE id = $1;`
+	// SQL запрос для подтверждения почты пользователя по id, email.
+	verifyUserEmail = `UPDATE users SET verified = true WHERE id = $1 AND email = $2;`
+	// SQL запрос для получения персонального токена с обновлением времени его использования по token_hash, last_used_at.
+	useApiToken = `UPDATE api_tokens SET last_used_at = $2 WHERE token_hash = $1 RETURNING *;`
+	// SQL запрос для обновления времени начала следующего соревнования по шаблону по id, next_start.
+	updateTemplateNextStart = `UPDATE tournament_templates SET next_start = $2 WHERE id = $1;`
+	// SQL запрос для отключения повторения шаблона соревнования по id.
+	disableTemplateRecurrence = `UPDATE tournament_templates SET recurrence = '', next_start = NULL WHERE id = $1;`
+	// SQL запрос для переключения видимости подборки по id, owner_id.
+	toggleCollectionPrivacy = `UPDATE collections SET private = NOT private WHERE id = $1 AND owner_id = $2;`
+	// SQL запрос для обновления позиции маршрута в подборке по collection_id, route_id, position.
+	updateCollectionRoutePosition = `UPDATE collection_routes SET position = $3 WHERE collection_id = $1 AND route_id = $2;`
+	// SQL запрос для скрытия комментария по id.
+	hideRouteComment = `UPDATE route_comments SET hidden = true WHERE id = $1;`
+	// SQL запрос для закрытия нерассмотренных жалоб на комментарий по comment_id, resolved_at, resolution.
+	resolveCommentReports = `UPDATE route_reports SET resolved_at = $2, resolution = $3
+    WHERE comment_id = $1 AND resolved_at IS NULL;`
+	// SQL запрос для закрытия жалобы по id, resolved_at, resolution.
+	resolveRouteReport = `UPDATE route_reports SET resolved_at = $2, resolution = $3 WHERE id = $1 AND resolved_at IS NULL;`
+	// SQL запрос для скрытия или открытия профиля пользователя по id, profile_hidden.
+	updateUserHidden = `UPDATE users SET profile_hidden = $2 WHERE id = $1;`
+	// SQL запрос для пометки почты пользователя подтверждённой по id.
+	markUserVerified = `UPDATE users SET verified = true WHERE id = $1;`
+	// SQL запрос для обновления пароля пользователя с завершением его сессий по id, password.
+	updateUserPassword = `UPDATE users SET password = $2, session_version = session_version + 1 WHERE id = $1;`
+	// SQL запрос для обезличивания удаляемого пользователя по id.
+	anonymizeUser = `UPDATE users SET name = 'deleted user', email = 'deleted-' || id || '@deleted.invalid',
+    password = '', verified = false, session_version = session_version + 1 WHERE id = $1;`
+	// SQL запрос для смены почты пользователя на подтверждённую по id, email.
+	updateUserEmail = `UPDATE users SET email = $2, verified = true WHERE id = $1;`
+	// SQL запрос для пометки ссылки-приглашения использованной по token_hash.
+	useTourInviteLink = `UPDATE tournament_invite_links SET used = true WHERE token_hash = $1;`
 	// SQL запрос для обновления пользователя по id, name, email, password.
 	updateUser = `UPDATE users SET name = $2, email = $3, password = $4 WHERE id = $1;`
+	// SQL запрос для обновления капитана команды по id, captain_id.
+	updateTeamCaptain = `UPDATE tournament_teams SET captain_id = $2 WHERE id = $1;`
+	// SQL запрос для обновления участников, победителя и времени готовности матча сетки по tour_id, num, player1, player2, winner, ready_at.
+	updateBracketMatch = `UPDATE bracket_matches SET player1 = $3, player2 = $4, winner = $5, ready_at = $6 WHERE tour_id = $1 AND num = $2;`
 )
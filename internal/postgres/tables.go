@@ -20,6 +20,34 @@ func overrideDB(db *sql.DB) error {
 // dropTables - функция, удаляющая таблицы WikiSurf в БД.
 func dropTables(db *sql.DB) error {
 	q := strings.Join([]string{
+		dropRouteReports,
+		dropRouteVotes,
+		dropRouteComments,
+		dropCollectionRoutes,
+		dropCollections,
+		dropTourTemplates,
+		dropPathInsights,
+		dropGhostResults,
+		dropFollows,
+		dropSeasonResults,
+		dropSeasons,
+		dropPlayerSkill,
+		dropUserAchievements,
+		dropApiTokens,
+		dropUserIdentities,
+		dropUserTokens,
+		dropTourBans,
+		dropTourJoinRequests,
+		dropTourInviteLinks,
+		dropTourInvites,
+		dropBracketMatches,
+		dropBrackets,
+		dropTeamInvites,
+		dropTeamUsers,
+		dropTeams,
+		dropTourWinners,
+		dropTourResults,
+		dropTourArchives,
 		dropTURelations,
 		dropTCRelations,
 		dropTRRelations,
@@ -47,6 +75,44 @@ func createTables(db *sql.DB) error {
 		createTURelations,
 		createTCRelations,
 		createTRRelations,
+		createTourArchives,
+		createTourResults,
+		createTourWinners,
+		createTeams,
+		createTeamUsers,
+		createTeamInvites,
+		createBrackets,
+		createBracketMatches,
+		createTourInvites,
+		createTourInviteLinks,
+		createTourJoinRequests,
+		createTourBans,
+		createUserTokens,
+		createUserIdentities,
+		createApiTokens,
+		createUserAchievements,
+		createPlayerSkill,
+		createSeasons,
+		createSeasonResults,
+		createFollows,
+		createGhostResults,
+		createPathInsights,
+		createTourTemplates,
+		createCollections,
+		createCollectionRoutes,
+		createRouteComments,
+		createRouteVotes,
+		createRouteReports,
+		alterToursMaxUsers,
+		alterUsersVerified,
+		alterUsersSession,
+		alterUsersHidden,
+		alterSprintsStepTimes,
+		alterSprintsBackSteps,
+		alterBracketMatchesReadyAt,
+		hashTourPasswords,
+		createTourPasswordIndex,
+		createOpenReportIndex,
 	}, " ")
 
 	_, err := db.Exec(q)
@@ -0,0 +1,345 @@
+// Пакет для проведения живых заездов с синхронным стартом.
+package race
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// Status - состояние комнаты заезда.
+type Status int
+
+const (
+	Waiting   Status = iota // Waiting - комната ожидает игроков и старта.
+	Countdown               // Countdown - идёт обратный отсчёт до старта.
+	Running                 // Running - заезд идёт.
+	Finished                // Finished - все игроки финишировали.
+)
+
+var (
+	ErrNoRoom     = errors.New("race room not found")
+	ErrNotHost    = errors.New("user is not the host of the race room")
+	ErrStarted    = errors.New("the race has already started")
+	ErrNotPlayer  = errors.New("user is not a player in the race room")
+	ErrRoomIsFull = errors.New("the race room is full")
+)
+
+// maxPlayers - максимальное количество игроков в комнате.
+const maxPlayers = 32
+
+// startTolerance - допустимое расхождение между началом спринта по часам сервера и стартом заезда.
+const startTolerance = time.Second
+
+// Player - структура, представляющая игрока в комнате заезда.
+type Player struct {
+	UserId     int    // UserId - id пользователя.
+	Name       string // Name - имя пользователя.
+	Done       bool   // Done - флаг, указывающий на то, что игрок завершил заезд.
+	Position   int    // Position - финишная позиция игрока, 0 - если игрок не дошёл до финиша.
+	SprintId   int    // SprintId - id спринта, которым игрок завершил заезд.
+	LengthTime int64  // LengthTime - длительность спринта в ms.
+}
+
+// Room - структура, представляющая снимок состояния комнаты заезда.
+type Room struct {
+	Code      string    // Code - код комнаты, по которому к ней присоединяются.
+	HostId    int       // HostId - id создателя комнаты.
+	RouteId   int       // RouteId - id маршрута заезда.
+	Status    Status    // Status - состояние комнаты.
+	StartAt   time.Time // StartAt - время синхронного старта.
+	UpdatedAt time.Time // UpdatedAt - время последнего изменения комнаты.
+	Players   []Player  // Players - игроки в порядке присоединения.
+}
+
+// HasPlayer - функция, проверяющая, участвует ли пользователь в заезде.
+func (r Room) HasPlayer(userId int) bool {
+	for _, p := range r.Players {
+		if p.UserId == userId {
+			return true
+		}
+	}
+	return false
+}
+
+// room - внутреннее состояние комнаты вместе с подписчиками.
+type room struct {
+	Room
+	finished int
+	subs     map[chan struct{}]struct{}
+}
+
+// Hub - структура, хранящая в памяти процесса все комнаты заездов.
+type Hub struct {
+	mu        sync.Mutex
+	rooms     map[string]*room
+	countdown time.Duration
+	now       func() time.Time
+}
+
+// NewHub - функция, создающая хаб с данной длительностью обратного отсчёта.
+func NewHub(countdown time.Duration) *Hub {
+	return &Hub{
+		rooms:     make(map[string]*room),
+		countdown: countdown,
+		now:       time.Now,
+	}
+}
+
+// Create - функция, создающая комнату для маршрута, в которую сразу входит её создатель.
+func (h *Hub) Create(hostId int, hostName string, routeId int) (Room, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var code string
+	for {
+		c, err := newCode()
+		if err != nil {
+			return Room{}, err
+		}
+		if _, ok := h.rooms[c]; !ok {
+			code = c
+			break
+		}
+	}
+
+	r := &room{
+		Room: Room{
+			Code:      code,
+			HostId:    hostId,
+			RouteId:   routeId,
+			Status:    Waiting,
+			UpdatedAt: time.Now(),
+			Players:   []Player{{UserId: hostId, Name: hostName}},
+		},
+		subs: make(map[chan struct{}]struct{}),
+	}
+	h.rooms[code] = r
+
+	return r.snapshot(), nil
+}
+
+// Get - функция, возвращающая снимок комнаты по коду.
+func (h *Hub) Get(code string) (Room, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	r, ok := h.rooms[code]
+	if !ok {
+		return Room{}, ErrNoRoom
+	}
+
+	return r.snapshot(), nil
+}
+
+// Join - функция, добавляющая игрока в ожидающую старта комнату.
+func (h *Hub) Join(code string, userId int, name string) (Room, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	r, ok := h.rooms[code]
+	if !ok {
+		return Room{}, ErrNoRoom
+	}
+	if r.HasPlayer(userId) {
+		return r.snapshot(), nil
+	}
+	if r.Status != Waiting {
+		return Room{}, ErrStarted
+	}
+	if len(r.Players) >= maxPlayers {
+		return Room{}, ErrRoomIsFull
+	}
+
+	r.Players = append(r.Players, Player{UserId: userId, Name: name})
+	r.notify()
+
+	return r.snapshot(), nil
+}
+
+// Leave - функция, удаляющая игрока из ожидающей старта комнаты.
+// Если комнату покидает её создатель, комната закрывается.
+func (h *Hub) Leave(code string, userId int) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	r, ok := h.rooms[code]
+	if !ok {
+		return ErrNoRoom
+	}
+	if r.Status != Waiting {
+		return ErrStarted
+	}
+
+	if userId == r.HostId {
+		r.Players = nil
+		r.notify()
+		delete(h.rooms, code)
+		return nil
+	}
+
+	for i, p := range r.Players {
+		if p.UserId == userId {
+			r.Players = append(r.Players[:i], r.Players[i+1:]...)
+			r.notify()
+			return nil
+		}
+	}
+
+	return ErrNotPlayer
+}
+
+// Start - функция, запускающая обратный отсчёт в комнате. Запустить его может только создатель комнаты.
+func (h *Hub) Start(code string, userId int) (Room, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	r, ok := h.rooms[code]
+	if !ok {
+		return Room{}, ErrNoRoom
+	}
+	if r.HostId != userId {
+		return Room{}, ErrNotHost
+	}
+	if r.Status != Waiting {
+		return Room{}, ErrStarted
+	}
+
+	r.Status = Countdown
+	r.StartAt = h.now().Add(h.countdown)
+	r.notify()
+
+	time.AfterFunc(h.countdown, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		if r.Status == Countdown {
+			r.Status = Running
+			r.notify()
+		}
+	})
+
+	return r.snapshot(), nil
+}
+
+// Finish - функция, фиксирующая результат спринта пользователя во всех идущих заездах по маршруту спринта.
+// Начало спринта считается по часам сервера как время получения результата минус его длительность,
+// спринты, начатые до старта заезда, не засчитываются.
+//
+// Возвращает коды комнат, в которых был зафиксирован результат.
+func (h *Hub) Finish(userId, routeId, sprintId int, lengthTime int64, success bool) []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	started := h.now().Add(-time.Duration(lengthTime) * time.Millisecond)
+
+	var codes []string
+	for code, r := range h.rooms {
+		if r.Status != Running || r.RouteId != routeId || started.Before(r.StartAt.Add(-startTolerance)) {
+			continue
+		}
+
+		for i := range r.Players {
+			p := &r.Players[i]
+			if p.UserId != userId || p.Done {
+				continue
+			}
+
+			p.Done = true
+			p.SprintId = sprintId
+			p.LengthTime = lengthTime
+			if success {
+				r.finished++
+				p.Position = r.finished
+			}
+
+			if r.allDone() {
+				r.Status = Finished
+			}
+			r.notify()
+			codes = append(codes, code)
+		}
+	}
+
+	return codes
+}
+
+// Subscribe - функция, подписывающая на изменения комнаты.
+//
+// Возвращает канал уведомлений и функцию отмены подписки.
+func (h *Hub) Subscribe(code string) (<-chan struct{}, func(), error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	r, ok := h.rooms[code]
+	if !ok {
+		return nil, nil, ErrNoRoom
+	}
+
+	ch := make(chan struct{}, 1)
+	r.subs[ch] = struct{}{}
+
+	cancel := func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(r.subs, ch)
+	}
+
+	return ch, cancel, nil
+}
+
+// Cleanup - функция, удаляющая комнаты, не изменявшиеся дольше maxAge.
+func (h *Hub) Cleanup(maxAge time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for code, r := range h.rooms {
+		if time.Since(r.UpdatedAt) > maxAge {
+			delete(h.rooms, code)
+		}
+	}
+}
+
+// snapshot - функция, возвращающая копию состояния комнаты.
+func (r *room) snapshot() Room {
+	res := r.Room
+	res.Players = append([]Player(nil), r.Players...)
+	return res
+}
+
+// allDone - функция, проверяющая, что все игроки завершили заезд.
+func (r *room) allDone() bool {
+	for _, p := range r.Players {
+		if !p.Done {
+			return false
+		}
+	}
+	return true
+}
+
+// notify - функция, уведомляющая подписчиков об изменении комнаты.
+func (r *room) notify() {
+	r.UpdatedAt = time.Now()
+	for ch := range r.subs {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// newCode - функция, генерирующая случайный код комнаты.
+func newCode() (string, error) {
+	const charSet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+	code := make([]byte, 6)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(charSet))))
+		if err != nil {
+			return "", err
+		}
+		code[i] = charSet[n.Int64()]
+	}
+	return string(code), nil
+}
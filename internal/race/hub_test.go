@@ -0,0 +1,207 @@
+package race
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// startRace - функция, создающая комнату с игроками 1..players на маршруте 1 и дожидающаяся старта заезда.
+func startRace(t *testing.T, h *Hub, players int) Room {
+	t.Helper()
+
+	room, err := h.Create(1, "host", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for id := 2; id <= players; id++ {
+		if _, err := h.Join(room.Code, id, "player"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ch, cancel, err := h.Subscribe(room.Code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+
+	if _, err := h.Start(room.Code, 1); err != nil {
+		t.Fatal(err)
+	}
+	timeout := time.After(time.Second)
+	for {
+		if room, _ = h.Get(room.Code); room.Status == Running {
+			return room
+		}
+		select {
+		case <-ch:
+		case <-timeout:
+			t.Fatal("the race did not start")
+		}
+	}
+}
+
+func TestRoomLifecycle(t *testing.T) {
+	h := NewHub(time.Hour)
+	room, err := h.Create(1, "host", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		do   func() error
+		err  error
+	}{
+		{name: "join an unknown room", do: func() error { _, err := h.Join("NOPE", 2, "p"); return err }, err: ErrNoRoom},
+		{name: "join", do: func() error { _, err := h.Join(room.Code, 2, "p"); return err }},
+		{name: "join twice", do: func() error { _, err := h.Join(room.Code, 2, "p"); return err }},
+		{name: "leave as a stranger", do: func() error { return h.Leave(room.Code, 3) }, err: ErrNotPlayer},
+		{name: "start as a player", do: func() error { _, err := h.Start(room.Code, 2); return err }, err: ErrNotHost},
+		{name: "start as the host", do: func() error { _, err := h.Start(room.Code, 1); return err }},
+		{name: "start twice", do: func() error { _, err := h.Start(room.Code, 1); return err }, err: ErrStarted},
+		{name: "join after the start", do: func() error { _, err := h.Join(room.Code, 3, "p"); return err }, err: ErrStarted},
+		{name: "leave after the start", do: func() error { return h.Leave(room.Code, 2) }, err: ErrStarted},
+	}
+
+	for _, tt := range tests {
+		if err := tt.do(); !errors.Is(err, tt.err) {
+			t.Fatalf("%s: got %v, want %v", tt.name, err, tt.err)
+		}
+	}
+
+	got, err := h.Get(room.Code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != Countdown || len(got.Players) != 2 || !got.StartAt.After(time.Now()) {
+		t.Fatalf("got %+v, want a counting down room with 2 players", got)
+	}
+}
+
+func TestHostLeaveClosesRoom(t *testing.T) {
+	h := NewHub(time.Hour)
+	room, _ := h.Create(1, "host", 1)
+	h.Join(room.Code, 2, "p")
+
+	if err := h.Leave(room.Code, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.Get(room.Code); !errors.Is(err, ErrNoRoom) {
+		t.Fatalf("got %v, want %v", err, ErrNoRoom)
+	}
+}
+
+func TestFinish(t *testing.T) {
+	// Все результаты приходят на сервер через 10 s после старта заезда.
+	const received = 10 * time.Second
+
+	type finish struct {
+		user    int
+		route   int
+		length  time.Duration // length - длительность спринта.
+		success bool
+		counted bool
+	}
+
+	tests := []struct {
+		name      string
+		players   int
+		finishes  []finish
+		positions map[int]int
+		status    Status
+	}{
+		{
+			name:    "positions follow the finish order",
+			players: 3,
+			finishes: []finish{
+				{user: 2, route: 1, length: 5 * time.Second, success: true, counted: true},
+				{user: 1, route: 1, length: 9 * time.Second, success: true, counted: true},
+			},
+			positions: map[int]int{2: 1, 1: 2, 3: 0},
+			status:    Running,
+		},
+		{
+			name:    "a sprint started before the race is ignored",
+			players: 2,
+			finishes: []finish{
+				{user: 2, route: 1, length: 15 * time.Second, success: true},
+				{user: 1, route: 1, length: 9 * time.Second, success: true, counted: true},
+				{user: 2, route: 1, length: 8 * time.Second, success: true, counted: true},
+			},
+			positions: map[int]int{1: 1, 2: 2},
+			status:    Finished,
+		},
+		{
+			name:    "a start within the tolerance is counted",
+			players: 2,
+			finishes: []finish{
+				{user: 1, route: 1, length: received + startTolerance/2, success: true, counted: true},
+				{user: 2, route: 1, length: received + 2*startTolerance, success: true},
+			},
+			positions: map[int]int{1: 1, 2: 0},
+			status:    Running,
+		},
+		{
+			name:    "other routes are ignored",
+			players: 2,
+			finishes: []finish{
+				{user: 1, route: 2, length: time.Second, success: true},
+			},
+			positions: map[int]int{1: 0, 2: 0},
+			status:    Running,
+		},
+		{
+			name:    "a failed sprint finishes without a position",
+			players: 2,
+			finishes: []finish{
+				{user: 1, route: 1, length: time.Second, counted: true},
+				{user: 2, route: 1, length: time.Second, success: true, counted: true},
+				{user: 1, route: 1, length: time.Second, success: true},
+			},
+			positions: map[int]int{1: 0, 2: 1},
+			status:    Finished,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHub(0)
+			room := startRace(t, h, tt.players)
+			h.now = func() time.Time { return room.StartAt.Add(received) }
+
+			for i, f := range tt.finishes {
+				codes := h.Finish(f.user, f.route, 100+i, f.length.Milliseconds(), f.success)
+				if counted := len(codes) == 1 && codes[0] == room.Code; counted != f.counted {
+					t.Fatalf("finish %d: got rooms %v, counted must be %v", i, codes, f.counted)
+				}
+			}
+
+			got, _ := h.Get(room.Code)
+			for _, p := range got.Players {
+				if p.Position != tt.positions[p.UserId] {
+					t.Errorf("player %d: got position %d, want %d", p.UserId, p.Position, tt.positions[p.UserId])
+				}
+			}
+			if got.Status != tt.status {
+				t.Errorf("got status %v, want %v", got.Status, tt.status)
+			}
+		})
+	}
+}
+
+func TestCleanup(t *testing.T) {
+	h := NewHub(time.Hour)
+	room, _ := h.Create(1, "host", 1)
+
+	h.Cleanup(time.Hour)
+	if _, err := h.Get(room.Code); err != nil {
+		t.Fatalf("a fresh room must be kept: %v", err)
+	}
+
+	h.Cleanup(0)
+	if _, err := h.Get(room.Code); !errors.Is(err, ErrNoRoom) {
+		t.Fatalf("a stale room must be removed: got %v", err)
+	}
+}
@@ -0,0 +1,202 @@
+// Package ratelimit - пакет, реализующий ограничение частоты запросов по алгоритму token bucket
+// и блокировку аккаунтов после неудачных попыток входа.
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit - структура, описывающая ограничение частоты запросов.
+type Limit struct {
+	Rate  float64 // Rate - количество токенов, восстанавливающихся в секунду.
+	Burst int     // Burst - ёмкость корзины токенов.
+}
+
+// PerMinute - функция, возвращающая ограничение в n запросов в минуту с ёмкостью корзины n.
+func PerMinute(n int) Limit {
+	return Limit{Rate: float64(n) / 60, Burst: n}
+}
+
+// Store - интерфейс хранилища корзин токенов.
+type Store interface {
+	// Take - попытка забрать токен из корзины по ключу.
+	// Возвращает разрешение на запрос и время, через которое появится следующий токен.
+	Take(key string, limit Limit) (bool, time.Duration)
+}
+
+// bucket - структура, представляющая корзину токенов.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// MemoryStore - хранилище корзин токенов в памяти процесса.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+// NewMemoryStore - функция, создающая хранилище корзин токенов в памяти.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Take implements Store.
+func (s *MemoryStore) Take(key string, limit Limit) (bool, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		s.buckets[key] = b
+	}
+
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	if limit.Rate <= 0 {
+		return false, time.Duration(math.MaxInt64)
+	}
+	wait := time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second))
+	return false, wait
+}
+
+// Cleanup - удаление корзин, к которым не обращались дольше maxIdle.
+func (s *MemoryStore) Cleanup(maxIdle time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for key, b := range s.buckets {
+		if now.Sub(b.last) > maxIdle {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// Limiter - ограничитель частоты запросов одного вида.
+type Limiter struct {
+	store Store
+	name  string
+	limit Limit
+}
+
+// New - функция, создающая ограничитель с данными хранилищем, именем и ограничением.
+// Имя используется как префикс ключей, чтобы разные ограничители могли разделять хранилище.
+func New(store Store, name string, limit Limit) *Limiter {
+	return &Limiter{store: store, name: name, limit: limit}
+}
+
+// Allow - проверка, разрешён ли запрос по ключу.
+// Возвращает разрешение и время, после которого стоит повторить запрос.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	return l.store.Take(l.name+":"+key, l.limit)
+}
+
+// lockEntry - структура, хранящая неудачные попытки входа в аккаунт.
+type lockEntry struct {
+	failures int
+	until    time.Time
+	last     time.Time
+}
+
+// Lockout - блокировка аккаунтов с экспоненциально растущей задержкой после повторных неудачных попыток входа.
+type Lockout struct {
+	mu        sync.Mutex
+	entries   map[string]*lockEntry
+	threshold int
+	base, max time.Duration
+	now       func() time.Time
+}
+
+// NewLockout - функция, создающая блокировку, срабатывающую после threshold неудач подряд.
+// Первая блокировка длится base, каждая следующая - вдвое дольше, но не более max.
+func NewLockout(threshold int, base, max time.Duration) *Lockout {
+	return &Lockout{
+		entries:   make(map[string]*lockEntry),
+		threshold: threshold,
+		base:      base,
+		max:       max,
+		now:       time.Now,
+	}
+}
+
+// Locked - проверка, заблокирован ли аккаунт по ключу, и оставшееся время блокировки.
+func (l *Lockout) Locked(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e, ok := l.entries[key]
+	if !ok {
+		return false, 0
+	}
+
+	if left := e.until.Sub(l.now()); left > 0 {
+		return true, left
+	}
+	return false, 0
+}
+
+// Fail - фиксация неудачной попытки входа. Возвращает длительность наложенной блокировки или 0.
+func (l *Lockout) Fail(key string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	e, ok := l.entries[key]
+	if !ok {
+		e = &lockEntry{}
+		l.entries[key] = e
+	}
+	e.failures++
+	e.last = now
+
+	if e.failures < l.threshold {
+		return 0
+	}
+
+	d := l.base
+	for i := l.threshold; i < e.failures && d < l.max; i++ {
+		d *= 2
+	}
+	if d > l.max {
+		d = l.max
+	}
+	e.until = now.Add(d)
+
+	return d
+}
+
+// Reset - сброс неудачных попыток входа после успешного входа.
+func (l *Lockout) Reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.entries, key)
+}
+
+// Cleanup - удаление записей без блокировки, не обновлявшихся дольше maxIdle.
+func (l *Lockout) Cleanup(maxIdle time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	for key, e := range l.entries {
+		if now.After(e.until) && now.Sub(e.last) > maxIdle {
+			delete(l.entries, key)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package ratelimit
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// clock - управляемое вручную время для тестов.
+type clock struct {
+	t time.Time
+}
+
+// now - функция, возвращающая текущее время часов.
+func (c *clock) now() time.Time { return c.t }
+
+// advance - функция, сдвигающая часы на d.
+func (c *clock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func TestMemoryStoreTake(t *testing.T) {
+	type step struct {
+		after   time.Duration // after - время, прошедшее перед попыткой.
+		allowed bool
+		wait    time.Duration
+	}
+
+	tests := []struct {
+		name  string
+		limit Limit
+		steps []step
+	}{
+		{
+			name:  "burst is spent then refilled",
+			limit: Limit{Rate: 1, Burst: 2},
+			steps: []step{
+				{allowed: true},
+				{allowed: true},
+				{allowed: false, wait: time.Second},
+				{after: 500 * time.Millisecond, allowed: false, wait: 500 * time.Millisecond},
+				{after: 500 * time.Millisecond, allowed: true},
+				{allowed: false, wait: time.Second},
+			},
+		},
+		{
+			name:  "refill is capped by the burst",
+			limit: Limit{Rate: 1, Burst: 2},
+			steps: []step{
+				{allowed: true},
+				{after: time.Hour, allowed: true},
+				{allowed: true},
+				{allowed: false, wait: time.Second},
+			},
+		},
+		{
+			name:  "per minute",
+			limit: PerMinute(60),
+			steps: []step{
+				{allowed: true},
+				{after: time.Second, allowed: true},
+			},
+		},
+		{
+			name:  "zero rate never refills",
+			limit: Limit{Rate: 0, Burst: 1},
+			steps: []step{
+				{allowed: true},
+				{after: time.Hour, allowed: false, wait: time.Duration(math.MaxInt64)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &clock{t: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+			s := NewMemoryStore()
+			s.now = c.now
+
+			for i, st := range tt.steps {
+				c.advance(st.after)
+				allowed, wait := s.Take("key", tt.limit)
+				if allowed != st.allowed || wait != st.wait {
+					t.Fatalf("step %d: got (%v, %v), want (%v, %v)", i, allowed, wait, st.allowed, st.wait)
+				}
+			}
+		})
+	}
+}
+
+func TestLimiterKeys(t *testing.T) {
+	s := NewMemoryStore()
+	signin := New(s, "signin", Limit{Rate: 0, Burst: 1})
+	signup := New(s, "signup", Limit{Rate: 0, Burst: 1})
+
+	if ok, _ := signin.Allow("1.2.3.4"); !ok {
+		t.Fatal("first sign in must be allowed")
+	}
+	if ok, _ := signin.Allow("1.2.3.4"); ok {
+		t.Fatal("second sign in from the same key must be limited")
+	}
+	if ok, _ := signin.Allow("5.6.7.8"); !ok {
+		t.Fatal("other keys must have their own bucket")
+	}
+	if ok, _ := signup.Allow("1.2.3.4"); !ok {
+		t.Fatal("limiters sharing a store must not share buckets")
+	}
+}
+
+func TestMemoryStoreCleanup(t *testing.T) {
+	c := &clock{t: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	s := NewMemoryStore()
+	s.now = c.now
+
+	s.Take("old", PerMinute(1))
+	c.advance(2 * time.Hour)
+	s.Take("new", PerMinute(1))
+	s.Cleanup(time.Hour)
+
+	if _, ok := s.buckets["old"]; ok {
+		t.Error("idle bucket must be removed")
+	}
+	if _, ok := s.buckets["new"]; !ok {
+		t.Error("recent bucket must be kept")
+	}
+}
+
+func TestLockoutBackoff(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold int
+		base, max time.Duration
+		fails     int
+		want      []time.Duration // want - блокировка после каждой неудачи.
+	}{
+		{
+			name:      "no lock below the threshold",
+			threshold: 3, base: time.Minute, max: time.Hour,
+			fails: 2,
+			want:  []time.Duration{0, 0},
+		},
+		{
+			name:      "doubles after the threshold",
+			threshold: 3, base: time.Minute, max: time.Hour,
+			fails: 6,
+			want:  []time.Duration{0, 0, time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute},
+		},
+		{
+			name:      "capped by max",
+			threshold: 1, base: 20 * time.Minute, max: time.Hour,
+			fails: 4,
+			want:  []time.Duration{20 * time.Minute, 40 * time.Minute, time.Hour, time.Hour},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &clock{t: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+			l := NewLockout(tt.threshold, tt.base, tt.max)
+			l.now = c.now
+
+			for i := 0; i < tt.fails; i++ {
+				got := l.Fail("user@example.com")
+				if got != tt.want[i] {
+					t.Fatalf("failure %d: got %v, want %v", i+1, got, tt.want[i])
+				}
+
+				locked, left := l.Locked("user@example.com")
+				if locked != (got > 0) || left != got {
+					t.Fatalf("failure %d: Locked() = (%v, %v), want (%v, %v)", i+1, locked, left, got > 0, got)
+				}
+			}
+		})
+	}
+}
+
+func TestLockoutExpiresAndResets(t *testing.T) {
+	c := &clock{t: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	l := NewLockout(1, time.Minute, time.Hour)
+	l.now = c.now
+
+	l.Fail("key")
+	c.advance(30 * time.Second)
+	if locked, left := l.Locked("key"); !locked || left != 30*time.Second {
+		t.Fatalf("Locked() = (%v, %v), want (true, 30s)", locked, left)
+	}
+
+	c.advance(30 * time.Second)
+	if locked, _ := l.Locked("key"); locked {
+		t.Fatal("the lock must expire")
+	}
+	if d := l.Fail("key"); d != 2*time.Minute {
+		t.Fatalf("failures must be remembered after the lock expires: got %v, want 2m", d)
+	}
+
+	l.Reset("key")
+	if locked, _ := l.Locked("key"); locked {
+		t.Fatal("Reset must unlock the key")
+	}
+	if d := l.Fail("key"); d != time.Minute {
+		t.Fatalf("Reset must forget failures: got %v, want 1m", d)
+	}
+
+	c.advance(2 * time.Hour)
+	l.Cleanup(time.Hour)
+	if _, ok := l.entries["key"]; ok {
+		t.Error("idle unlocked entry must be removed")
+	}
+}
@@ -0,0 +1,152 @@
+// Package skill - пакет, рассчитывающий рейтинг мастерства игроков по многопользовательскому Эло.
+//
+// Каждый новый личный рекорд игрока на маршруте считается матчем против лучших результатов
+// остальных игроков на этом маршруте: более быстрый результат побеждает, равный - ничья.
+package skill
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/famusovsky/WikiSurfBack/internal/models"
+)
+
+const (
+	Initial  = 1500.0              // Initial - рейтинг нового игрока.
+	K        = 32.0                // K - максимальное изменение рейтинга за матч.
+	Grace    = 30 * 24 * time.Hour // Grace - срок неактивности, после которого рейтинг начинает снижаться.
+	HalfLife = 90 * 24 * time.Hour // HalfLife - срок, за который отрыв от начального рейтинга уменьшается вдвое.
+)
+
+// New - функция, возвращающая рейтинг нового игрока.
+func New(userId int) models.PlayerSkill {
+	return models.PlayerSkill{UserId: userId, Rating: Initial}
+}
+
+// expected - функция, возвращающая ожидаемый результат игрока с рейтингом a против игрока с рейтингом b.
+func expected(a, b float64) float64 {
+	return 1 / (1 + math.Pow(10, (b-a)/400))
+}
+
+// score - функция, возвращающая результат матча по длительностям спринтов.
+func score(a, b int64) float64 {
+	switch {
+	case a < b:
+		return 1
+	case a == b:
+		return 0.5
+	default:
+		return 0
+	}
+}
+
+// Play - функция, проводящая матч игрока с результатом length против лучших результатов соперников.
+// Изменение рейтинга игрока делится между соперниками, каждый соперник получает обратное изменение.
+//
+// Принимает: id игрока, длительность его спринта, лучшие длительности соперников по их id,
+// текущие рейтинги (отсутствующие считаются начальными), время матча.
+//
+// Возвращает: новые рейтинги участников матча.
+func Play(player int, length int64, opponents map[int]int64, ratings map[int]models.PlayerSkill, at time.Time) []models.PlayerSkill {
+	get := func(id int) models.PlayerSkill {
+		if r, ok := ratings[id]; ok {
+			return r
+		}
+		return New(id)
+	}
+
+	p := get(player)
+	p.Games++
+	p.LastActive = at
+	if len(opponents) == 0 {
+		return []models.PlayerSkill{p}
+	}
+
+	ids := make([]int, 0, len(opponents))
+	for id := range opponents {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	k := K / float64(len(ids))
+	res := make([]models.PlayerSkill, 0, len(ids)+1)
+	delta := 0.0
+	for _, id := range ids {
+		o := get(id)
+		d := k * (score(length, opponents[id]) - expected(p.Rating, o.Rating))
+		delta += d
+		o.Rating -= d
+		res = append(res, o)
+	}
+	p.Rating += delta
+
+	return append(res, p)
+}
+
+// Replay - функция, рассчитывающая рейтинги по всей истории успешных спринтов.
+// Спринты обрабатываются по времени старта, затем по id, поэтому результат детерминирован.
+func Replay(sprints []models.Sprint) map[int]models.PlayerSkill {
+	sort.SliceStable(sprints, func(i, j int) bool {
+		if !sprints[i].StartTime.Equal(sprints[j].StartTime) {
+			return sprints[i].StartTime.Before(sprints[j].StartTime)
+		}
+		return sprints[i].Id < sprints[j].Id
+	})
+
+	ratings := map[int]models.PlayerSkill{}
+	bests := map[int]map[int]int64{}
+	for _, s := range sprints {
+		if !s.Success {
+			continue
+		}
+
+		route := bests[s.RouteId]
+		if route == nil {
+			route = map[int]int64{}
+			bests[s.RouteId] = route
+		}
+		if best, ok := route[s.UserId]; ok && best <= s.LengthTime {
+			continue
+		}
+
+		opponents := make(map[int]int64, len(route))
+		for id, length := range route {
+			if id != s.UserId {
+				opponents[id] = length
+			}
+		}
+		route[s.UserId] = s.LengthTime
+
+		for _, r := range Play(s.UserId, s.LengthTime, opponents, ratings, s.StartTime) {
+			ratings[r.UserId] = r
+		}
+	}
+
+	return ratings
+}
+
+// Decayed - функция, возвращающая рейтинг с учётом неактивности игрока на момент now.
+// После срока Grace отрыв от начального рейтинга уменьшается вдвое за каждый HalfLife.
+func Decayed(r models.PlayerSkill, now time.Time) float64 {
+	idle := now.Sub(r.LastActive) - Grace
+	if idle <= 0 {
+		return r.Rating
+	}
+
+	return Initial + (r.Rating-Initial)*math.Pow(0.5, float64(idle)/float64(HalfLife))
+}
+
+// Ladder - функция, сортирующая игроков по рейтингу с учётом неактивности на момент now.
+func Ladder(skills []models.PlayerSkill, now time.Time) []models.PlayerSkill {
+	res := make([]models.PlayerSkill, len(skills))
+	for i, s := range skills {
+		s.Rating = Decayed(s, now)
+		res[i] = s
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Rating > res[j].Rating
+	})
+
+	return res
+}
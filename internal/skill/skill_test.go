@@ -0,0 +1,268 @@
+package skill
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/famusovsky/WikiSurfBack/internal/models"
+)
+
+// near - функция, сравнивающая рейтинги с точностью до погрешности вычислений.
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+// byId - функция, раскладывающая рейтинги по id игроков.
+func byId(skills []models.PlayerSkill) map[int]models.PlayerSkill {
+	res := make(map[int]models.PlayerSkill, len(skills))
+	for _, s := range skills {
+		res[s.UserId] = s
+	}
+	return res
+}
+
+func TestPlay(t *testing.T) {
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		length    int64
+		opponents map[int]int64
+		ratings   map[int]models.PlayerSkill
+		want      map[int]float64
+	}{
+		{
+			name:   "first result on the route",
+			length: 1000,
+			want:   map[int]float64{1: Initial},
+		},
+		{
+			name:      "win against an equal opponent",
+			length:    1000,
+			opponents: map[int]int64{2: 2000},
+			want:      map[int]float64{1: Initial + K/2, 2: Initial - K/2},
+		},
+		{
+			name:      "loss against an equal opponent",
+			length:    3000,
+			opponents: map[int]int64{2: 2000},
+			want:      map[int]float64{1: Initial - K/2, 2: Initial + K/2},
+		},
+		{
+			name:      "draw against an equal opponent",
+			length:    2000,
+			opponents: map[int]int64{2: 2000},
+			want:      map[int]float64{1: Initial, 2: Initial},
+		},
+		{
+			name:      "k is shared between opponents",
+			length:    1500,
+			opponents: map[int]int64{2: 1000, 3: 2000},
+			want:      map[int]float64{1: Initial, 2: Initial + K/4, 3: Initial - K/4},
+		},
+		{
+			name:      "a stronger opponent loses more",
+			length:    1000,
+			opponents: map[int]int64{2: 2000},
+			ratings:   map[int]models.PlayerSkill{2: {UserId: 2, Rating: Initial + 400}},
+			want:      map[int]float64{1: Initial + K*10/11, 2: Initial + 400 - K*10/11},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := byId(Play(1, tt.length, tt.opponents, tt.ratings, at))
+			if len(res) != len(tt.want) {
+				t.Fatalf("got %d ratings, want %d", len(res), len(tt.want))
+			}
+
+			before, after := 0.0, 0.0
+			for id, want := range tt.want {
+				got, ok := res[id]
+				if !ok {
+					t.Fatalf("no rating for player %d", id)
+				}
+				if !near(got.Rating, want) {
+					t.Errorf("player %d: got %v, want %v", id, got.Rating, want)
+				}
+				after += got.Rating
+				if r, ok := tt.ratings[id]; ok {
+					before += r.Rating
+				} else {
+					before += Initial
+				}
+			}
+			if !near(before, after) {
+				t.Errorf("the match must be zero-sum: %v before, %v after", before, after)
+			}
+
+			if p := res[1]; p.Games != 1 || !p.LastActive.Equal(at) {
+				t.Errorf("player: got %d games active at %v, want 1 game active at %v", p.Games, p.LastActive, at)
+			}
+		})
+	}
+}
+
+func TestReplay(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sprint := func(id, user, route int, length int64, success bool) models.Sprint {
+		return models.Sprint{
+			Id:         id,
+			UserId:     user,
+			RouteId:    route,
+			LengthTime: length,
+			Success:    success,
+			StartTime:  start.Add(time.Duration(id) * time.Minute),
+		}
+	}
+
+	tests := []struct {
+		name    string
+		sprints []models.Sprint
+		want    map[int]float64
+		games   map[int]int
+	}{
+		{
+			name: "a faster result wins and a slower one counts nothing",
+			sprints: []models.Sprint{
+				sprint(1, 1, 1, 2000, true),
+				sprint(2, 2, 1, 1000, true),
+				sprint(3, 2, 1, 1500, true),
+				sprint(4, 1, 1, 500, false),
+			},
+			want:  map[int]float64{1: Initial - K/2, 2: Initial + K/2},
+			games: map[int]int{1: 1, 2: 1},
+		},
+		{
+			name: "routes are played separately",
+			sprints: []models.Sprint{
+				sprint(1, 1, 1, 1000, true),
+				sprint(2, 2, 2, 500, true),
+			},
+			want:  map[int]float64{1: Initial, 2: Initial},
+			games: map[int]int{1: 1, 2: 1},
+		},
+		{
+			name: "a new personal best is a new match",
+			sprints: []models.Sprint{
+				sprint(1, 1, 1, 1000, true),
+				sprint(2, 2, 1, 2000, true),
+				sprint(3, 2, 1, 1000, true),
+			},
+			games: map[int]int{1: 1, 2: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Replay(append([]models.Sprint(nil), tt.sprints...))
+
+			for id, want := range tt.want {
+				if !near(got[id].Rating, want) {
+					t.Errorf("player %d: got %v, want %v", id, got[id].Rating, want)
+				}
+			}
+			for id, games := range tt.games {
+				if got[id].Games != games {
+					t.Errorf("player %d: got %d games, want %d", id, got[id].Games, games)
+				}
+			}
+
+			// Порядок входных спринтов не влияет на результат.
+			reversed := make([]models.Sprint, len(tt.sprints))
+			for i, s := range tt.sprints {
+				reversed[len(tt.sprints)-1-i] = s
+			}
+			again := Replay(reversed)
+			for id, r := range got {
+				if !near(again[id].Rating, r.Rating) || again[id].Games != r.Games {
+					t.Errorf("player %d: replay of reversed history differs: %+v, want %+v", id, again[id], r)
+				}
+			}
+		})
+	}
+}
+
+func TestReplayMatchesPlay(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sprints := []models.Sprint{
+		{Id: 1, UserId: 1, RouteId: 1, LengthTime: 3000, Success: true, StartTime: start},
+		{Id: 2, UserId: 2, RouteId: 1, LengthTime: 2000, Success: true, StartTime: start.Add(time.Minute)},
+		{Id: 3, UserId: 3, RouteId: 1, LengthTime: 2500, Success: true, StartTime: start.Add(2 * time.Minute)},
+		{Id: 4, UserId: 1, RouteId: 1, LengthTime: 1000, Success: true, StartTime: start.Add(3 * time.Minute)},
+	}
+
+	// Пошаговое проведение матчей, как при сохранении спринтов.
+	ratings := map[int]models.PlayerSkill{}
+	bests := map[int]int64{}
+	for _, s := range sprints {
+		opponents := map[int]int64{}
+		for id, l := range bests {
+			if id != s.UserId {
+				opponents[id] = l
+			}
+		}
+		bests[s.UserId] = s.LengthTime
+		for _, r := range Play(s.UserId, s.LengthTime, opponents, ratings, s.StartTime) {
+			ratings[r.UserId] = r
+		}
+	}
+
+	replayed := Replay(sprints)
+	for id, r := range ratings {
+		if !near(replayed[id].Rating, r.Rating) || replayed[id].Games != r.Games || !replayed[id].LastActive.Equal(r.LastActive) {
+			t.Errorf("player %d: replay %+v, step by step %+v", id, replayed[id], r)
+		}
+	}
+}
+
+func TestDecayed(t *testing.T) {
+	active := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		rating float64
+		idle   time.Duration
+		want   float64
+	}{
+		{name: "active player", rating: 1700, idle: 0, want: 1700},
+		{name: "within the grace period", rating: 1700, idle: Grace, want: 1700},
+		{name: "one half-life after the grace period", rating: 1700, idle: Grace + HalfLife, want: 1600},
+		{name: "two half-lives after the grace period", rating: 1700, idle: Grace + 2*HalfLife, want: 1550},
+		{name: "low ratings decay up", rating: 1300, idle: Grace + HalfLife, want: 1400},
+		{name: "initial rating does not change", rating: Initial, idle: Grace + HalfLife, want: Initial},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := models.PlayerSkill{Rating: tt.rating, LastActive: active}
+			if got := Decayed(r, active.Add(tt.idle)); !near(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLadder(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	skills := []models.PlayerSkill{
+		{UserId: 1, Rating: 1800, LastActive: now.Add(-Grace - 2*HalfLife)},
+		{UserId: 2, Rating: 1600, LastActive: now},
+		{UserId: 3, Rating: 1700, LastActive: now},
+	}
+
+	got := Ladder(skills, now)
+	wantOrder := []int{3, 2, 1}
+	for i, id := range wantOrder {
+		if got[i].UserId != id {
+			t.Fatalf("place %d: got player %d, want %d", i+1, got[i].UserId, id)
+		}
+	}
+	if !near(got[2].Rating, 1575) {
+		t.Errorf("decayed rating: got %v, want 1575", got[2].Rating)
+	}
+	if skills[0].Rating != 1800 {
+		t.Error("Ladder must not change the input")
+	}
+}
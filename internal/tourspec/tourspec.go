@@ -0,0 +1,354 @@
+// Package tourspec - пакет, описывающий файл настройки соревнования для импорта и экспорта.
+//
+// Поддерживаются форматы JSON, YAML и CSV. В CSV каждая строка начинается с названия поля:
+//
+//	start_time,2026-11-01T10:00:00Z
+//	end_time,2026-11-08T10:00:00Z
+//	private,true
+//	max_users,30
+//	creator,coauthor@example.com
+//	route,https://en.wikipedia.org/wiki/Start,https://en.wikipedia.org/wiki/Finish
+package tourspec
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/famusovsky/WikiSurfBack/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// MaxRoutes - максимальное количество маршрутов в одном файле.
+const MaxRoutes = 200
+
+// ErrUnknownCreator - ошибка, возвращаемая, если почта соавтора не принадлежит ни одному пользователю.
+var ErrUnknownCreator = errors.New("co-creator is not registered")
+
+// Route - структура, описывающая маршрут соревнования.
+type Route struct {
+	Start  string `json:"start" yaml:"start"`   // Start - ссылка на стартовую статью.
+	Finish string `json:"finish" yaml:"finish"` // Finish - ссылка на финишную статью.
+}
+
+// Spec - структура, описывающая настройку соревнования.
+type Spec struct {
+	StartTime time.Time `json:"start_time" yaml:"start_time"` // StartTime - время начала соревнования.
+	EndTime   time.Time `json:"end_time" yaml:"end_time"`     // EndTime - время конца соревнования.
+	Private   bool      `json:"private" yaml:"private"`       // Private - флаг закрытости соревнования.
+	MaxUsers  int       `json:"max_users" yaml:"max_users"`   // MaxUsers - максимальное количество участников, 0 - без ограничения.
+	Creators  []string  `json:"creators" yaml:"creators"`     // Creators - почты соавторов соревнования.
+	Routes    []Route   `json:"routes" yaml:"routes"`         // Routes - маршруты соревнования.
+}
+
+// wikiLink - шаблон ссылки на статью Википедии.
+var wikiLink = regexp.MustCompile(`.*wikipedia\.org\/wiki\/[^\s"]+`)
+
+// CheckRoute - функция, проверяющая, что маршрут задан двумя разными ссылками на статьи Википедии.
+func CheckRoute(start, finish string) error {
+	if start == "" || finish == "" {
+		return errors.New("empty input")
+	}
+	if start == finish {
+		return errors.New("start and finish must be different")
+	}
+	if !(wikiLink.MatchString(start) && wikiLink.MatchString(finish)) {
+		return errors.New("input must be a wikipedia article link")
+	}
+
+	return nil
+}
+
+// Validate - функция, проверяющая настройку соревнования.
+func (s Spec) Validate() error {
+	if s.StartTime.IsZero() || s.EndTime.IsZero() {
+		return errors.New("start_time and end_time are required")
+	}
+	if !s.EndTime.After(s.StartTime) {
+		return errors.New("the tournament must end after it starts")
+	}
+	if s.MaxUsers < 0 {
+		return errors.New("max_users must be a non-negative number")
+	}
+	if len(s.Routes) == 0 {
+		return errors.New("the tournament needs at least one route")
+	}
+	if len(s.Routes) > MaxRoutes {
+		return fmt.Errorf("the tournament can have at most %d routes", MaxRoutes)
+	}
+	for i, r := range s.Routes {
+		if err := CheckRoute(r.Start, r.Finish); err != nil {
+			return fmt.Errorf("route #%d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// FormatOf - функция, определяющая формат файла по расширению или типу содержимого.
+func FormatOf(name, contentType string) (string, error) {
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(name)), ".")
+	switch {
+	case ext == "json" || strings.Contains(contentType, "json"):
+		return "json", nil
+	case ext == "csv" || strings.Contains(contentType, "csv"):
+		return "csv", nil
+	case ext == "yaml" || ext == "yml" || strings.Contains(contentType, "yaml"):
+		return "yaml", nil
+	default:
+		return "", errors.New("unknown file format, use JSON, YAML or CSV")
+	}
+}
+
+// Parse - функция, читающая настройку соревнования в формате json, yaml или csv.
+func Parse(data []byte, format string) (Spec, error) {
+	var (
+		spec Spec
+		err  error
+	)
+
+	switch format {
+	case "json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&spec)
+	case "yaml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(&spec)
+	case "csv":
+		spec, err = parseCsv(data)
+	default:
+		err = fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil {
+		return Spec{}, err
+	}
+
+	return spec, spec.Validate()
+}
+
+// parseCsv - функция, читающая настройку соревнования в формате CSV.
+func parseCsv(data []byte) (Spec, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	r.Comment = '#'
+
+	var spec Spec
+	for line := 1; ; line++ {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return spec, nil
+		}
+		if err != nil {
+			return Spec{}, err
+		}
+
+		want := 2
+		if rec[0] == "route" {
+			want = 3
+		}
+		if len(rec) != want {
+			return Spec{}, fmt.Errorf("line %d: %q needs %d values", line, rec[0], want-1)
+		}
+
+		switch rec[0] {
+		case "start_time":
+			spec.StartTime, err = time.Parse(time.RFC3339, rec[1])
+		case "end_time":
+			spec.EndTime, err = time.Parse(time.RFC3339, rec[1])
+		case "private":
+			spec.Private, err = strconv.ParseBool(rec[1])
+		case "max_users":
+			spec.MaxUsers, err = strconv.Atoi(rec[1])
+		case "creator":
+			spec.Creators = append(spec.Creators, rec[1])
+		case "route":
+			spec.Routes = append(spec.Routes, Route{Start: rec[1], Finish: rec[2]})
+		default:
+			err = fmt.Errorf("unknown field %q", rec[0])
+		}
+		if err != nil {
+			return Spec{}, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+}
+
+// Encode - функция, записывающая настройку соревнования в формате json, yaml или csv.
+func Encode(w io.Writer, spec Spec, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(spec)
+	case "yaml":
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(spec); err != nil {
+			return err
+		}
+		return enc.Close()
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"start_time", spec.StartTime.Format(time.RFC3339)})
+		cw.Write([]string{"end_time", spec.EndTime.Format(time.RFC3339)})
+		cw.Write([]string{"private", strconv.FormatBool(spec.Private)})
+		cw.Write([]string{"max_users", strconv.Itoa(spec.MaxUsers)})
+		for _, c := range spec.Creators {
+			cw.Write([]string{"creator", c})
+		}
+		for _, r := range spec.Routes {
+			cw.Write([]string{"route", r.Start, r.Finish})
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// Store - интерфейс хранилища, необходимого для импорта и экспорта соревнований.
+type Store interface {
+	GetUser(email string) (models.User, error)                                                               // GetUser - получение пользователя по почте.
+	GetTournament(id int) (models.Tournament, error)                                                         // GetTournament - получение соревнования.
+	GetTournamentRoutes(id int) ([]models.Route, error)                                                      // GetTournamentRoutes - получение маршрутов соревнования.
+	GetTournamentCreators(id int) ([]models.User, error)                                                     // GetTournamentCreators - получение создателей соревнования.
+	ImportTournament(tour models.Tournament, routes []models.Route, creators []int, userId int) (int, error) // ImportTournament - атомарное создание соревнования.
+}
+
+// Import - функция, создающая соревнование по настройке от имени пользователя.
+// Существующие маршруты переиспользуются, недостающие создаются, всё соревнование создаётся в одной транзакции.
+//
+// Возвращает: id созданного соревнования.
+func Import(store Store, spec Spec, userId int) (int, error) {
+	if err := spec.Validate(); err != nil {
+		return 0, err
+	}
+
+	creators := make([]int, 0, len(spec.Creators))
+	for _, email := range spec.Creators {
+		user, err := store.GetUser(email)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %q", ErrUnknownCreator, email)
+		}
+		creators = append(creators, user.Id)
+	}
+
+	routes := make([]models.Route, len(spec.Routes))
+	for i, r := range spec.Routes {
+		routes[i] = models.Route{Start: r.Start, Finish: r.Finish, CreatorId: userId}
+	}
+
+	return store.ImportTournament(models.Tournament{
+		StartTime: spec.StartTime,
+		EndTime:   spec.EndTime,
+		Private:   spec.Private,
+		MaxUsers:  spec.MaxUsers,
+	}, routes, creators, userId)
+}
+
+// Export - функция, возвращающая настройку существующего соревнования, по которой его можно воссоздать.
+func Export(store Store, tourId int) (Spec, error) {
+	tour, err := store.GetTournament(tourId)
+	if err != nil {
+		return Spec{}, err
+	}
+	routes, err := store.GetTournamentRoutes(tourId)
+	if err != nil {
+		return Spec{}, err
+	}
+	creators, err := store.GetTournamentCreators(tourId)
+	if err != nil {
+		return Spec{}, err
+	}
+
+	spec := Spec{
+		StartTime: tour.StartTime,
+		EndTime:   tour.EndTime,
+		Private:   tour.Private,
+		MaxUsers:  tour.MaxUsers,
+		Creators:  make([]string, len(creators)),
+		Routes:    make([]Route, len(routes)),
+	}
+	for i, c := range creators {
+		spec.Creators[i] = c.Email
+	}
+	for i, r := range routes {
+		spec.Routes[i] = Route{Start: r.Start, Finish: r.Finish}
+	}
+
+	return spec, nil
+}
+
+// Правила повторения шаблонов соревнований.
+const (
+	Once    = ""        // Once - соревнование создаётся по шаблону только вручную.
+	Weekly  = "weekly"  // Weekly - соревнование создаётся каждую неделю.
+	Monthly = "monthly" // Monthly - соревнование создаётся каждый месяц.
+)
+
+// CheckRecurrence - функция, проверяющая правило повторения.
+func CheckRecurrence(recurrence string) error {
+	switch recurrence {
+	case Once, Weekly, Monthly:
+		return nil
+	default:
+		return fmt.Errorf("unknown recurrence %q, use weekly or monthly", recurrence)
+	}
+}
+
+// Occurrence - функция, возвращающая время начала n-го по правилу повторения соревнования.
+// Каждый запуск считается от исходного времени anchor, поэтому ежемесячные соревнования не смещаются:
+// если в месяце нет нужного дня, берётся последний день месяца.
+func Occurrence(anchor time.Time, recurrence string, n int) time.Time {
+	switch recurrence {
+	case Weekly:
+		return anchor.AddDate(0, 0, 7*n)
+	case Monthly:
+		first := time.Date(anchor.Year(), anchor.Month()+time.Month(n), 1,
+			anchor.Hour(), anchor.Minute(), anchor.Second(), anchor.Nanosecond(), anchor.Location())
+		day := min(anchor.Day(), first.AddDate(0, 1, -1).Day())
+		return first.AddDate(0, 0, day-1)
+	default:
+		return anchor
+	}
+}
+
+// Next - функция, возвращающая время начала первого по правилу повторения соревнования позже after.
+// Для соревнований без повторения возвращает anchor.
+func Next(anchor time.Time, recurrence string, after time.Time) time.Time {
+	if recurrence == Once {
+		return anchor
+	}
+
+	n := 0
+	if recurrence == Weekly && after.After(anchor) {
+		n = int(after.Sub(anchor) / (7 * 24 * time.Hour))
+	}
+	if recurrence == Monthly && after.After(anchor) {
+		n = (after.Year()-anchor.Year())*12 + int(after.Month()-anchor.Month()) - 1
+	}
+	for {
+		if t := Occurrence(anchor, recurrence, n); t.After(after) {
+			return t
+		}
+		n++
+	}
+}
+
+// Shift - функция, переносящая соревнование на новое время начала с сохранением длительности.
+func (s Spec) Shift(start time.Time) Spec {
+	s.EndTime = start.Add(s.EndTime.Sub(s.StartTime))
+	s.StartTime = start
+
+	return s
+}
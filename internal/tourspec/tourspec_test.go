@@ -0,0 +1,261 @@
+package tourspec
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// validSpec - функция, возвращающая корректную настройку соревнования.
+func validSpec() Spec {
+	return Spec{
+		StartTime: time.Date(2026, 11, 1, 10, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2026, 11, 8, 10, 0, 0, 0, time.UTC),
+		Private:   true,
+		MaxUsers:  30,
+		Creators:  []string{"coauthor@example.com"},
+		Routes: []Route{
+			{Start: "https://en.wikipedia.org/wiki/Start", Finish: "https://en.wikipedia.org/wiki/Finish"},
+			{Start: "https://en.wikipedia.org/wiki/Go", Finish: "https://en.wikipedia.org/wiki/Rust"},
+		},
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, format := range []string{"json", "yaml", "csv"} {
+		t.Run(format, func(t *testing.T) {
+			want := validSpec()
+
+			var buf bytes.Buffer
+			if err := Encode(&buf, want, format); err != nil {
+				t.Fatal(err)
+			}
+			got, err := Parse(buf.Bytes(), format)
+			if err != nil {
+				t.Fatalf("%v\n%s", err, buf.String())
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("got %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		data   string
+		err    string
+	}{
+		{
+			name:   "csv with comments and spaces",
+			format: "csv",
+			data: "# weekly cup\nstart_time, 2026-11-01T10:00:00Z\nend_time, 2026-11-08T10:00:00Z\n" +
+				"route, https://en.wikipedia.org/wiki/A, https://en.wikipedia.org/wiki/B\n",
+		},
+		{
+			name:   "csv unknown field",
+			format: "csv",
+			data:   "title,Cup\n",
+			err:    `line 1: unknown field "title"`,
+		},
+		{
+			name:   "csv route without finish",
+			format: "csv",
+			data:   "route,https://en.wikipedia.org/wiki/A\n",
+			err:    `line 1: "route" needs 2 values`,
+		},
+		{
+			name:   "csv bad time",
+			format: "csv",
+			data:   "start_time,tomorrow\n",
+			err:    "line 1:",
+		},
+		{
+			name:   "json unknown field",
+			format: "json",
+			data:   `{"title": "Cup"}`,
+			err:    "unknown field",
+		},
+		{
+			name:   "yaml unknown field",
+			format: "yaml",
+			data:   "title: Cup\n",
+			err:    "not found",
+		},
+		{
+			name:   "unknown format",
+			format: "xml",
+			err:    `unknown format "xml"`,
+		},
+		{
+			name:   "parsed spec is validated",
+			format: "json",
+			data:   `{"start_time": "2026-11-01T10:00:00Z", "end_time": "2026-11-08T10:00:00Z"}`,
+			err:    "at least one route",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data), tt.format)
+			if tt.err == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("got error %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(*Spec)
+		err    string
+	}{
+		{name: "valid", change: func(*Spec) {}},
+		{name: "no start time", change: func(s *Spec) { s.StartTime = time.Time{} }, err: "are required"},
+		{name: "ends before it starts", change: func(s *Spec) { s.EndTime = s.StartTime }, err: "must end after it starts"},
+		{name: "negative max users", change: func(s *Spec) { s.MaxUsers = -1 }, err: "non-negative"},
+		{name: "no routes", change: func(s *Spec) { s.Routes = nil }, err: "at least one route"},
+		{name: "too many routes", change: func(s *Spec) { s.Routes = make([]Route, MaxRoutes+1) }, err: "at most"},
+		{name: "same start and finish", change: func(s *Spec) { s.Routes[1].Finish = s.Routes[1].Start }, err: "route #2: start and finish must be different"},
+		{name: "not a wikipedia link", change: func(s *Spec) { s.Routes[0].Start = "https://example.com" }, err: "route #1: input must be a wikipedia article link"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := validSpec()
+			tt.change(&s)
+			err := s.Validate()
+			if tt.err == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("got error %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestFormatOf(t *testing.T) {
+	tests := []struct {
+		name        string
+		file        string
+		contentType string
+		want        string
+	}{
+		{name: "json extension", file: "cup.JSON", want: "json"},
+		{name: "csv extension", file: "cup.csv", want: "csv"},
+		{name: "yaml extension", file: "cup.yaml", want: "yaml"},
+		{name: "yml extension", file: "cup.yml", want: "yaml"},
+		{name: "content type", file: "cup", contentType: "application/yaml", want: "yaml"},
+		{name: "unknown", file: "cup.txt", contentType: "text/plain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FormatOf(tt.file, tt.contentType)
+			if got != tt.want || (err != nil) != (tt.want == "") {
+				t.Fatalf("got (%q, %v), want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOccurrence(t *testing.T) {
+	anchor := time.Date(2026, 1, 31, 18, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		recurrence string
+		n          int
+		want       time.Time
+	}{
+		{name: "once", recurrence: Once, n: 3, want: anchor},
+		{name: "weekly", recurrence: Weekly, n: 2, want: time.Date(2026, 2, 14, 18, 30, 0, 0, time.UTC)},
+		{name: "monthly in a short month", recurrence: Monthly, n: 1, want: time.Date(2026, 2, 28, 18, 30, 0, 0, time.UTC)},
+		{name: "monthly does not drift after a short month", recurrence: Monthly, n: 2, want: time.Date(2026, 3, 31, 18, 30, 0, 0, time.UTC)},
+		{name: "monthly in a thirty day month", recurrence: Monthly, n: 3, want: time.Date(2026, 4, 30, 18, 30, 0, 0, time.UTC)},
+		{name: "monthly in a leap year", recurrence: Monthly, n: 25, want: time.Date(2028, 2, 29, 18, 30, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Occurrence(anchor, tt.recurrence, tt.n); !got.Equal(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	anchor := time.Date(2026, 1, 31, 18, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		recurrence string
+		after      time.Time
+		want       time.Time
+	}{
+		{name: "once", recurrence: Once, after: anchor.AddDate(1, 0, 0), want: anchor},
+		{name: "before the anchor", recurrence: Weekly, after: anchor.Add(-time.Hour), want: anchor},
+		{name: "at the anchor", recurrence: Weekly, after: anchor, want: anchor.AddDate(0, 0, 7)},
+		{name: "weeks later", recurrence: Weekly, after: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), want: time.Date(2026, 3, 7, 18, 30, 0, 0, time.UTC)},
+		{name: "same month", recurrence: Monthly, after: anchor.Add(time.Minute), want: time.Date(2026, 2, 28, 18, 30, 0, 0, time.UTC)},
+		{name: "from the short month", recurrence: Monthly, after: time.Date(2026, 2, 28, 18, 30, 0, 0, time.UTC), want: time.Date(2026, 3, 31, 18, 30, 0, 0, time.UTC)},
+		{name: "earlier in the month", recurrence: Monthly, after: time.Date(2026, 5, 2, 0, 0, 0, 0, time.UTC), want: time.Date(2026, 5, 31, 18, 30, 0, 0, time.UTC)},
+		{name: "next year", recurrence: Monthly, after: time.Date(2026, 12, 31, 19, 0, 0, 0, time.UTC), want: time.Date(2027, 1, 31, 18, 30, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Next(anchor, tt.recurrence, tt.after); !got.Equal(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextChain(t *testing.T) {
+	// Цепочка запусков, как в планировщике: каждый следующий считается от предыдущего.
+	anchor := time.Date(2026, 1, 31, 10, 0, 0, 0, time.UTC)
+	want := []time.Time{
+		time.Date(2026, 2, 28, 10, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 31, 10, 0, 0, 0, time.UTC),
+		time.Date(2026, 4, 30, 10, 0, 0, 0, time.UTC),
+		time.Date(2026, 5, 31, 10, 0, 0, 0, time.UTC),
+	}
+
+	at := anchor
+	for i, w := range want {
+		at = Next(anchor, Monthly, at)
+		if !at.Equal(w) {
+			t.Fatalf("run %d: got %v, want %v", i+1, at, w)
+		}
+	}
+}
+
+func TestShift(t *testing.T) {
+	s := validSpec()
+	start := time.Date(2027, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	got := s.Shift(start)
+	if !got.StartTime.Equal(start) || got.EndTime.Sub(got.StartTime) != s.EndTime.Sub(s.StartTime) {
+		t.Fatalf("got %v - %v, want a week from %v", got.StartTime, got.EndTime, start)
+	}
+	if !s.StartTime.Equal(validSpec().StartTime) {
+		t.Fatal("Shift must not change the original spec")
+	}
+}
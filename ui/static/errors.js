@@ -0,0 +1,6 @@
+document.addEventListener("htmx:beforeSwap", function (evt) {
+    if (evt.detail.xhr.status === 429 || evt.detail.xhr.status === 403) {
+        evt.detail.shouldSwap = true;
+        evt.detail.isError = false;
+    }
+});
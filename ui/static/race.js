@@ -0,0 +1,31 @@
+(function () {
+    let state = document.getElementById('raceState');
+
+    let prepare = function () {
+        state.querySelectorAll('.countdown').forEach(function (el) {
+            el.dataset.deadline = Date.now() + Number(el.dataset.left);
+        });
+    };
+
+    let tick = function () {
+        state.querySelectorAll('.countdown').forEach(function (el) {
+            let left = Math.max(0, Number(el.dataset.deadline) - Date.now());
+            el.textContent = (left / 1000).toFixed(1) + ' s';
+        });
+    };
+
+    prepare();
+    setInterval(tick, 100);
+
+    let source = new EventSource(state.dataset.events);
+    source.addEventListener('state', function (e) {
+        state.innerHTML = e.data;
+        htmx.process(state);
+        prepare();
+        tick();
+    });
+    source.addEventListener('closed', function () {
+        source.close();
+        state.innerHTML = '<div>The race room is closed</div>';
+    });
+})();
@@ -0,0 +1,32 @@
+(function () {
+    let rows = Array.from(document.querySelectorAll('#replaySteps tr'));
+    let play = document.getElementById('replayPlay');
+    let speed = document.getElementById('replaySpeed');
+    let progress = document.getElementById('replayProgress');
+    let timers = [];
+
+    let stop = function () {
+        timers.forEach(clearTimeout);
+        timers = [];
+    };
+
+    play.addEventListener('click', function () {
+        stop();
+        let k = Number(speed.value);
+        let begin = Date.now();
+        rows.forEach(function (row) {
+            row.style.visibility = 'hidden';
+            timers.push(setTimeout(function () {
+                row.style.visibility = 'visible';
+            }, Number(row.dataset.at) / k));
+        });
+
+        let tick = function () {
+            progress.value = (Date.now() - begin) * k;
+            if (progress.value < Number(progress.max)) {
+                timers.push(setTimeout(tick, 50));
+            }
+        };
+        tick();
+    });
+})();
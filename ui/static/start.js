@@ -6,11 +6,15 @@ document.currentScript = document.currentScript || (function() {
 let id = document.currentScript.getAttribute('rid');
 let start = document.currentScript.getAttribute('start');
 let finish = document.currentScript.getAttribute('finish');
+let ghost = document.currentScript.getAttribute('ghost') || null;
+let ghostSprint = Number(document.currentScript.getAttribute('ghost-sprint')) || null;
 
 chrome.runtime.sendMessage({
     action: 'wikiSurfBegin',
     wikiSurfStart: start,
     wikiSurfFinish: finish,
-    wikiSurfId: id});
+    wikiSurfId: id,
+    wikiSurfGhost: ghost,
+    wikiSurfGhostSprint: ghostSprint});
 
 chrome.tabs.create({active: true, url: start});
\ No newline at end of file